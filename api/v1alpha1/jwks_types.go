@@ -1,7 +1,10 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // JWKSSpec defines the desired state of JWKS
@@ -18,13 +21,24 @@ type JWKSSpec struct {
 	// +optional
 	NginxConfigMapName string `json:"nginxConfigMapName,omitempty"`
 
-	// Endpoint is the HTTP endpoint path for JWKS
-	// JWKS will be available at both "/" and "/jwks.json" paths
-	// This field is kept for backward compatibility but is not used in nginx config generation
+	// Endpoint is the primary HTTP path the JWKS is served at
+	// It is used by the readiness probe, verification and the public URL
 	// +kubebuilder:default="/jwks.json"
+	// +kubebuilder:validation:Pattern=`^/[A-Za-z0-9._~!&*+,=:@%/-]*$`
 	// +optional
 	Endpoint string `json:"endpoint,omitempty"`
 
+	// Paths are additional HTTP paths serving the JWKS
+	// If not set, the JWKS is also served at "/" for backward compatibility
+	// Every path that is not configured returns 404
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// PathPrefix mounts the JWKS and discovery paths under a common prefix, e.g. "/auth"
+	// +kubebuilder:validation:Pattern=`^/[A-Za-z0-9._~!&*+,=:@%/-]*$`
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
 	// UpdateStrategy defines how to update JWKS when certificate rotates
 	// +kubebuilder:validation:Enum=rolling;immediate
 	// +kubebuilder:default=rolling
@@ -36,6 +50,15 @@ type JWKSSpec struct {
 	// +optional
 	KeepOldKeys bool `json:"keepOldKeys,omitempty"`
 
+	// DriftPolicy defines how manual changes of the JWKS ConfigMap are handled:
+	// "restore" rewrites the content from keys written by the operator,
+	// "report" leaves the ConfigMap untouched and raises the DriftDetected condition
+	// Keys not written by the operator are never merged in either mode
+	// +kubebuilder:validation:Enum=restore;report
+	// +kubebuilder:default=restore
+	// +optional
+	DriftPolicy string `json:"driftPolicy,omitempty"`
+
 	// OldKeysTTL is the time to keep old keys after rotation
 	// Format: Go duration (e.g., "720h" for 30 days)
 	// +kubebuilder:default="720h"
@@ -59,6 +82,565 @@ type JWKSSpec struct {
 	// If not specified, uses operator default from config.yaml (5 minutes)
 	// +optional
 	JWKSVerificationInterval string `json:"jwksVerificationInterval,omitempty"`
+
+	// Outputs is a list of additional destinations for the rendered JWKS
+	// All outputs are updated from the same merged key set during a single reconciliation
+	// +optional
+	Outputs []OutputSpec `json:"outputs,omitempty"`
+
+	// ReplicateTo defines namespaces that receive a copy of the JWKS ConfigMap
+	// Copies are kept in sync on rotation and removed when a namespace falls out of scope
+	// +optional
+	ReplicateTo *ReplicationSpec `json:"replicateTo,omitempty"`
+
+	// CleanupOnDelete determines if generated ConfigMaps and Secrets are deleted together with JWKS
+	// If false, they are orphaned (owner references are removed) and kept in the cluster
+	// If not specified, uses operator default from config.yaml
+	// +optional
+	CleanupOnDelete *bool `json:"cleanupOnDelete,omitempty"`
+
+	// Issuer is the OpenID Connect issuer URL
+	// If set, an OpenID Connect discovery document is served at /.well-known/openid-configuration
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Discovery contains optional metadata of the OpenID Connect discovery document
+	// Ignored if Issuer is not set
+	// +optional
+	Discovery *DiscoverySpec `json:"discovery,omitempty"`
+
+	// CORS defines the CORS policy of the JWKS server
+	// If not set, any origin is allowed for GET and OPTIONS requests
+	// +optional
+	CORS *CORSSpec `json:"cors,omitempty"`
+
+	// Cache defines the Cache-Control header of JWKS and discovery responses
+	// +optional
+	Cache *CacheSpec `json:"cache,omitempty"`
+
+	// Server contains per-JWKS settings of the JWKS server pods
+	// Unset fields fall back to the global nginx configuration from config.yaml
+	// +optional
+	Server *ServerSpec `json:"server,omitempty"`
+
+	// Service contains settings of the Service in front of the JWKS server
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Expose publishes the JWKS server outside of the cluster via Ingress or Gateway API HTTPRoute
+	// +optional
+	Expose *ExposeSpec `json:"expose,omitempty"`
+
+	// NetworkPolicy creates a NetworkPolicy restricting ingress traffic of the JWKS server pods
+	// The operator namespace is always allowed, so the operator can verify the served JWKS
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+}
+
+// NetworkPolicySpec defines the NetworkPolicy of the JWKS server pods
+// Only the nginx port is opened, traffic to other ports is denied
+type NetworkPolicySpec struct {
+	// From lists peers allowed to reach the JWKS server in addition to the operator namespace
+	// +optional
+	From []NetworkPolicyPeer `json:"from,omitempty"`
+
+	// IngressController selects the ingress controller or gateway pods allowed to reach the JWKS server
+	// +optional
+	IngressController *NetworkPolicyPeer `json:"ingressController,omitempty"`
+}
+
+// NetworkPolicyPeer selects pods allowed to reach the JWKS server
+// At least one selector must be set. If only PodSelector is set, pods of the JWKS namespace are selected;
+// if both are set, pods matching PodSelector in namespaces matching NamespaceSelector are selected
+type NetworkPolicyPeer struct {
+	// NamespaceSelector selects namespaces by labels
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// PodSelector selects pods by labels
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+// ExposeSpec defines how the JWKS server is exposed outside of the cluster
+type ExposeSpec struct {
+	// Ingress creates a networking.k8s.io/v1 Ingress for the JWKS Service
+	// +optional
+	Ingress *IngressExposeSpec `json:"ingress,omitempty"`
+
+	// HTTPRoute creates a gateway.networking.k8s.io/v1 HTTPRoute for the JWKS Service
+	// Gateway API CRDs are optional, the condition Exposed reports if they are missing
+	// +optional
+	HTTPRoute *HTTPRouteExposeSpec `json:"httpRoute,omitempty"`
+}
+
+// IngressExposeSpec defines the Ingress for the JWKS server
+type IngressExposeSpec struct {
+	// Host is the public host name
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// ClassName is the IngressClass name
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// TLSSecretName is the name of the Secret with the TLS certificate for Host
+	// If set, the public URL uses https
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations are added to the Ingress
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// HTTPRouteExposeSpec defines the HTTPRoute for the JWKS server
+type HTTPRouteExposeSpec struct {
+	// ParentRefs are the Gateways the route attaches to
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []ParentReference `json:"parentRefs"`
+
+	// Hostnames are the public host names of the route
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// Scheme of the public URL reported in status
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default=https
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+
+	// Annotations are added to the HTTPRoute
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ParentReference identifies a Gateway API parent resource
+type ParentReference struct {
+	// Group of the parent resource
+	// +kubebuilder:default=gateway.networking.k8s.io
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Kind of the parent resource
+	// +kubebuilder:default=Gateway
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace of the parent resource, defaults to the JWKS namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the parent resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// SectionName is the listener name in the parent resource
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// ServiceSpec defines settings of the JWKS server Service
+type ServiceSpec struct {
+	// Type is the Service type
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +kubebuilder:default=ClusterIP
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port is the Service port, traffic is always forwarded to the server container port
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=80
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Annotations are added to the Service, e.g. for cloud load balancer settings
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerSourceRanges restricts client IPs for LoadBalancer Services
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy for NodePort and LoadBalancer Services
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+}
+
+// ServerSpec defines pod-level settings of the JWKS server Deployment
+type ServerSpec struct {
+	// Type selects the server implementation: nginx or the built-in Go server (cmd/jwks-server)
+	// Both use the same Deployment, Service and ConfigMaps; switching the type rolls out new pods
+	// external deploys no server and only publishes ConfigMaps, server objects created before are deleted
+	// +kubebuilder:validation:Enum=nginx;builtin;external
+	// +kubebuilder:default=nginx
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Image is the server container image
+	// Defaults to nginx.image or nginx.builtinImage from the operator config, depending on Type
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the server container image
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Replicas is the number of server replicas
+	// Ignored if autoscaling is configured
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources are merged over the global nginx resources per resource name
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector constrains server pods to nodes with matching labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of server pods
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity of server pods
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName of server pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// ImagePullSecrets used to pull the server image
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// SecurityProfile selects the pod security settings
+	// restricted runs nginx as non-root with a read-only root filesystem (Pod Security "restricted")
+	// none keeps the image defaults for legacy images that require root
+	// Defaults to nginx.securityProfile from the operator config
+	// +kubebuilder:validation:Enum=restricted;none
+	// +optional
+	SecurityProfile string `json:"securityProfile,omitempty"`
+
+	// TopologySpreadConstraints of server pods
+	// An empty labelSelector selects the pods of this JWKS server
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PodDisruptionBudget creates a PodDisruptionBudget for the server pods
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// Autoscaling creates a HorizontalPodAutoscaler for the server Deployment
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// Metrics adds a sidecar exporting request metrics of the server to Prometheus
+	// The built-in server exports metrics itself, Enabled only adds the Prometheus scrape annotations
+	// +optional
+	Metrics *ServerMetricsSpec `json:"metrics,omitempty"`
+
+	// Template renders the nginx config from a user-supplied template or adds snippets to the built-in one
+	// Ignored by the built-in server
+	// +optional
+	Template *ServerTemplateSpec `json:"template,omitempty"`
+
+	// TLS adds an https listener with the certificate from a TLS Secret and an https Service port
+	// The http listener is kept for probes and Ingress backends, pods are rolled when the certificate changes
+	// +optional
+	TLS *ServerTLSSpec `json:"tls,omitempty"`
+
+	// RateLimit limits JWKS and discovery requests per client IP
+	// Every pod counts its own requests, the Service spreads a client over all replicas
+	// +optional
+	RateLimit *RateLimitSpec `json:"rateLimit,omitempty"`
+
+	// MaxBodySize is the largest accepted request body, larger requests are rejected with 413
+	// Defaults to 1Ki, only GET, HEAD and OPTIONS requests are served
+	// +optional
+	MaxBodySize *resource.Quantity `json:"maxBodySize,omitempty"`
+
+	// AccessLog configures the access log records of the server
+	// If not set, nginx keeps the log format of the image and the built-in server does not log requests
+	// +optional
+	AccessLog *AccessLogSpec `json:"accessLog,omitempty"`
+}
+
+// AccessLogSpec defines the access log of the JWKS server
+type AccessLogSpec struct {
+	// Format of access log records: combined, json or off
+	// json records include the JWKS name, namespace and the digest of the served JWKS
+	// +kubebuilder:validation:Enum=combined;json;off
+	// +kubebuilder:default=combined
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// ProbeSamplePercent is the percentage of kubelet probe requests that are logged
+	// Probes are recognized by the kube-probe User-Agent, 0 does not log them
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ProbeSamplePercent int32 `json:"probeSamplePercent,omitempty"`
+}
+
+// RateLimitSpec defines the per client IP request rate of the JWKS server
+// Requests over the rate and burst are rejected immediately with Status
+type RateLimitSpec struct {
+	// RequestsPerSecond is the sustained request rate of a client IP
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+
+	// Burst is the number of requests a client IP may send over the rate
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+
+	// Status is the response status of rejected requests, defaults to 429
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	// +optional
+	Status int32 `json:"status,omitempty"`
+}
+
+// ServerTLSSpec references the TLS Secret the JWKS server serves https with
+type ServerTLSSpec struct {
+	// SecretName is a kubernetes.io/tls Secret in the JWKS namespace with tls.crt and tls.key
+	// The certificate should be valid for <name>.<namespace>.svc.cluster.local, ca.crt is trusted by verification
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+
+	// Port is the https port of the Service, defaults to 443
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// ServerTemplateSpec references a ConfigMap with a custom nginx config template and snippets
+// Keys: default.conf.tmpl replaces the built-in template; http-snippet.conf, server-snippet.conf and
+// location-snippet.conf are added to the http context, the JWKS server block and the JWKS locations
+type ServerTemplateSpec struct {
+	// ConfigMapName is the ConfigMap in the JWKS namespace holding the template and snippets
+	// +kubebuilder:validation:Required
+	ConfigMapName string `json:"configMapName"`
+}
+
+// ServerMetricsSpec defines the metrics exporter sidecar of the JWKS server
+type ServerMetricsSpec struct {
+	// Enabled adds the exporter sidecar
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Image is the exporter image, defaults to nginx.exporterImage from the operator config
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Monitor creates a Prometheus Operator ServiceMonitor or PodMonitor scraping the server metrics
+	// Requires exported metrics: the exporter sidecar or the built-in server
+	// Skipped if the Prometheus Operator CRDs are not installed
+	// +optional
+	Monitor *MonitorSpec `json:"monitor,omitempty"`
+}
+
+// MonitorSpec defines the Prometheus Operator monitor of the JWKS server metrics
+type MonitorSpec struct {
+	// Kind is ServiceMonitor or PodMonitor
+	// A ServiceMonitor scrapes the headless Service <name>-metrics created next to it
+	// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+	// +kubebuilder:default=ServiceMonitor
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Interval is the scrape interval, the Prometheus default is used if not set
+	// +kubebuilder:validation:Pattern=`^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$`
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Labels are added to the monitor, e.g. the label selected by serviceMonitorSelector or podMonitorSelector of Prometheus
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget of the JWKS server
+// Only one of MinAvailable and MaxUnavailable may be set, MaxUnavailable defaults to 1
+type PodDisruptionBudgetSpec struct {
+	// MinAvailable is the number or percentage of pods that must stay available during evictions
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that may be unavailable during evictions
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// AutoscalingSpec defines the HorizontalPodAutoscaler of the JWKS server
+type AutoscalingSpec struct {
+	// MinReplicas is the lower limit of replicas, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the average CPU utilization of requests to scale on
+	// Defaults to 80 if no target is set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the average memory utilization of requests to scale on
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// CacheSpec defines the Cache-Control header of the JWKS server
+type CacheSpec struct {
+	// MaxAge in seconds, defaults to nginx.cacheMaxAge from the operator config
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAge *int32 `json:"maxAge,omitempty"`
+
+	// StaleWhileRevalidate in seconds allows caches to serve a stale JWKS while revalidating it
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StaleWhileRevalidate *int32 `json:"staleWhileRevalidate,omitempty"`
+
+	// MustRevalidate forbids serving a stale JWKS after max-age without revalidation
+	// +optional
+	MustRevalidate bool `json:"mustRevalidate,omitempty"`
+}
+
+// CORSSpec defines the CORS policy of the JWKS server
+type CORSSpec struct {
+	// Enabled controls whether CORS headers are sent at all
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// AllowedOrigins are origins allowed by exact match, "*" allows any origin
+	// If neither AllowedOrigins nor AllowedOriginPatterns is set, any origin is allowed
+	// +optional
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+
+	// AllowedOriginPatterns are regular expressions (nginx PCRE syntax) matched against the Origin header
+	// +optional
+	AllowedOriginPatterns []string `json:"allowedOriginPatterns,omitempty"`
+
+	// AllowedMethods defaults to GET and OPTIONS
+	// +optional
+	AllowedMethods []string `json:"allowedMethods,omitempty"`
+
+	// AllowedHeaders defaults to Content-Type
+	// +optional
+	AllowedHeaders []string `json:"allowedHeaders,omitempty"`
+
+	// MaxAge is the number of seconds a preflight response may be cached
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAge *int32 `json:"maxAge,omitempty"`
+
+	// AllowCredentials sends Access-Control-Allow-Credentials: true
+	// Not allowed together with the "*" origin
+	// +optional
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+}
+
+// DiscoverySpec defines optional metadata of the OpenID Connect discovery document
+type DiscoverySpec struct {
+	// JWKSURI is the public URL of the served JWKS
+	// Defaults to the issuer URL with "/jwks.json" appended
+	// +optional
+	JWKSURI string `json:"jwksURI,omitempty"`
+
+	// AuthorizationEndpoint is the URL of the authorization endpoint
+	// +optional
+	AuthorizationEndpoint string `json:"authorizationEndpoint,omitempty"`
+
+	// TokenEndpoint is the URL of the token endpoint
+	// +optional
+	TokenEndpoint string `json:"tokenEndpoint,omitempty"`
+
+	// UserinfoEndpoint is the URL of the userinfo endpoint
+	// +optional
+	UserinfoEndpoint string `json:"userinfoEndpoint,omitempty"`
+
+	// ResponseTypesSupported defaults to ["code"]
+	// +optional
+	ResponseTypesSupported []string `json:"responseTypesSupported,omitempty"`
+
+	// SubjectTypesSupported defaults to ["public"]
+	// +optional
+	SubjectTypesSupported []string `json:"subjectTypesSupported,omitempty"`
+
+	// IDTokenSigningAlgValuesSupported defaults to algorithms of the served keys
+	// +optional
+	IDTokenSigningAlgValuesSupported []string `json:"idTokenSigningAlgValuesSupported,omitempty"`
+
+	// ScopesSupported is the list of supported scopes
+	// +optional
+	ScopesSupported []string `json:"scopesSupported,omitempty"`
+
+	// ClaimsSupported is the list of supported claims
+	// +optional
+	ClaimsSupported []string `json:"claimsSupported,omitempty"`
+}
+
+// ReplicationSpec selects namespaces for JWKS ConfigMap replication
+// A namespace is selected if it is listed in Namespaces or matches NamespaceSelector
+type ReplicationSpec struct {
+	// Namespaces is an explicit list of target namespaces
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects target namespaces by labels
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// OutputSpec defines an additional ConfigMap or Secret that receives JWKS data
+type OutputSpec struct {
+	// Kind is the kind of the target resource
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=ConfigMap
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the target resource in the JWKS namespace
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Format is the output format of the key set:
+	// "jwks" (JSON Web Key Set), "pem-bundle" (all public keys in one PEM file),
+	// "per-kid-pem" (one <kid>.pem file per key) or "spki-pins" (SHA-256 SPKI pins as JSON)
+	// +kubebuilder:validation:Enum=jwks;pem-bundle;per-kid-pem;spki-pins
+	// +kubebuilder:default=jwks
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Key is the data key under which the output is stored
+	// Defaults depend on format: "jwks.json", "keys.pem" or "pins.json"
+	// Ignored for "per-kid-pem", which writes one "<kid>.pem" key per JWK
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Encoding defines where data is written in a ConfigMap:
+	// "binary" uses BinaryData, "string" uses Data
+	// Secrets always store data in Data
+	// +kubebuilder:validation:Enum=binary;string
+	// +kubebuilder:default=binary
+	// +optional
+	Encoding string `json:"encoding,omitempty"`
 }
 
 // JWKSStatus defines the observed state of JWKS
@@ -86,6 +668,31 @@ type JWKSStatus struct {
 	// JWKSVerified is the timestamp when JWKS was last verified from nginx
 	// +optional
 	JWKSVerified *metav1.Time `json:"jwksVerified,omitempty"`
+
+	// ReplicatedNamespaces is the list of namespaces holding an up-to-date JWKS ConfigMap copy
+	// +optional
+	ReplicatedNamespaces []string `json:"replicatedNamespaces,omitempty"`
+
+	// ReplicaConflicts is the list of target namespaces holding an unmanaged ConfigMap with the same name
+	// +optional
+	ReplicaConflicts []string `json:"replicaConflicts,omitempty"`
+
+	// URL is the public URL of the JWKS endpoint if spec.expose is set
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// ContentDigest is the SHA-256 digest of jwks.json in the JWKS ConfigMap
+	// +optional
+	ContentDigest string `json:"contentDigest,omitempty"`
+
+	// ContentUpdateTime is the timestamp when ContentDigest last changed
+	// +optional
+	ContentUpdateTime *metav1.Time `json:"contentUpdateTime,omitempty"`
+
+	// PropagationDuration is the time from ContentUpdateTime until every ready server pod served the content
+	// Empty while the current content is still propagating
+	// +optional
+	PropagationDuration *metav1.Duration `json:"propagationDuration,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwksserver"
+)
+
+func main() {
+	options := jwksserver.Options{}
+	var logLevel string
+
+	flag.StringVar(&options.ConfigPath, "config",
+		filepath.Join(config.BuiltinConfigMountPath, config.ConfigMapKeyBuiltinConfig), "Path to the server config generated by the operator")
+	flag.StringVar(&options.DataDir, "data-dir", config.JWKSDataMountPath, "Directory the JWKS ConfigMap is mounted at")
+	flag.StringVar(&options.ListenAddress, "listen-address", ":"+strconv.Itoa(config.DefaultNginxPort), "The address JWKS is served on")
+	flag.StringVar(&options.MetricsAddress, "metrics-address", ":"+strconv.Itoa(config.ExporterPort),
+		"The address Prometheus metrics are served on, empty disables metrics")
+	flag.DurationVar(&options.ReloadInterval, "reload-interval", config.DefaultBuiltinReloadInterval,
+		"Interval the mounted files are checked for changes")
+	flag.StringVar(&options.TLSListenAddress, "tls-listen-address", "", "The address JWKS is served on over https, empty disables https")
+	flag.StringVar(&options.TLSCertFile, "tls-cert-file", "", "PEM certificate of the https listener")
+	flag.StringVar(&options.TLSKeyFile, "tls-key-file", "", "PEM private key of the https listener")
+	flag.StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn, error")
+	flag.Parse()
+
+	if options.TLSListenAddress != "" && (options.TLSCertFile == "" || options.TLSKeyFile == "") {
+		fmt.Fprintln(os.Stderr, "-tls-cert-file and -tls-key-file are required with -tls-listen-address")
+		os.Exit(1)
+	}
+
+	if err := run(options, logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run creates the logger and serves JWKS until SIGINT or SIGTERM
+func run(options jwksserver.Options, logLevel string) error {
+	zapConfig := zap.NewProductionConfig()
+	level, err := zap.ParseAtomicLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", logLevel, err)
+	}
+	zapConfig.Level = level
+
+	logger, err := zapConfig.Build()
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer func() { _ = logger.Sync() }()
+
+	server, err := jwksserver.NewServer(options, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load served content: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := server.Run(ctx); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
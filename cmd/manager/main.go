@@ -18,6 +18,7 @@ import (
 	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
 	"github.com/jwks-operator/jwks-operator/pkg/controller"
+	"github.com/jwks-operator/jwks-operator/pkg/monitoring"
 )
 
 var (
@@ -105,13 +106,19 @@ func main() {
 	}
 
 	// Create controller
-	jwksReconciler := controller.NewJWKSReconciler(mgr.GetClient(), mgr.GetScheme(), cfg, logger)
+	jwksReconciler := controller.NewJWKSReconciler(mgr.GetClient(), mgr.GetScheme(), mgr.GetEventRecorderFor("jwks-operator"), cfg, logger)
 
 	if err = jwksReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JWKS")
 		os.Exit(1)
 	}
 
+	// Create metrics Service and ServiceMonitor of the operator from metrics.serviceMonitor
+	if err := mgr.Add(monitoring.NewOperatorMonitor(mgr.GetClient(), cfg, logger)); err != nil {
+		setupLog.Error(err, "unable to set up operator monitor")
+		os.Exit(1)
+	}
+
 	// Setup health checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
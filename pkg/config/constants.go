@@ -7,9 +7,21 @@ const (
 	// DefaultNginxImage is the default nginx container image
 	DefaultNginxImage = "nginx:1.25-alpine"
 	// DefaultNginxPort is the default nginx port
-	DefaultNginxPort = 80
+	// An unprivileged port lets nginx run as non-root
+	DefaultNginxPort = 8080
+	// DefaultNginxStatusPort is the default internal port serving stub_status
+	DefaultNginxStatusPort = 8081
+	// DefaultServicePort is the default port of the JWKS server Service
+	DefaultServicePort = 80
 	// DefaultNginxReplicas is the default number of nginx replicas
 	DefaultNginxReplicas = 1
+	// DefaultPDBMaxUnavailable is the default maxUnavailable of the JWKS server PodDisruptionBudget
+	// Unlike minAvailable 1 it does not block node drains of a single replica
+	DefaultPDBMaxUnavailable = 1
+	// DefaultAutoscalingMinReplicas is the default lower replica limit of the JWKS server autoscaler
+	DefaultAutoscalingMinReplicas = 1
+	// DefaultAutoscalingTargetCPU is the default target CPU utilization in percent of the JWKS server autoscaler
+	DefaultAutoscalingTargetCPU = 80
 )
 
 // Verification constants
@@ -54,6 +66,12 @@ const (
 	LabelManagedByValue = "jwks-operator"
 	// LabelAppValue is the app label value for nginx
 	LabelAppValue = "nginx-jwks"
+	// LabelSourceNamespace is the label key with the namespace of the JWKS a replica was copied from
+	LabelSourceNamespace = "jwks-source-namespace"
+	// LabelComponent is the label key separating metrics Services from the Services serving JWKS
+	LabelComponent = "component"
+	// LabelComponentMetrics is the component label value of metrics Services
+	LabelComponentMetrics = "metrics"
 )
 
 // Probe constants
@@ -74,14 +92,48 @@ const (
 	HealthCheckPath = "/healthz"
 	// JWKSEndpointPath is the path for JWKS endpoint
 	JWKSEndpointPath = "/jwks.json"
+	// DiscoveryEndpointPath is the path for the OpenID Connect discovery document
+	DiscoveryEndpointPath = "/.well-known/openid-configuration"
 )
 
 // ConfigMap keys
 const (
 	// ConfigMapKeyJWKS is the key for JWKS data in ConfigMap
 	ConfigMapKeyJWKS = "jwks.json"
+	// ConfigMapKeyDiscovery is the key for the OpenID Connect discovery document in the JWKS ConfigMap
+	ConfigMapKeyDiscovery = "openid-configuration.json"
 	// ConfigMapKeyNginxConfig is the key for nginx config in ConfigMap
 	ConfigMapKeyNginxConfig = "default.conf"
+	// ConfigMapKeyJWKSSnapshotPrefix and ConfigMapKeyJWKSSnapshotSuffix frame the content digest in the key
+	// of the jwks.json copy nginx serves from its own ConfigMap
+	ConfigMapKeyJWKSSnapshotPrefix = "jwks-"
+	ConfigMapKeyJWKSSnapshotSuffix = ".json"
+	// ConfigMapKeyNginxTemplate is the key for the nginx config template in the spec.server.template ConfigMap
+	ConfigMapKeyNginxTemplate = "default.conf.tmpl"
+	// ConfigMapKeyHTTPSnippet is the key for the http-level nginx snippet in the spec.server.template ConfigMap
+	ConfigMapKeyHTTPSnippet = "http-snippet.conf"
+	// ConfigMapKeyServerSnippet is the key for the server-level nginx snippet in the spec.server.template ConfigMap
+	ConfigMapKeyServerSnippet = "server-snippet.conf"
+	// ConfigMapKeyLocationSnippet is the key for the nginx snippet added to JWKS and discovery locations
+	ConfigMapKeyLocationSnippet = "location-snippet.conf"
+	// AnnotationTemplateHash is the nginx ConfigMap annotation key for the hash of the spec.server.template
+	// ConfigMap the config was rendered from, a changed template triggers a full reconciliation
+	AnnotationTemplateHash = "jwks-operator.example.com/template-hash"
+)
+
+// Output constants
+const (
+	// OutputKindConfigMap is the output kind for ConfigMap targets
+	OutputKindConfigMap = "ConfigMap"
+	// OutputKindSecret is the output kind for Secret targets
+	OutputKindSecret = "Secret"
+	// OutputEncodingBinary stores output data in ConfigMap BinaryData
+	OutputEncodingBinary = "binary"
+	// OutputEncodingString stores output data in ConfigMap Data
+	OutputEncodingString = "string"
+	// AnnotationOutputKeys lists the data keys written by outputs into the target resource
+	// Keys no longer rendered, e.g. <kid>.pem of removed keys, are deleted from the target
+	AnnotationOutputKeys = "jwks-operator.example.com/output-keys"
 )
 
 // Volume names
@@ -90,14 +142,205 @@ const (
 	VolumeNameNginxConfig = "nginx-config"
 	// VolumeNameJWKSData is the name of JWKS data volume
 	VolumeNameJWKSData = "jwks-data"
+	// VolumeNameNginxCache is the name of the writable nginx cache volume of restricted pods
+	VolumeNameNginxCache = "nginx-cache"
+	// VolumeNameNginxRun is the name of the writable volume for the nginx pid file of restricted pods
+	VolumeNameNginxRun = "nginx-run"
+	// VolumeNameTmp is the name of the writable /tmp volume of restricted pods
+	VolumeNameTmp = "tmp"
+	// JWKSDataMountPath is the directory where the JWKS ConfigMap is mounted in nginx pods
+	JWKSDataMountPath = "/usr/share/nginx/jwks"
 )
 
 // Annotation keys for tracking ConfigMap changes
+// The hashes are set on the Deployment, not on its pod template, so ConfigMap changes don't restart pods
 const (
 	// AnnotationNginxConfigMapHash is the annotation key for nginx ConfigMap hash
 	AnnotationNginxConfigMapHash = "jwks-operator.example.com/nginx-configmap-hash"
 	// AnnotationJWKSConfigMapHash is the annotation key for JWKS ConfigMap hash
 	AnnotationJWKSConfigMapHash = "jwks-operator.example.com/jwks-configmap-hash"
+	// AnnotationRestartedAt is the pod template annotation older operator versions set to restart pods
+	AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+)
+
+// Config reloader constants
+const (
+	// ReloaderContainerName is the name of the sidecar reloading nginx after config changes
+	ReloaderContainerName = "config-reloader"
+	// DefaultReloaderInterval is the interval in seconds the reloader checks the mounted config
+	DefaultReloaderInterval = 5
+	// DefaultReloaderCPURequest is the CPU request of the reloader sidecar
+	DefaultReloaderCPURequest = "5m"
+	// DefaultReloaderMemoryRequest is the memory request of the reloader sidecar
+	DefaultReloaderMemoryRequest = "8Mi"
+	// DefaultReloaderMemoryLimit is the memory limit of the reloader sidecar
+	DefaultReloaderMemoryLimit = "32Mi"
+)
+
+// Metrics exporter constants
+const (
+	// ExporterContainerName is the name of the metrics exporter sidecar
+	ExporterContainerName = "metrics-exporter"
+	// DefaultExporterImage is the default image of the metrics exporter sidecar
+	// The exporter turns nginx access log records into request, status code and response time metrics
+	DefaultExporterImage = "ghcr.io/martin-helmich/prometheus-nginxlog-exporter/exporter:v1.11.0"
+	// ExporterPort is the port the exporter serves metrics on
+	ExporterPort = 9113
+	// ExporterMetricsPath is the path the exporter serves metrics on
+	ExporterMetricsPath = "/metrics"
+	// ExporterSyslogAddress is the address nginx sends access log records to
+	ExporterSyslogAddress = "127.0.0.1:5531"
+	// ExporterMetricsNamespace is the prefix of exporter metrics
+	ExporterMetricsNamespace = "jwks_server"
+	// ConfigMapKeyExporterConfig is the key for the exporter config in the nginx ConfigMap
+	ConfigMapKeyExporterConfig = "exporter.yaml"
+	// DefaultExporterCPURequest is the CPU request of the exporter sidecar
+	DefaultExporterCPURequest = "10m"
+	// DefaultExporterMemoryRequest is the memory request of the exporter sidecar
+	DefaultExporterMemoryRequest = "16Mi"
+	// DefaultExporterMemoryLimit is the memory limit of the exporter sidecar
+	DefaultExporterMemoryLimit = "64Mi"
+)
+
+// Propagation constants
+const (
+	// DefaultPropagationCheckInterval is the requeue interval while new JWKS content propagates to server pods
+	DefaultPropagationCheckInterval = 10 * time.Second
+	// DefaultPropagationTimeout is the time verification waits for new JWKS content to reach server pods
+	// kubelet refreshes ConfigMap volumes within its sync period plus the ConfigMap cache TTL
+	DefaultPropagationTimeout = 3 * time.Minute
+)
+
+// Annotation keys for detecting manual changes of the JWKS ConfigMap
+const (
+	// AnnotationJWKSContentDigest is the annotation key for the digest of the last JWKS written by the operator
+	AnnotationJWKSContentDigest = "jwks-operator.example.com/content-digest"
+	// AnnotationJWKSKeyHistory is the annotation key for fingerprints of keys written by the operator, oldest first
+	AnnotationJWKSKeyHistory = "jwks-operator.example.com/key-history"
+	// MaxKeyHistory is the maximum number of keys kept in key history
+	MaxKeyHistory = 32
+)
+
+// Drift policies
+const (
+	// DriftPolicyRestore restores JWKS ConfigMap content written by the operator
+	DriftPolicyRestore = "restore"
+	// DriftPolicyReport only reports manual changes without modifying the ConfigMap
+	DriftPolicyReport = "report"
+)
+
+// Annotation keys for generated Services, Ingresses and HTTPRoutes
+const (
+	// AnnotationManagedAnnotations lists annotations set from the JWKS spec
+	// Keys removed from the spec are deleted from the object, other annotations are kept
+	AnnotationManagedAnnotations = "jwks-operator.example.com/managed-annotations"
+	// AnnotationSpecHash is the hash of the desired spec of objects with server-side defaults (e.g. HTTPRoute)
+	AnnotationSpecHash = "jwks-operator.example.com/spec-hash"
+)
+
+// Expose constants
+const (
+	// ExposeSchemeHTTP is the http scheme of the public URL
+	ExposeSchemeHTTP = "http"
+	// ExposeSchemeHTTPS is the https scheme of the public URL
+	ExposeSchemeHTTPS = "https"
+	// GatewayAPIGroup is the Gateway API group
+	GatewayAPIGroup = "gateway.networking.k8s.io"
+	// GatewayAPIVersion is the Gateway API version used for HTTPRoute
+	GatewayAPIVersion = "v1"
+	// HTTPRouteKind is the kind of Gateway API HTTPRoute
+	HTTPRouteKind = "HTTPRoute"
+)
+
+// Pod security profiles of the JWKS server
+const (
+	// SecurityProfileRestricted runs the server compliant with Pod Security "restricted"
+	SecurityProfileRestricted = "restricted"
+	// SecurityProfileNone keeps security settings of the image
+	SecurityProfileNone = "none"
+	// NginxUserID is the uid and gid of the nginx user in the official nginx images
+	NginxUserID = 101
+	// MinUnprivilegedPort is the lowest port a server running as NginxUserID can bind
+	MinUnprivilegedPort = 1024
+)
+
+// Server types of the JWKS server
+const (
+	// ServerTypeNginx serves the JWKS with nginx
+	ServerTypeNginx = "nginx"
+	// ServerTypeBuiltin serves the JWKS with the built-in Go server (cmd/jwks-server)
+	ServerTypeBuiltin = "builtin"
+	// ServerTypeExternal deploys no server, the JWKS ConfigMap is served outside the operator
+	ServerTypeExternal = "external"
+)
+
+// nginx config test constants
+const (
+	// ConfigTestJobSuffix is appended to the JWKS name to name the Job running nginx -t
+	ConfigTestJobSuffix = "-nginx-test"
+	// ConfigCandidateSuffix is appended to the JWKS name to name the ConfigMap with the config under test
+	ConfigCandidateSuffix = "-nginx-candidate"
+	// AnnotationConfigHash is the annotation key for the hash of the config a test Job checks
+	AnnotationConfigHash = "jwks-operator.example.com/config-hash"
+	// ConfigTestDeadlineSeconds limits the runtime of the nginx -t Job
+	ConfigTestDeadlineSeconds = 120
+	// ConfigTestTTLSeconds is the time a finished nginx -t Job is kept for inspection
+	ConfigTestTTLSeconds = 3600
+)
+
+// TLS serving constants
+const (
+	// DefaultNginxTLSPort is the default container port of the https listener
+	DefaultNginxTLSPort = 8443
+	// DefaultTLSServicePort is the default https port of the server Service
+	DefaultTLSServicePort = 443
+	// VolumeNameTLS is the name of the volume with the TLS Secret from spec.server.tls
+	VolumeNameTLS = "tls"
+	// TLSMountPath is the directory the TLS Secret is mounted at in server pods
+	TLSMountPath = "/etc/jwks-operator/tls"
+	// AnnotationTLSSecretHash is the pod template annotation key for the hash of the TLS Secret
+	// A changed certificate rolls the server pods
+	AnnotationTLSSecretHash = "jwks-operator.example.com/tls-secret-hash"
+)
+
+// Request limit constants
+const (
+	// DefaultRateLimitStatus is the status of responses rejected by spec.server.rateLimit
+	DefaultRateLimitStatus = 429
+	// DefaultMaxBodySize is the default request body limit in bytes, JWKS endpoints accept no bodies
+	DefaultMaxBodySize = 1024
+)
+
+// Access log constants
+const (
+	// AccessLogFormatCombined logs requests in the nginx combined format
+	AccessLogFormatCombined = "combined"
+	// AccessLogFormatJSON logs requests as JSON objects with the JWKS name, namespace and content digest
+	AccessLogFormatJSON = "json"
+	// AccessLogFormatOff disables the access log
+	AccessLogFormatOff = "off"
+	// HeaderContentDigest is the response header with the digest of the served JWKS
+	HeaderContentDigest = "X-JWKS-Digest"
+	// ProbeUserAgentPrefix is the User-Agent prefix of kubelet probes
+	ProbeUserAgentPrefix = "kube-probe/"
+)
+
+// Built-in server constants
+const (
+	// BuiltinContainerName is the name of the built-in server container
+	BuiltinContainerName = "jwks-server"
+	// ConfigMapKeyBuiltinConfig is the key for the built-in server config in the server ConfigMap
+	ConfigMapKeyBuiltinConfig = "server.json"
+	// BuiltinConfigMountPath is the directory the server ConfigMap is mounted at in built-in server pods
+	BuiltinConfigMountPath = "/etc/jwks-server"
+	// DefaultBuiltinReloadInterval is the interval the built-in server checks mounted files for changes
+	DefaultBuiltinReloadInterval = 2 * time.Second
+)
+
+// Finalizer constants
+const (
+	// FinalizerName is the finalizer added to JWKS resources to run cleanup before deletion
+	FinalizerName = "jwks-operator.example.com/finalizer"
 )
 
 // Secret keys
@@ -106,4 +349,28 @@ const (
 	SecretKeyTLSKey = "tls.key"
 	// SecretKeyTLSCert is the key for TLS certificate in Secret
 	SecretKeyTLSCert = "tls.crt"
+	// SecretKeyCACert is the key for the CA certificate in Secret, as set by cert-manager
+	SecretKeyCACert = "ca.crt"
+)
+
+// Prometheus Operator monitoring constants
+const (
+	// MonitoringGroup is the Prometheus Operator group
+	MonitoringGroup = "monitoring.coreos.com"
+	// MonitoringVersion is the Prometheus Operator version used for ServiceMonitor and PodMonitor
+	MonitoringVersion = "v1"
+	// ServiceMonitorKind is the kind of Prometheus Operator ServiceMonitor
+	ServiceMonitorKind = "ServiceMonitor"
+	// PodMonitorKind is the kind of Prometheus Operator PodMonitor
+	PodMonitorKind = "PodMonitor"
+	// MetricsPortName is the name of the metrics port of containers and metrics Services
+	MetricsPortName = "metrics"
+	// MetricsServiceSuffix is appended to the JWKS name to name the metrics Service scraped by a ServiceMonitor
+	MetricsServiceSuffix = "-metrics"
+	// OperatorMetricsName is the name of the metrics Service and ServiceMonitor of the operator
+	OperatorMetricsName = "jwks-operator-metrics"
+	// LabelControlPlane is the label key of the operator pods
+	LabelControlPlane = "control-plane"
+	// LabelControlPlaneValue is the label value of the operator pods
+	LabelControlPlaneValue = "controller-manager"
 )
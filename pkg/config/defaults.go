@@ -55,10 +55,14 @@ func DefaultConfig() *Config {
 			UseServiceAccount:    true,
 		},
 		Nginx: NginxConfig{
-			Image:       DefaultNginxImage,
-			Port:        DefaultNginxPort,
-			Replicas:    DefaultNginxReplicas,
-			CacheMaxAge: DefaultCacheMaxAge,
+			Image:           DefaultNginxImage,
+			Port:            DefaultNginxPort,
+			Replicas:        DefaultNginxReplicas,
+			CacheMaxAge:     DefaultCacheMaxAge,
+			SecurityProfile: SecurityProfileRestricted,
+			StatusPort:      DefaultNginxStatusPort,
+			TLSPort:         DefaultNginxTLSPort,
+			ExporterImage:   DefaultExporterImage,
 			Resources: NginxResources{
 				Requests: NginxResourceRequirements{
 					CPU:    DefaultNginxCPURequest,
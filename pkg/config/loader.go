@@ -3,12 +3,21 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// monitorIntervalRegexp matches Prometheus durations, the format of ServiceMonitor and PodMonitor intervals
+var monitorIntervalRegexp = regexp.MustCompile(`^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$`)
+
+// ValidMonitorInterval checks if interval is a Prometheus duration such as 30s or 1m30s
+func ValidMonitorInterval(interval string) bool {
+	return interval != "" && monitorIntervalRegexp.MatchString(interval)
+}
+
 // Load loads configuration from file and environment variables
 // namespace must be provided from Kubernetes environment (e.g., from Pod namespace)
 func Load(configPath string, namespace string) (*Config, error) {
@@ -140,6 +149,14 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("logging level must be one of: debug, info, warn, error")
 	}
 
+	// Validate ServiceMonitor of the operator
+	if cfg.Metrics.ServiceMonitor.Enabled && (cfg.Metrics.Port < 1 || cfg.Metrics.Port > 65535) {
+		return fmt.Errorf("metrics port must be between 1 and 65535 to create the ServiceMonitor, got %d", cfg.Metrics.Port)
+	}
+	if interval := cfg.Metrics.ServiceMonitor.Interval; interval != "" && !ValidMonitorInterval(interval) {
+		return fmt.Errorf("metrics.serviceMonitor.interval %q is not a Prometheus duration", interval)
+	}
+
 	// Validate nginx configuration
 	if err := validateNginxConfig(&cfg.Nginx); err != nil {
 		return fmt.Errorf("nginx configuration validation failed: %w", err)
@@ -164,6 +181,26 @@ func validateNginxConfig(nginxConfig *NginxConfig) error {
 		return fmt.Errorf("nginx port must be between 1 and 65535, got %d", nginxConfig.Port)
 	}
 
+	// Validate status port
+	if nginxConfig.StatusPort < 0 || nginxConfig.StatusPort > 65535 {
+		return fmt.Errorf("nginx status port must be between 1 and 65535, got %d", nginxConfig.StatusPort)
+	}
+	if nginxConfig.StatusPort > 0 && nginxConfig.StatusPort == nginxConfig.Port {
+		return fmt.Errorf("nginx status port must differ from nginx port %d", nginxConfig.Port)
+	}
+	if nginxConfig.StatusPort == ExporterPort || nginxConfig.Port == ExporterPort {
+		return fmt.Errorf("nginx ports must differ from the metrics exporter port %d", ExporterPort)
+	}
+
+	// Validate TLS port
+	if nginxConfig.TLSPort < 0 || nginxConfig.TLSPort > 65535 {
+		return fmt.Errorf("nginx TLS port must be between 1 and 65535, got %d", nginxConfig.TLSPort)
+	}
+	if nginxConfig.TLSPort > 0 &&
+		(nginxConfig.TLSPort == nginxConfig.Port || nginxConfig.TLSPort == nginxConfig.StatusPort || nginxConfig.TLSPort == ExporterPort) {
+		return fmt.Errorf("nginx TLS port %d must differ from the nginx, status and metrics exporter ports", nginxConfig.TLSPort)
+	}
+
 	// Validate replicas
 	if nginxConfig.Replicas < 0 {
 		return fmt.Errorf("nginx replicas must be non-negative, got %d", nginxConfig.Replicas)
@@ -174,6 +211,32 @@ func validateNginxConfig(nginxConfig *NginxConfig) error {
 		return fmt.Errorf("nginx cache max age must be non-negative, got %d", nginxConfig.CacheMaxAge)
 	}
 
+	// Validate security profile
+	switch nginxConfig.SecurityProfile {
+	case "", SecurityProfileRestricted, SecurityProfileNone:
+	default:
+		return fmt.Errorf("nginx security profile must be %q or %q, got %q",
+			SecurityProfileRestricted, SecurityProfileNone, nginxConfig.SecurityProfile)
+	}
+
+	// Validate privileged ports, the restricted profile runs nginx as a non-root user
+	if nginxConfig.SecurityProfile != SecurityProfileNone {
+		ports := []struct {
+			name string
+			port int
+		}{
+			{"nginx port", nginxConfig.Port},
+			{"nginx status port", nginxConfig.StatusPort},
+			{"nginx TLS port", nginxConfig.TLSPort},
+		}
+		for _, p := range ports {
+			if p.port > 0 && p.port < MinUnprivilegedPort {
+				return fmt.Errorf("%s must be at least %d with the %q security profile, got %d",
+					p.name, MinUnprivilegedPort, SecurityProfileRestricted, p.port)
+			}
+		}
+	}
+
 	return nil
 }
 
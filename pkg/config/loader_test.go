@@ -0,0 +1,128 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateNginxConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *NginxConfig
+		wantErr string
+	}{
+		{
+			name: "nil config",
+		},
+		{
+			name:   "defaults",
+			config: &NginxConfig{},
+		},
+		{
+			name:   "restricted profile",
+			config: &NginxConfig{Port: DefaultNginxPort, SecurityProfile: SecurityProfileRestricted},
+		},
+		{
+			name:   "none profile",
+			config: &NginxConfig{SecurityProfile: SecurityProfileNone},
+		},
+		{
+			name:    "privileged port with the restricted profile",
+			config:  &NginxConfig{Port: 80},
+			wantErr: "nginx port must be at least 1024",
+		},
+		{
+			name:    "privileged status port with the restricted profile",
+			config:  &NginxConfig{StatusPort: 81, SecurityProfile: SecurityProfileRestricted},
+			wantErr: "nginx status port must be at least 1024",
+		},
+		{
+			name:    "privileged TLS port with the restricted profile",
+			config:  &NginxConfig{TLSPort: 443},
+			wantErr: "nginx TLS port must be at least 1024",
+		},
+		{
+			name:   "privileged ports with the none profile",
+			config: &NginxConfig{Port: 80, TLSPort: 443, SecurityProfile: SecurityProfileNone},
+		},
+		{
+			name:    "unknown profile",
+			config:  &NginxConfig{SecurityProfile: "baseline"},
+			wantErr: "security profile",
+		},
+		{
+			name:    "status port out of range",
+			config:  &NginxConfig{StatusPort: 70000},
+			wantErr: "status port",
+		},
+		{
+			name:    "status port equal to nginx port",
+			config:  &NginxConfig{Port: 8080, StatusPort: 8080},
+			wantErr: "must differ from nginx port",
+		},
+		{
+			name:    "exporter port",
+			config:  &NginxConfig{StatusPort: ExporterPort},
+			wantErr: "metrics exporter port",
+		},
+		{
+			name:    "TLS port out of range",
+			config:  &NginxConfig{TLSPort: 70000},
+			wantErr: "TLS port must be between",
+		},
+		{
+			name:    "TLS port equal to nginx port",
+			config:  &NginxConfig{Port: 8443, TLSPort: 8443},
+			wantErr: "TLS port 8443 must differ",
+		},
+		{
+			name:    "TLS port equal to exporter port",
+			config:  &NginxConfig{TLSPort: ExporterPort},
+			wantErr: "must differ from the nginx, status and metrics exporter ports",
+		},
+		{
+			name:    "negative cache max age",
+			config:  &NginxConfig{CacheMaxAge: -1},
+			wantErr: "cache max age",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNginxConfig(tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateNginxConfig() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateNginxConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidMonitorInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     bool
+	}{
+		{interval: "30s", want: true},
+		{interval: "1m30s", want: true},
+		{interval: "500ms", want: true},
+		{interval: "0", want: true},
+		{interval: ""},
+		{interval: "30"},
+		{interval: "1.5m"},
+		{interval: "30s1m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			if got := ValidMonitorInterval(tt.interval); got != tt.want {
+				t.Errorf("ValidMonitorInterval(%q) = %v, want %v", tt.interval, got, tt.want)
+			}
+		})
+	}
+}
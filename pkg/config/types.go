@@ -29,7 +29,8 @@ type Config struct {
 	// DefaultKeepOldKeys determines if old keys should be kept by default
 	DefaultKeepOldKeys bool `yaml:"defaultKeepOldKeys"`
 
-	// CleanupOnDelete determines if ConfigMap should be deleted when JWKS is deleted
+	// CleanupOnDelete determines if ConfigMaps and Secrets should be deleted when JWKS is deleted
+	// Can be overridden per JWKS with spec.cleanupOnDelete
 	CleanupOnDelete bool `yaml:"cleanupOnDelete"`
 
 	// Logging configuration
@@ -72,6 +73,21 @@ type MetricsConfig struct {
 	Port     int    `yaml:"port"`
 	Path     string `yaml:"path"`
 	Detailed bool   `yaml:"detailed"`
+	// ServiceMonitor creates a metrics Service and a Prometheus Operator ServiceMonitor for the operator
+	ServiceMonitor ServiceMonitorConfig `yaml:"serviceMonitor"`
+}
+
+// ServiceMonitorConfig represents the ServiceMonitor of the operator metrics
+type ServiceMonitorConfig struct {
+	// Enabled creates the Service and ServiceMonitor in the operator namespace
+	// Both are skipped if the Prometheus Operator CRDs are not installed
+	Enabled bool `yaml:"enabled"`
+	// Interval is the scrape interval, the Prometheus default is used if empty
+	Interval string `yaml:"interval"`
+	// Labels are added to the ServiceMonitor, e.g. the label selected by serviceMonitorSelector of Prometheus
+	Labels map[string]string `yaml:"labels"`
+	// Selector selects the operator pods, defaults to control-plane: controller-manager
+	Selector map[string]string `yaml:"selector"`
 }
 
 // HealthConfig represents health check configuration
@@ -123,6 +139,18 @@ type NginxConfig struct {
 	Resources NginxResources `yaml:"resources"`
 	// CacheMaxAge is the cache max-age in seconds for nginx responses
 	CacheMaxAge int `yaml:"cacheMaxAge"`
+	// SecurityProfile is the default pod security profile (restricted or none)
+	SecurityProfile string `yaml:"securityProfile"`
+	// StatusPort is the internal nginx port serving stub_status
+	StatusPort int `yaml:"statusPort"`
+	// ExporterImage is the default image of the metrics exporter sidecar
+	ExporterImage string `yaml:"exporterImage"`
+	// BuiltinImage is the default image of the built-in JWKS server
+	BuiltinImage string `yaml:"builtinImage"`
+	// ConfigTest runs nginx -t in a Job against a changed config before it is written to the server ConfigMap
+	ConfigTest bool `yaml:"configTest"`
+	// TLSPort is the container port of the https listener enabled by spec.server.tls
+	TLSPort int `yaml:"tlsPort"`
 }
 
 // VerificationConfig represents JWKS verification configuration
@@ -135,6 +163,9 @@ type VerificationConfig struct {
 	RetryDelay time.Duration `yaml:"retryDelay"`
 	// ContextTimeout is the context timeout for verification
 	ContextTimeout time.Duration `yaml:"contextTimeout"`
+	// CABundleFile is a PEM file with CA certificates trusted when verifying servers with spec.server.tls
+	// ca.crt from the TLS Secret is trusted as well
+	CABundleFile string `yaml:"caBundleFile"`
 }
 
 // NginxResources represents nginx container resources
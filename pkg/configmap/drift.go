@@ -0,0 +1,202 @@
+package configmap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// KeyHistoryEntry records a key written by the operator
+type KeyHistoryEntry struct {
+	Kid         string `json:"kid"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// DriftReport describes manual changes of the JWKS ConfigMap
+type DriftReport struct {
+	// Detected is true if content differs from what the operator wrote last time
+	Detected bool
+	// UnknownKids contains kids that were never written by the operator
+	UnknownKids []string
+	// ModifiedKids contains kids written by the operator whose content was changed
+	ModifiedKids []string
+}
+
+// parseKeyHistory parses key history annotation, oldest entries first
+func parseKeyHistory(configMap *corev1.ConfigMap) ([]KeyHistoryEntry, bool) {
+	value, ok := configMap.Annotations[config.AnnotationJWKSKeyHistory]
+	if !ok {
+		return nil, false
+	}
+
+	var history []KeyHistoryEntry
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil, false // Corrupted history is treated as missing
+	}
+	return history, true
+}
+
+// appendKeyHistory adds written keys to history, newest entries last, and trims it to MaxKeyHistory
+func appendKeyHistory(history []KeyHistoryEntry, jwksData *jwks.JWKS) ([]KeyHistoryEntry, error) {
+	written := make(map[string]string, len(jwksData.Keys))
+	for i := range jwksData.Keys {
+		fingerprint, err := jwks.KeyFingerprint(&jwksData.Keys[i])
+		if err != nil {
+			return nil, err
+		}
+		written[jwksData.Keys[i].Kid] = fingerprint
+	}
+
+	result := make([]KeyHistoryEntry, 0, len(history)+len(written))
+	for _, entry := range history {
+		if _, ok := written[entry.Kid]; !ok {
+			result = append(result, entry)
+		}
+	}
+	for i := range jwksData.Keys {
+		kid := jwksData.Keys[i].Kid
+		result = append(result, KeyHistoryEntry{Kid: kid, Fingerprint: written[kid]})
+	}
+
+	if len(result) > config.MaxKeyHistory {
+		result = result[len(result)-config.MaxKeyHistory:]
+	}
+	return result, nil
+}
+
+// setWriteAnnotations records digest and key history of content written by the operator
+func setWriteAnnotations(configMap *corev1.ConfigMap, jsonData []byte, jwksData *jwks.JWKS) error {
+	history, _ := parseKeyHistory(configMap)
+	history, err := appendKeyHistory(history, jwksData)
+	if err != nil {
+		return fmt.Errorf("failed to update key history: %w", err)
+	}
+
+	historyData, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key history: %w", err)
+	}
+
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	configMap.Annotations[config.AnnotationJWKSContentDigest] = jwks.Digest(jsonData)
+	configMap.Annotations[config.AnnotationJWKSKeyHistory] = string(historyData)
+	return nil
+}
+
+// jwksContent returns raw JWKS JSON stored in the ConfigMap
+func jwksContent(configMap *corev1.ConfigMap) []byte {
+	if data, ok := configMap.BinaryData[config.ConfigMapKeyJWKS]; ok {
+		return data
+	}
+	if data, ok := configMap.Data[config.ConfigMapKeyJWKS]; ok {
+		return []byte(data)
+	}
+	return nil
+}
+
+// trustedKeys splits keys into keys written by the operator and changed or unknown ones
+// Without key history (ConfigMap written by an older operator version) all keys are trusted
+func trustedKeys(configMap *corev1.ConfigMap, jwksData *jwks.JWKS) (trusted []jwks.JWK, unknown, modified []string) {
+	history, ok := parseKeyHistory(configMap)
+	if !ok {
+		return jwksData.Keys, nil, nil
+	}
+
+	known := make(map[string]string, len(history))
+	for _, entry := range history {
+		known[entry.Kid] = entry.Fingerprint
+	}
+
+	for i := range jwksData.Keys {
+		key := &jwksData.Keys[i]
+		expected, ok := known[key.Kid]
+		if !ok {
+			unknown = append(unknown, key.Kid)
+			continue
+		}
+		fingerprint, err := jwks.KeyFingerprint(key)
+		if err != nil || fingerprint != expected {
+			modified = append(modified, key.Kid)
+			continue
+		}
+		trusted = append(trusted, *key)
+	}
+
+	return trusted, unknown, modified
+}
+
+// DetectDrift compares JWKS ConfigMap content with the digest and key history of the last operator write
+// Returns an empty report if the ConfigMap does not exist or was never written with a digest
+func (m *Manager) DetectDrift(ctx context.Context, namespace, configMapName string) (*DriftReport, error) {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: configMapName}
+
+	err := m.client.Get(ctx, key, configMap)
+	if apierrors.IsNotFound(err) {
+		return &DriftReport{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap: %w", err)
+	}
+
+	expectedDigest, ok := configMap.Annotations[config.AnnotationJWKSContentDigest]
+	if !ok {
+		return &DriftReport{}, nil // Not written with drift tracking yet
+	}
+
+	content := jwksContent(configMap)
+	if jwks.Digest(content) == expectedDigest {
+		return &DriftReport{}, nil
+	}
+
+	report := &DriftReport{Detected: true}
+
+	var current jwks.JWKS
+	if err := json.Unmarshal(content, &current); err != nil {
+		current = jwks.JWKS{} // Content is not valid JWKS anymore
+	}
+
+	_, report.UnknownKids, report.ModifiedKids = trustedKeys(configMap, &current)
+
+	return report, nil
+}
+
+// GetTrustedJWKS retrieves JWKS from a ConfigMap keeping only keys written by the operator
+// Returns nil if the ConfigMap doesn't exist
+func (m *Manager) GetTrustedJWKS(ctx context.Context, namespace, configMapName string) (*jwks.JWKS, error) {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: configMapName}
+
+	err := m.client.Get(ctx, key, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap: %w", err)
+	}
+
+	content := jwksContent(configMap)
+	if len(content) == 0 {
+		return &jwks.JWKS{}, nil
+	}
+
+	var current jwks.JWKS
+	if err := json.Unmarshal(content, &current); err != nil {
+		if _, ok := parseKeyHistory(configMap); ok {
+			return &jwks.JWKS{}, nil // Tampered content, nothing to trust
+		}
+		return nil, fmt.Errorf("failed to parse JWKS JSON: %w", err)
+	}
+
+	trusted, _, _ := trustedKeys(configMap, &current)
+	return &jwks.JWKS{Keys: trusted}, nil
+}
@@ -0,0 +1,148 @@
+package configmap
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// newTestKey returns an RSA JWK with the given kid and modulus
+func newTestKey(kid, n string) jwks.JWK {
+	return jwks.JWK{Kty: "RSA", Use: "sig", Alg: "RS512", Kid: kid, N: n, E: "AQAB"}
+}
+
+// kids returns kids of the key set in order
+func kids(keySet *jwks.JWKS) []string {
+	result := make([]string, 0, len(keySet.Keys))
+	for i := range keySet.Keys {
+		result = append(result, keySet.Keys[i].Kid)
+	}
+	return result
+}
+
+// setContent replaces the JWKS content of the ConfigMap
+func setContent(t *testing.T, keys ...jwks.JWK) func(*corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		data, err := json.Marshal(&jwks.JWKS{Keys: keys})
+		if err != nil {
+			t.Fatalf("failed to marshal JWKS: %v", err)
+		}
+		configMap.BinaryData[config.ConfigMapKeyJWKS] = data
+	}
+}
+
+func TestManagerDriftDetection(t *testing.T) {
+	key1, key2, key3 := newTestKey("key-1", "AAAA"), newTestKey("key-2", "BBBB"), newTestKey("key-3", "CCCC")
+
+	tests := []struct {
+		name         string
+		modify       func(*corev1.ConfigMap)
+		wantDetected bool
+		wantUnknown  []string
+		wantModified []string
+		wantTrusted  []string
+	}{
+		{
+			name:        "content written by the operator",
+			modify:      func(*corev1.ConfigMap) {},
+			wantTrusted: []string{"key-1", "key-2"},
+		},
+		{
+			name:         "manually added key",
+			modify:       setContent(t, key1, key2, key3),
+			wantDetected: true,
+			wantUnknown:  []string{"key-3"},
+			wantTrusted:  []string{"key-1", "key-2"},
+		},
+		{
+			name:         "modified key material",
+			modify:       setContent(t, newTestKey("key-1", "DDDD"), key2),
+			wantDetected: true,
+			wantModified: []string{"key-1"},
+			wantTrusted:  []string{"key-2"},
+		},
+		{
+			name:         "removed key",
+			modify:       setContent(t, key2),
+			wantDetected: true,
+			wantTrusted:  []string{"key-2"},
+		},
+		{
+			name: "content that is not JWKS",
+			modify: func(configMap *corev1.ConfigMap) {
+				configMap.BinaryData[config.ConfigMapKeyJWKS] = []byte("not json")
+			},
+			wantDetected: true,
+			wantTrusted:  []string{},
+		},
+		{
+			name: "written without drift tracking",
+			modify: func(configMap *corev1.ConfigMap) {
+				configMap.Annotations = nil
+				setContent(t, key1, key2, key3)(configMap)
+			},
+			wantTrusted: []string{"key-1", "key-2", "key-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memClient := newMemoryClient()
+			manager := NewManager(memClient)
+
+			if err := manager.UpdateJWKS(ctx, "default", "jwks", &jwks.JWKS{Keys: []jwks.JWK{key1, key2}}); err != nil {
+				t.Fatalf("UpdateJWKS() unexpected error: %v", err)
+			}
+			configMap := &corev1.ConfigMap{}
+			if err := memClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "jwks"}, configMap); err != nil {
+				t.Fatalf("failed to get ConfigMap: %v", err)
+			}
+			tt.modify(configMap)
+			if err := memClient.Update(ctx, configMap); err != nil {
+				t.Fatalf("failed to update ConfigMap: %v", err)
+			}
+
+			report, err := manager.DetectDrift(ctx, "default", "jwks")
+			if err != nil {
+				t.Fatalf("DetectDrift() unexpected error: %v", err)
+			}
+			if report.Detected != tt.wantDetected {
+				t.Errorf("Detected = %v, want %v", report.Detected, tt.wantDetected)
+			}
+			if !reflect.DeepEqual(report.UnknownKids, tt.wantUnknown) {
+				t.Errorf("UnknownKids = %v, want %v", report.UnknownKids, tt.wantUnknown)
+			}
+			if !reflect.DeepEqual(report.ModifiedKids, tt.wantModified) {
+				t.Errorf("ModifiedKids = %v, want %v", report.ModifiedKids, tt.wantModified)
+			}
+
+			trusted, err := manager.GetTrustedJWKS(ctx, "default", "jwks")
+			if err != nil {
+				t.Fatalf("GetTrustedJWKS() unexpected error: %v", err)
+			}
+			if got := kids(trusted); !reflect.DeepEqual(got, tt.wantTrusted) {
+				t.Errorf("GetTrustedJWKS() kids = %v, want %v", got, tt.wantTrusted)
+			}
+		})
+	}
+}
+
+func TestManagerDetectDriftMissingConfigMap(t *testing.T) {
+	manager := NewManager(newMemoryClient())
+
+	report, err := manager.DetectDrift(context.Background(), "default", "jwks")
+	if err != nil {
+		t.Fatalf("DetectDrift() unexpected error: %v", err)
+	}
+	if report.Detected {
+		t.Error("DetectDrift() reported drift for a missing ConfigMap")
+	}
+}
@@ -1,6 +1,7 @@
 package configmap
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/jwks-operator/jwks-operator/pkg/config"
 	"github.com/jwks-operator/jwks-operator/pkg/jwks"
 )
 
@@ -54,6 +56,9 @@ func (m *Manager) UpdateJWKS(ctx context.Context, namespace, configMapName strin
 				"jwks.json": jsonData,
 			},
 		}
+		if err := setWriteAnnotations(configMap, jsonData, jwksData); err != nil {
+			return err
+		}
 		return m.client.Create(ctx, configMap)
 	}
 	if err != nil {
@@ -65,6 +70,10 @@ func (m *Manager) UpdateJWKS(ctx context.Context, namespace, configMapName strin
 		configMap.BinaryData = make(map[string][]byte)
 	}
 	configMap.BinaryData["jwks.json"] = jsonData
+	delete(configMap.Data, "jwks.json") // BinaryData takes precedence, drop stale copies
+	if err := setWriteAnnotations(configMap, jsonData, jwksData); err != nil {
+		return err
+	}
 
 	return m.client.Update(ctx, configMap)
 }
@@ -103,7 +112,67 @@ func (m *Manager) GetJWKS(ctx context.Context, namespace, configMapName string)
 	return &jwksData, nil
 }
 
+// GetContentDigest returns the digest of jwks.json as stored in the ConfigMap
+// The digest is computed from the content, so it also matches manual changes kept with driftPolicy report
+// Returns an empty string if the ConfigMap or jwks.json doesn't exist
+func (m *Manager) GetContentDigest(ctx context.Context, namespace, configMapName string) (string, error) {
+	_, digest, err := m.GetContent(ctx, namespace, configMapName)
+	return digest, err
+}
+
+// GetContent returns jwks.json as stored in the ConfigMap together with its digest
+// Returns empty strings if the ConfigMap or jwks.json doesn't exist
+func (m *Manager) GetContent(ctx context.Context, namespace, configMapName string) (string, string, error) {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: configMapName}
+
+	err := m.client.Get(ctx, key, configMap)
+	if apierrors.IsNotFound(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get ConfigMap: %w", err)
+	}
+
+	content := jwksContent(configMap)
+	if len(content) == 0 {
+		return "", "", nil
+	}
+	return string(content), jwks.Digest(content), nil
+}
+
 // CreateConfigMap creates a new ConfigMap with JWKS data
 func (m *Manager) CreateConfigMap(ctx context.Context, namespace, configMapName string, jwksData *jwks.JWKS) error {
 	return m.UpdateJWKS(ctx, namespace, configMapName, jwksData)
 }
+
+// UpdateDiscovery stores the OpenID Connect discovery document next to jwks.json
+// A nil document removes the discovery key from the ConfigMap
+func (m *Manager) UpdateDiscovery(ctx context.Context, namespace, configMapName string, discoveryData []byte) error {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: configMapName}
+
+	if err := m.client.Get(ctx, key, configMap); err != nil {
+		return fmt.Errorf("failed to get ConfigMap: %w", err)
+	}
+
+	current, exists := configMap.BinaryData[config.ConfigMapKeyDiscovery]
+	if discoveryData == nil {
+		if !exists {
+			return nil // Nothing to remove
+		}
+		delete(configMap.BinaryData, config.ConfigMapKeyDiscovery)
+		return m.client.Update(ctx, configMap)
+	}
+
+	if exists && bytes.Equal(current, discoveryData) {
+		return nil // Discovery document is up to date
+	}
+
+	if configMap.BinaryData == nil {
+		configMap.BinaryData = make(map[string][]byte)
+	}
+	configMap.BinaryData[config.ConfigMapKeyDiscovery] = discoveryData
+
+	return m.client.Update(ctx, configMap)
+}
@@ -0,0 +1,82 @@
+package configmap
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+func TestManagerGetContentDigest(t *testing.T) {
+	content := []byte(`{"keys":[]}`)
+
+	tests := []struct {
+		name      string
+		configMap *corev1.ConfigMap
+		want      string
+	}{
+		{
+			name: "missing ConfigMap",
+		},
+		{
+			name: "binary data",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "jwks", Namespace: "default"},
+				BinaryData: map[string][]byte{config.ConfigMapKeyJWKS: content},
+			},
+			want: jwks.Digest(content),
+		},
+		{
+			name: "manually written data",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "jwks", Namespace: "default"},
+				Data:       map[string]string{config.ConfigMapKeyJWKS: string(content)},
+			},
+			want: jwks.Digest(content),
+		},
+		{
+			name: "without jwks.json",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "jwks", Namespace: "default"},
+				Data:       map[string]string{"other": "value"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memClient := newMemoryClient()
+			if tt.configMap != nil {
+				if err := memClient.Create(ctx, tt.configMap); err != nil {
+					t.Fatalf("failed to create ConfigMap: %v", err)
+				}
+			}
+
+			manager := NewManager(memClient)
+			got, err := manager.GetContentDigest(ctx, "default", "jwks")
+			if err != nil {
+				t.Fatalf("GetContentDigest() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetContentDigest() = %q, want %q", got, tt.want)
+			}
+
+			gotContent, gotDigest, err := manager.GetContent(ctx, "default", "jwks")
+			if err != nil {
+				t.Fatalf("GetContent() unexpected error: %v", err)
+			}
+			wantContent := ""
+			if tt.want != "" {
+				wantContent = string(content)
+			}
+			if gotContent != wantContent || gotDigest != tt.want {
+				t.Errorf("GetContent() = %q, %q, want %q, %q", gotContent, gotDigest, wantContent, tt.want)
+			}
+		})
+	}
+}
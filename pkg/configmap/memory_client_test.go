@@ -0,0 +1,105 @@
+package configmap
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// memoryClient is an in-memory client for ConfigMaps and Namespaces
+// Methods that are not overridden panic through the nil embedded client
+type memoryClient struct {
+	client.Client
+	objects map[string]client.Object
+}
+
+// newMemoryClient returns a client holding copies of the given objects
+func newMemoryClient(objects ...client.Object) *memoryClient {
+	c := &memoryClient{objects: make(map[string]client.Object)}
+	for _, obj := range objects {
+		c.objects[objectKey(obj, client.ObjectKeyFromObject(obj))] = obj.DeepCopyObject().(client.Object)
+	}
+	return c
+}
+
+// objectKey returns the storage key of an object
+func objectKey(obj client.Object, key client.ObjectKey) string {
+	return fmt.Sprintf("%T/%s/%s", obj, key.Namespace, key.Name)
+}
+
+// notFound returns a NotFound error for the object
+func notFound(obj client.Object, name string) error {
+	return apierrors.NewNotFound(schema.GroupResource{Resource: fmt.Sprintf("%T", obj)}, name)
+}
+
+func (c *memoryClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	stored, ok := c.objects[objectKey(obj, key)]
+	if !ok {
+		return notFound(obj, key.Name)
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(stored.DeepCopyObject()).Elem())
+	return nil
+}
+
+func (c *memoryClient) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	matches := func(obj client.Object) bool {
+		if listOpts.Namespace != "" && obj.GetNamespace() != listOpts.Namespace {
+			return false
+		}
+		return listOpts.LabelSelector == nil || listOpts.LabelSelector.Matches(labels.Set(obj.GetLabels()))
+	}
+
+	switch l := list.(type) {
+	case *corev1.ConfigMapList:
+		for _, obj := range c.objects {
+			if cm, ok := obj.(*corev1.ConfigMap); ok && matches(cm) {
+				l.Items = append(l.Items, *cm.DeepCopy())
+			}
+		}
+	case *corev1.NamespaceList:
+		for _, obj := range c.objects {
+			if ns, ok := obj.(*corev1.Namespace); ok && matches(ns) {
+				l.Items = append(l.Items, *ns.DeepCopy())
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported list type %T", list)
+	}
+	return nil
+}
+
+func (c *memoryClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	key := objectKey(obj, client.ObjectKeyFromObject(obj))
+	if _, ok := c.objects[key]; ok {
+		return apierrors.NewAlreadyExists(schema.GroupResource{Resource: fmt.Sprintf("%T", obj)}, obj.GetName())
+	}
+	c.objects[key] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (c *memoryClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	key := objectKey(obj, client.ObjectKeyFromObject(obj))
+	if _, ok := c.objects[key]; !ok {
+		return notFound(obj, obj.GetName())
+	}
+	c.objects[key] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (c *memoryClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	key := objectKey(obj, client.ObjectKeyFromObject(obj))
+	if _, ok := c.objects[key]; !ok {
+		return notFound(obj, obj.GetName())
+	}
+	delete(c.objects, key)
+	return nil
+}
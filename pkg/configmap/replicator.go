@@ -0,0 +1,300 @@
+package configmap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// ReplicationResult describes the outcome of a replication sync
+type ReplicationResult struct {
+	// Synced contains namespaces with an up-to-date copy
+	Synced []string
+	// Conflicts contains namespaces where a ConfigMap with the same name is not managed by the operator
+	Conflicts []string
+	// Removed contains namespaces where a stale copy was deleted
+	Removed []string
+}
+
+// Replicator copies the JWKS ConfigMap into other namespaces
+type Replicator struct {
+	client client.Client
+}
+
+// NewReplicator creates a new ConfigMap replicator
+func NewReplicator(client client.Client) *Replicator {
+	return &Replicator{
+		client: client,
+	}
+}
+
+// replicaLabels returns labels identifying replicas of a JWKS ConfigMap
+func replicaLabels(jwksObj *v1alpha1.JWKS) map[string]string {
+	return map[string]string{
+		config.LabelJWKSConfig:      jwksObj.Name,
+		config.LabelSourceNamespace: jwksObj.Namespace,
+		config.LabelManagedBy:       config.LabelManagedByValue,
+	}
+}
+
+// TargetNamespaces resolves namespaces selected by spec.replicateTo
+// The JWKS namespace itself and terminating namespaces are never selected
+func (r *Replicator) TargetNamespaces(ctx context.Context, jwksObj *v1alpha1.JWKS) ([]string, error) {
+	spec := jwksObj.Spec.ReplicateTo
+	if spec == nil {
+		return nil, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.client.List(ctx, namespaceList); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	listed := make(map[string]bool, len(spec.Namespaces))
+	for _, ns := range spec.Namespaces {
+		listed[ns] = true
+	}
+
+	var selector labels.Selector
+	if spec.NamespaceSelector != nil {
+		s, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespaceSelector: %w", err)
+		}
+		selector = s
+	}
+
+	targets := make([]string, 0)
+	for i := range namespaceList.Items {
+		ns := &namespaceList.Items[i]
+		if ns.Name == jwksObj.Namespace || ns.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		if listed[ns.Name] || (selector != nil && selector.Matches(labels.Set(ns.Labels))) {
+			targets = append(targets, ns.Name)
+		}
+	}
+
+	sort.Strings(targets)
+	return targets, nil
+}
+
+// Sync copies the JWKS ConfigMap into target namespaces and removes copies
+// from namespaces that are no longer selected
+func (r *Replicator) Sync(ctx context.Context, jwksObj *v1alpha1.JWKS) (*ReplicationResult, error) {
+	result := &ReplicationResult{}
+
+	targets, err := r.TargetNamespaces(ctx, jwksObj)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targets) > 0 {
+		source := &corev1.ConfigMap{}
+		sourceKey := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Spec.ConfigMapName}
+		if err := r.client.Get(ctx, sourceKey, source); err != nil {
+			return nil, fmt.Errorf("failed to get source ConfigMap: %w", err)
+		}
+
+		for _, ns := range targets {
+			conflict, err := r.syncReplica(ctx, jwksObj, source, ns)
+			if err != nil {
+				return nil, fmt.Errorf("failed to replicate ConfigMap to namespace %s: %w", ns, err)
+			}
+			if conflict {
+				result.Conflicts = append(result.Conflicts, ns)
+				continue
+			}
+			result.Synced = append(result.Synced, ns)
+		}
+	}
+
+	removed, err := r.deleteReplicas(ctx, jwksObj, targets)
+	if err != nil {
+		return nil, err
+	}
+	result.Removed = removed
+
+	return result, nil
+}
+
+// InSync reports whether every target namespace holds an up-to-date replica and no stale replicas exist
+// Namespaces recorded in status.replicaConflicts count as in sync while the unmanaged ConfigMap exists
+func (r *Replicator) InSync(ctx context.Context, jwksObj *v1alpha1.JWKS) (bool, error) {
+	targets, err := r.TargetNamespaces(ctx, jwksObj)
+	if err != nil {
+		return false, err
+	}
+
+	replicas, err := r.listReplicas(ctx, jwksObj)
+	if err != nil {
+		return false, err
+	}
+
+	targetSet := make(map[string]bool, len(targets))
+	for _, ns := range targets {
+		targetSet[ns] = true
+	}
+
+	current := make(map[string]*corev1.ConfigMap, len(replicas))
+	for i := range replicas {
+		replica := &replicas[i]
+		if replica.Name != jwksObj.Spec.ConfigMapName || !targetSet[replica.Namespace] {
+			return false, nil // Stale replica
+		}
+		current[replica.Namespace] = replica
+	}
+
+	conflicts := make(map[string]bool, len(jwksObj.Status.ReplicaConflicts))
+	for _, ns := range jwksObj.Status.ReplicaConflicts {
+		if !targetSet[ns] {
+			return false, nil // Conflict out of scope, status is outdated
+		}
+		conflicts[ns] = true
+	}
+
+	var source *corev1.ConfigMap
+	if len(current) > 0 {
+		source = &corev1.ConfigMap{}
+		sourceKey := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Spec.ConfigMapName}
+		if err := r.client.Get(ctx, sourceKey, source); err != nil {
+			return false, fmt.Errorf("failed to get source ConfigMap: %w", err)
+		}
+	}
+
+	for _, ns := range targets {
+		replica, ok := current[ns]
+		if !ok {
+			if !conflicts[ns] {
+				return false, nil // Missing replica
+			}
+			exists, err := r.configMapExists(ctx, ns, jwksObj.Spec.ConfigMapName)
+			if err != nil || !exists {
+				return false, err // Conflict resolved, the replica can be created
+			}
+			continue
+		}
+		if conflicts[ns] ||
+			!equality.Semantic.DeepEqual(replica.Data, source.Data) ||
+			!equality.Semantic.DeepEqual(replica.BinaryData, source.BinaryData) {
+			return false, nil // Outdated replica
+		}
+	}
+	return true, nil
+}
+
+// configMapExists reports whether a ConfigMap with the given name exists in the namespace
+func (r *Replicator) configMapExists(ctx context.Context, namespace, name string) (bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get ConfigMap in namespace %s: %w", namespace, err)
+	}
+	return true, nil
+}
+
+// DeleteReplicas deletes all replicas of the JWKS ConfigMap
+func (r *Replicator) DeleteReplicas(ctx context.Context, jwksObj *v1alpha1.JWKS) error {
+	_, err := r.deleteReplicas(ctx, jwksObj, nil)
+	return err
+}
+
+// syncReplica creates or updates a replica in the given namespace
+// Returns true if an unmanaged ConfigMap with the same name already exists
+func (r *Replicator) syncReplica(ctx context.Context, jwksObj *v1alpha1.JWKS, source *corev1.ConfigMap, namespace string) (bool, error) {
+	replica := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: source.Name}
+
+	err := r.client.Get(ctx, key, replica)
+	if apierrors.IsNotFound(err) {
+		replica = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      source.Name,
+				Namespace: namespace,
+				Labels:    replicaLabels(jwksObj),
+			},
+			Data:       source.Data,
+			BinaryData: source.BinaryData,
+		}
+		return false, r.client.Create(ctx, replica)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get replica: %w", err)
+	}
+
+	// Never overwrite ConfigMaps that were not created as replicas of this JWKS
+	for k, v := range replicaLabels(jwksObj) {
+		if replica.Labels[k] != v {
+			return true, nil
+		}
+	}
+
+	if equality.Semantic.DeepEqual(replica.Data, source.Data) &&
+		equality.Semantic.DeepEqual(replica.BinaryData, source.BinaryData) {
+		return false, nil // Replica is up to date
+	}
+
+	replica.Data = source.Data
+	replica.BinaryData = source.BinaryData
+	return false, r.client.Update(ctx, replica)
+}
+
+// listReplicas returns existing replicas of the JWKS ConfigMap in all namespaces
+func (r *Replicator) listReplicas(ctx context.Context, jwksObj *v1alpha1.JWKS) ([]corev1.ConfigMap, error) {
+	configMapList := &corev1.ConfigMapList{}
+	if err := r.client.List(ctx, configMapList, client.MatchingLabels(replicaLabels(jwksObj))); err != nil {
+		return nil, fmt.Errorf("failed to list replicas: %w", err)
+	}
+
+	replicas := make([]corev1.ConfigMap, 0, len(configMapList.Items))
+	for _, cm := range configMapList.Items {
+		if cm.Namespace == jwksObj.Namespace {
+			continue
+		}
+		replicas = append(replicas, cm)
+	}
+	return replicas, nil
+}
+
+// deleteReplicas deletes replicas outside of the keep list and returns their namespaces
+// Replicas left over from a previous spec.configMapName are deleted as well
+func (r *Replicator) deleteReplicas(ctx context.Context, jwksObj *v1alpha1.JWKS, keep []string) ([]string, error) {
+	replicas, err := r.listReplicas(ctx, jwksObj)
+	if err != nil {
+		return nil, err
+	}
+
+	keepSet := make(map[string]bool, len(keep))
+	for _, ns := range keep {
+		keepSet[ns] = true
+	}
+
+	removed := make([]string, 0)
+	for i := range replicas {
+		replica := &replicas[i]
+		if keepSet[replica.Namespace] && replica.Name == jwksObj.Spec.ConfigMapName {
+			continue
+		}
+		if err := r.client.Delete(ctx, replica); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete replica in namespace %s: %w", replica.Namespace, err)
+		}
+		removed = append(removed, replica.Namespace)
+	}
+
+	sort.Strings(removed)
+	return removed, nil
+}
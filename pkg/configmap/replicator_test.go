@@ -0,0 +1,277 @@
+package configmap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+const testJWKSNamespace = "auth"
+
+// newReplicatedJWKS returns a JWKS resource replicated with the given spec
+func newReplicatedJWKS(spec *v1alpha1.ReplicationSpec) *v1alpha1.JWKS {
+	return &v1alpha1.JWKS{
+		ObjectMeta: metav1.ObjectMeta{Name: "jwks", Namespace: testJWKSNamespace},
+		Spec:       v1alpha1.JWKSSpec{ConfigMapName: "jwks-keys", ReplicateTo: spec},
+	}
+}
+
+// newNamespace returns a namespace with the given labels
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+// newJWKSConfigMap returns a JWKS ConfigMap in the namespace, a replica of jwksObj when jwksObj is set
+func newJWKSConfigMap(jwksObj *v1alpha1.JWKS, namespace, content string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "jwks-keys", Namespace: namespace},
+		Data:       map[string]string{"jwks.json": content},
+	}
+	if jwksObj != nil {
+		configMap.Labels = replicaLabels(jwksObj)
+	}
+	return configMap
+}
+
+// testNamespaces returns the namespaces used by the replication tests
+func testNamespaces() []client.Object {
+	terminating := newNamespace("team-c", map[string]string{"team": "x"})
+	terminating.Status.Phase = corev1.NamespaceTerminating
+	return []client.Object{
+		newNamespace(testJWKSNamespace, map[string]string{"team": "x"}),
+		newNamespace("team-a", map[string]string{"team": "x"}),
+		newNamespace("team-b", nil),
+		terminating,
+	}
+}
+
+func TestReplicatorTargetNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *v1alpha1.ReplicationSpec
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "replication not configured",
+			spec: nil,
+			want: nil,
+		},
+		{
+			name: "explicit namespaces",
+			spec: &v1alpha1.ReplicationSpec{Namespaces: []string{"team-b", "missing"}},
+			want: []string{"team-b"},
+		},
+		{
+			name: "selector skips the JWKS and terminating namespaces",
+			spec: &v1alpha1.ReplicationSpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "x"}}},
+			want: []string{"team-a"},
+		},
+		{
+			name: "namespaces and selector",
+			spec: &v1alpha1.ReplicationSpec{
+				Namespaces:        []string{"team-b", testJWKSNamespace},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "x"}},
+			},
+			want: []string{"team-a", "team-b"},
+		},
+		{
+			name: "invalid selector",
+			spec: &v1alpha1.ReplicationSpec{NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Unknown"}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replicator := NewReplicator(newMemoryClient(testNamespaces()...))
+
+			got, err := replicator.TargetNamespaces(context.Background(), newReplicatedJWKS(tt.spec))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("TargetNamespaces() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TargetNamespaces() unexpected error: %v", err)
+			}
+			if len(got) != 0 || len(tt.want) != 0 {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("TargetNamespaces() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReplicatorSync(t *testing.T) {
+	jwksObj := newReplicatedJWKS(&v1alpha1.ReplicationSpec{Namespaces: []string{"team-a", "team-b"}})
+	other := newReplicatedJWKS(nil)
+	other.Name = "other"
+
+	tests := []struct {
+		name          string
+		existing      []client.Object
+		wantSynced    []string
+		wantConflicts []string
+		wantRemoved   []string
+		wantReplicas  map[string]string
+	}{
+		{
+			name:         "creates replicas",
+			wantSynced:   []string{"team-a", "team-b"},
+			wantReplicas: map[string]string{"team-a": "v2", "team-b": "v2"},
+		},
+		{
+			name:         "updates stale replica",
+			existing:     []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1")},
+			wantSynced:   []string{"team-a", "team-b"},
+			wantReplicas: map[string]string{"team-a": "v2", "team-b": "v2"},
+		},
+		{
+			name:          "keeps unmanaged ConfigMap",
+			existing:      []client.Object{newJWKSConfigMap(nil, "team-a", "user"), newJWKSConfigMap(other, "team-b", "v1")},
+			wantConflicts: []string{"team-a", "team-b"},
+			wantReplicas:  map[string]string{"team-a": "user", "team-b": "v1"},
+		},
+		{
+			name:         "removes replica out of scope",
+			existing:     []client.Object{newJWKSConfigMap(jwksObj, "team-c", "v1")},
+			wantSynced:   []string{"team-a", "team-b"},
+			wantRemoved:  []string{"team-c"},
+			wantReplicas: map[string]string{"team-a": "v2", "team-b": "v2", "team-c": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := append(testNamespaces(), newJWKSConfigMap(nil, testJWKSNamespace, "v2"))
+			memClient := newMemoryClient(append(objects, tt.existing...)...)
+			replicator := NewReplicator(memClient)
+
+			result, err := replicator.Sync(context.Background(), jwksObj)
+			if err != nil {
+				t.Fatalf("Sync() unexpected error: %v", err)
+			}
+			assertNamespaces(t, "Synced", result.Synced, tt.wantSynced)
+			assertNamespaces(t, "Conflicts", result.Conflicts, tt.wantConflicts)
+			assertNamespaces(t, "Removed", result.Removed, tt.wantRemoved)
+
+			for ns, want := range tt.wantReplicas {
+				replica := &corev1.ConfigMap{}
+				err := memClient.Get(context.Background(), types.NamespacedName{Namespace: ns, Name: "jwks-keys"}, replica)
+				if want == "" {
+					if err == nil {
+						t.Errorf("replica in %s was not deleted", ns)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("replica in %s: %v", ns, err)
+				}
+				if got := replica.Data["jwks.json"]; got != want {
+					t.Errorf("replica in %s = %q, want %q", ns, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReplicatorInSync(t *testing.T) {
+	jwksObj := newReplicatedJWKS(&v1alpha1.ReplicationSpec{Namespaces: []string{"team-a", "team-b"}})
+	source := newJWKSConfigMap(nil, testJWKSNamespace, "v1")
+
+	tests := []struct {
+		name      string
+		existing  []client.Object
+		conflicts []string
+		want      bool
+	}{
+		{
+			name:     "replicas in every target namespace",
+			existing: []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1"), newJWKSConfigMap(jwksObj, "team-b", "v1")},
+			want:     true,
+		},
+		{
+			name:     "missing replica",
+			existing: []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1")},
+			want:     false,
+		},
+		{
+			name: "replica out of scope",
+			existing: []client.Object{
+				newJWKSConfigMap(jwksObj, "team-a", "v1"),
+				newJWKSConfigMap(jwksObj, "team-b", "v1"),
+				newJWKSConfigMap(jwksObj, "team-c", "v1"),
+			},
+			want: false,
+		},
+		{
+			name:     "outdated replica content",
+			existing: []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1"), newJWKSConfigMap(jwksObj, "team-b", "v0")},
+			want:     false,
+		},
+		{
+			name:      "recorded conflict with unmanaged ConfigMap",
+			existing:  []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1"), newJWKSConfigMap(nil, "team-b", "user")},
+			conflicts: []string{"team-b"},
+			want:      true,
+		},
+		{
+			name:     "unrecorded conflict",
+			existing: []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1"), newJWKSConfigMap(nil, "team-b", "user")},
+			want:     false,
+		},
+		{
+			name:      "conflict resolved",
+			existing:  []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1")},
+			conflicts: []string{"team-b"},
+			want:      false,
+		},
+		{
+			name:      "conflict out of scope",
+			existing:  []client.Object{newJWKSConfigMap(jwksObj, "team-a", "v1"), newJWKSConfigMap(jwksObj, "team-b", "v1")},
+			conflicts: []string{"team-c"},
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := append(testNamespaces(), source.DeepCopy())
+			replicator := NewReplicator(newMemoryClient(append(objects, tt.existing...)...))
+			jwksObj := jwksObj.DeepCopy()
+			jwksObj.Status.ReplicaConflicts = tt.conflicts
+
+			got, err := replicator.InSync(context.Background(), jwksObj)
+			if err != nil {
+				t.Fatalf("InSync() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("InSync() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// assertNamespaces compares namespace lists, treating nil and empty lists as equal
+func assertNamespaces(t *testing.T, field string, got, want []string) {
+	t.Helper()
+
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
@@ -19,49 +19,58 @@ func NewUpdateStrategy(manager *Manager) *UpdateStrategy {
 	}
 }
 
-// Apply applies the update strategy
-func (s *UpdateStrategy) Apply(ctx context.Context, namespace, configMapName string, newJWKS *jwks.JWKS, strategy string, keepOldKeys bool) error {
+// Apply applies the update strategy and returns the JWKS that was written to the ConfigMap
+func (s *UpdateStrategy) Apply(ctx context.Context, namespace, configMapName string, newJWKS *jwks.JWKS, strategy string, keepOldKeys bool) (*jwks.JWKS, error) {
+	desired, err := s.Plan(ctx, namespace, configMapName, newJWKS, strategy, keepOldKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.UpdateJWKS(ctx, namespace, configMapName, desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// Plan computes the JWKS the update strategy would write without modifying the ConfigMap
+func (s *UpdateStrategy) Plan(ctx context.Context, namespace, configMapName string, newJWKS *jwks.JWKS, strategy string, keepOldKeys bool) (*jwks.JWKS, error) {
 	if newJWKS == nil {
-		return fmt.Errorf("new JWKS is nil")
+		return nil, fmt.Errorf("new JWKS is nil")
 	}
 
 	switch strategy {
 	case "rolling":
-		return s.applyRollingStrategy(ctx, namespace, configMapName, newJWKS, keepOldKeys)
+		return s.planRollingStrategy(ctx, namespace, configMapName, newJWKS, keepOldKeys)
 	case "immediate":
-		return s.applyImmediateStrategy(ctx, namespace, configMapName, newJWKS)
+		return newJWKS, nil // Replace all keys
 	default:
-		return fmt.Errorf("unknown update strategy: %s", strategy)
+		return nil, fmt.Errorf("unknown update strategy: %s", strategy)
 	}
 }
 
-// applyRollingStrategy applies rolling update strategy (graceful rotation)
-func (s *UpdateStrategy) applyRollingStrategy(ctx context.Context, namespace, configMapName string, newJWKS *jwks.JWKS, keepOldKeys bool) error {
-	if keepOldKeys {
-		// Get current JWKS
-		oldJWKS, err := s.manager.GetJWKS(ctx, namespace, configMapName)
-		if err != nil {
-			return fmt.Errorf("failed to get current JWKS: %w", err)
-		}
-
-		// Merge old and new keys
-		if oldJWKS != nil {
-			generator := jwks.NewGenerator()
-			mergedJWKS, err := generator.MergeJWKS(oldJWKS, newJWKS)
-			if err != nil {
-				return fmt.Errorf("failed to merge JWKS: %w", err)
-			}
-			newJWKS = mergedJWKS
-		}
+// planRollingStrategy plans rolling update strategy (graceful rotation)
+// Only keys previously written by the operator are kept, manually added keys are dropped
+func (s *UpdateStrategy) planRollingStrategy(ctx context.Context, namespace, configMapName string, newJWKS *jwks.JWKS, keepOldKeys bool) (*jwks.JWKS, error) {
+	if !keepOldKeys {
+		return newJWKS, nil
 	}
 
-	// Update ConfigMap
-	return s.manager.UpdateJWKS(ctx, namespace, configMapName, newJWKS)
-}
+	// Get current JWKS
+	oldJWKS, err := s.manager.GetTrustedJWKS(ctx, namespace, configMapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current JWKS: %w", err)
+	}
+	if oldJWKS == nil || len(oldJWKS.Keys) == 0 {
+		return newJWKS, nil
+	}
 
-// applyImmediateStrategy applies immediate update strategy (replace all keys)
-func (s *UpdateStrategy) applyImmediateStrategy(ctx context.Context, namespace, configMapName string, newJWKS *jwks.JWKS) error {
-	return s.manager.UpdateJWKS(ctx, namespace, configMapName, newJWKS)
+	// Merge old and new keys
+	generator := jwks.NewGenerator()
+	mergedJWKS, err := generator.MergeJWKS(oldJWKS, newJWKS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge JWKS: %w", err)
+	}
+	return mergedJWKS, nil
 }
 
 // ShouldUpdate determines if an update is needed
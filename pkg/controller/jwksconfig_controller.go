@@ -8,12 +8,21 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
@@ -24,33 +33,43 @@ import (
 type JWKSReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
 	Config     *config.Config
 	Logger     *zap.Logger
 	Reconciler *reconciler.Reconciler
 }
 
 // NewJWKSReconciler creates a new JWKS reconciler
-func NewJWKSReconciler(client client.Client, scheme *runtime.Scheme, cfg *config.Config, logger *zap.Logger) *JWKSReconciler {
+func NewJWKSReconciler(client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, cfg *config.Config, logger *zap.Logger) *JWKSReconciler {
 	return &JWKSReconciler{
 		Client:     client,
 		Scheme:     scheme,
+		Recorder:   recorder,
 		Config:     cfg,
 		Logger:     logger,
-		Reconciler: reconciler.NewReconciler(client, cfg, logger),
+		Reconciler: reconciler.NewReconciler(client, recorder, cfg, logger),
 	}
 }
 
 //+kubebuilder:rbac:groups=example.com,resources=jwks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=example.com,resources=jwks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=example.com,resources=jwks/finalizers,verbs=update
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
-//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=create;get;list;update;patch;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=create;delete;get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=create;delete;get;list;patch;update;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *JWKSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -85,6 +104,15 @@ func (r *JWKSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return r.handleDeletion(ctx, jwks)
 	}
 
+	// Ensure finalizer is present so that cleanup runs before JWKS is removed
+	if !controllerutil.ContainsFinalizer(jwks, config.FinalizerName) {
+		controllerutil.AddFinalizer(jwks, config.FinalizerName)
+		if err := r.Update(ctx, jwks); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Reconcile the JWKS
 	if err := r.Reconciler.Reconcile(ctx, jwks); err != nil {
 		// Check if error is due to missing Secret
@@ -103,6 +131,9 @@ func (r *JWKSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 	// Determine requeue interval based on fast reconciliation logic after restart
 	requeueInterval := r.getRequeueInterval(jwks)
+	if r.Reconciler.PropagationPending(jwks) && requeueInterval > config.DefaultPropagationCheckInterval {
+		requeueInterval = config.DefaultPropagationCheckInterval
+	}
 
 	return ctrl.Result{RequeueAfter: requeueInterval}, nil
 }
@@ -249,14 +280,24 @@ func (r *JWKSReconciler) updateFastReconcileCountAsync(jwks *v1alpha1.JWKS, coun
 func (r *JWKSReconciler) handleDeletion(ctx context.Context, jwks *v1alpha1.JWKS) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	// Cleanup nginx resources (Service and Deployment)
-	if err := r.Reconciler.Cleanup(ctx, jwks.Namespace, jwks.Name); err != nil {
-		logger.Error(err, "Failed to cleanup nginx resources")
-		// Continue with deletion even if cleanup fails
+	if !controllerutil.ContainsFinalizer(jwks, config.FinalizerName) {
+		return ctrl.Result{}, nil // Cleanup already done
 	}
 
-	// Note: ConfigMaps are not deleted by default (cleanupOnDelete=false)
-	// This allows manual cleanup or reuse of ConfigMaps
+	// Cleanup nginx resources and replicas, delete or orphan data according to cleanupOnDelete
+	if err := r.Reconciler.Finalize(ctx, jwks); err != nil {
+		logger.Error(err, "Failed to cleanup resources, will retry")
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(jwks, config.FinalizerName)
+	if err := r.Update(ctx, jwks); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to remove finalizer")
+		return ctrl.Result{}, err
+	}
 
 	return ctrl.Result{}, nil
 }
@@ -266,10 +307,97 @@ func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
 
+// mapNamespaceToJWKS enqueues JWKS resources that replicate into other namespaces
+// when a namespace is created, relabeled or deleted
+func (r *JWKSReconciler) mapNamespaceToJWKS(ctx context.Context, _ client.Object) []reconcile.Request {
+	jwksList := &v1alpha1.JWKSList{}
+	if err := r.List(ctx, jwksList); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list JWKS for namespace event")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range jwksList.Items {
+		if jwksList.Items[i].Spec.ReplicateTo == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&jwksList.Items[i]),
+		})
+	}
+	return requests
+}
+
+// mapTLSSecretToJWKS enqueues JWKS resources serving https with the Secret, so a renewed certificate rolls the pods
+func (r *JWKSReconciler) mapTLSSecretToJWKS(ctx context.Context, obj client.Object) []reconcile.Request {
+	jwksList := &v1alpha1.JWKSList{}
+	if err := r.List(ctx, jwksList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list JWKS for Secret event")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range jwksList.Items {
+		server := jwksList.Items[i].Spec.Server
+		if server == nil || server.TLS == nil || server.TLS.SecretName != obj.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&jwksList.Items[i]),
+		})
+	}
+	return requests
+}
+
+// mapTemplateConfigMapToJWKS enqueues JWKS resources rendering their nginx config from the ConfigMap,
+// so changes of the template or snippets are applied without waiting for the next resync
+func (r *JWKSReconciler) mapTemplateConfigMapToJWKS(ctx context.Context, obj client.Object) []reconcile.Request {
+	jwksList := &v1alpha1.JWKSList{}
+	if err := r.List(ctx, jwksList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list JWKS for ConfigMap event")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range jwksList.Items {
+		server := jwksList.Items[i].Spec.Server
+		if server == nil || server.Template == nil || server.Template.ConfigMapName != obj.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&jwksList.Items[i]),
+		})
+	}
+	return requests
+}
+
+// mapConfigTestJobToJWKS enqueues the JWKS whose changed nginx config is tested by the Job
+// Test Jobs have no owner reference, the JWKS is found by the jwks-config label
+func (r *JWKSReconciler) mapConfigTestJobToJWKS(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[config.LabelManagedBy] != config.LabelManagedByValue || labels[config.LabelJWKSConfig] == "" {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Namespace: obj.GetNamespace(), Name: labels[config.LabelJWKSConfig]},
+	}}
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *JWKSReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.JWKS{}).
 		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.Service{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToJWKS)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(r.mapConfigTestJobToJWKS)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mapTLSSecretToJWKS)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mapTemplateConfigMapToJWKS)).
 		Complete(r)
 }
@@ -0,0 +1,189 @@
+package expose
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/utils"
+)
+
+// httpRouteGVK is the GroupVersionKind of Gateway API HTTPRoute
+// HTTPRoute is handled as an unstructured object so Gateway API CRDs stay optional
+var httpRouteGVK = schema.GroupVersionKind{
+	Group:   config.GatewayAPIGroup,
+	Version: config.GatewayAPIVersion,
+	Kind:    config.HTTPRouteKind,
+}
+
+// IsGatewayAPINotInstalled checks if the error is caused by missing Gateway API CRDs
+func IsGatewayAPINotInstalled(err error) bool {
+	return meta.IsNoMatchError(err)
+}
+
+// newHTTPRoute returns an empty unstructured HTTPRoute
+func newHTTPRoute() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	return route
+}
+
+// buildHTTPRouteSpec builds the HTTPRoute spec routing all paths to the JWKS Service
+func buildHTTPRouteSpec(jwksObj *v1alpha1.JWKS, spec *v1alpha1.HTTPRouteExposeSpec) map[string]interface{} {
+	parentRefs := make([]interface{}, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := map[string]interface{}{
+			"name": ref.Name,
+		}
+		if ref.Group != "" {
+			parentRef["group"] = ref.Group
+		}
+		if ref.Kind != "" {
+			parentRef["kind"] = ref.Kind
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	routeSpec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": "/",
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": jwksObj.Name,
+						"port": int64(nginx.ServicePort(jwksObj.Spec.Service)),
+					},
+				},
+			},
+		},
+	}
+
+	if len(spec.Hostnames) > 0 {
+		hostnames := make([]interface{}, 0, len(spec.Hostnames))
+		for _, hostname := range spec.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		routeSpec["hostnames"] = hostnames
+	}
+
+	return routeSpec
+}
+
+// specHash returns a hash of the desired HTTPRoute spec
+// The API server adds defaults to HTTPRoute, so the desired spec is compared by hash instead of content
+func specHash(routeSpec map[string]interface{}) (string, error) {
+	data, err := json.Marshal(routeSpec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal HTTPRoute spec: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// ensureHTTPRoute creates or updates the HTTPRoute for the JWKS Service
+func (m *Manager) ensureHTTPRoute(ctx context.Context, jwksObj *v1alpha1.JWKS, spec *v1alpha1.HTTPRouteExposeSpec) error {
+	desiredSpec := buildHTTPRouteSpec(jwksObj, spec)
+	hash, err := specHash(desiredSpec)
+	if err != nil {
+		return err
+	}
+
+	route := newHTTPRoute()
+	key := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Name}
+
+	err = m.client.Get(ctx, key, route)
+	if apierrors.IsNotFound(err) {
+		route = newHTTPRoute()
+		route.SetName(jwksObj.Name)
+		route.SetNamespace(jwksObj.Namespace)
+		route.SetLabels(labels(jwksObj))
+		utils.ApplyManagedAnnotations(route, spec.Annotations)
+		setSpecHash(route, hash)
+		route.Object["spec"] = desiredSpec
+		if _, err := utils.SetControllerReference(jwksObj, route, m.client.Scheme()); err != nil {
+			return err
+		}
+		return m.client.Create(ctx, route)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get HTTPRoute: %w", err)
+	}
+
+	if !isManaged(route, jwksObj) {
+		return fmt.Errorf("HTTPRoute %s: %w", jwksObj.Name, ErrNotManaged)
+	}
+
+	changed := utils.ApplyManagedAnnotations(route, spec.Annotations)
+	if route.GetAnnotations()[config.AnnotationSpecHash] != hash {
+		setSpecHash(route, hash)
+		route.Object["spec"] = desiredSpec
+		changed = true
+	}
+	ownerChanged, err := utils.SetControllerReference(jwksObj, route, m.client.Scheme())
+	if err != nil {
+		return err
+	}
+
+	if !changed && !ownerChanged {
+		return nil
+	}
+	return m.client.Update(ctx, route)
+}
+
+// setSpecHash stores the hash of the desired spec in the HTTPRoute annotations
+func setSpecHash(route *unstructured.Unstructured, hash string) {
+	annotations := route.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[config.AnnotationSpecHash] = hash
+	route.SetAnnotations(annotations)
+}
+
+// deleteHTTPRoute deletes the HTTPRoute created for JWKS, unmanaged objects are kept
+// Missing Gateway API CRDs mean there is nothing to delete
+func (m *Manager) deleteHTTPRoute(ctx context.Context, jwksObj *v1alpha1.JWKS) error {
+	route := newHTTPRoute()
+	key := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Name}
+
+	if err := m.client.Get(ctx, key, route); err != nil {
+		if apierrors.IsNotFound(err) || IsGatewayAPINotInstalled(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get HTTPRoute: %w", err)
+	}
+
+	if !isManaged(route, jwksObj) {
+		return nil
+	}
+
+	if err := m.client.Delete(ctx, route); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
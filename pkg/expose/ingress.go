@@ -0,0 +1,131 @@
+package expose
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/utils"
+)
+
+// buildIngressSpec builds the Ingress spec routing all paths of the host to the JWKS Service
+func buildIngressSpec(jwksObj *v1alpha1.JWKS, spec *v1alpha1.IngressExposeSpec) networkingv1.IngressSpec {
+	pathType := networkingv1.PathTypePrefix
+	ingressSpec := networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{
+			{
+				Host: spec.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							{
+								Path:     "/",
+								PathType: &pathType,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: jwksObj.Name,
+										Port: networkingv1.ServiceBackendPort{
+											Number: nginx.ServicePort(jwksObj.Spec.Service),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if spec.ClassName != "" {
+		className := spec.ClassName
+		ingressSpec.IngressClassName = &className
+	}
+
+	if spec.TLSSecretName != "" {
+		ingressSpec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{spec.Host},
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+
+	return ingressSpec
+}
+
+// ensureIngress creates or updates the Ingress for the JWKS Service
+func (m *Manager) ensureIngress(ctx context.Context, jwksObj *v1alpha1.JWKS, spec *v1alpha1.IngressExposeSpec) error {
+	desiredSpec := buildIngressSpec(jwksObj, spec)
+
+	ingress := &networkingv1.Ingress{}
+	key := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Name}
+
+	err := m.client.Get(ctx, key, ingress)
+	if apierrors.IsNotFound(err) {
+		ingress = &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      jwksObj.Name,
+				Namespace: jwksObj.Namespace,
+				Labels:    labels(jwksObj),
+			},
+			Spec: desiredSpec,
+		}
+		utils.ApplyManagedAnnotations(ingress, spec.Annotations)
+		if _, err := utils.SetControllerReference(jwksObj, ingress, m.client.Scheme()); err != nil {
+			return err
+		}
+		return m.client.Create(ctx, ingress)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Ingress: %w", err)
+	}
+
+	if !isManaged(ingress, jwksObj) {
+		return fmt.Errorf("Ingress %s: %w", jwksObj.Name, ErrNotManaged)
+	}
+
+	changed := utils.ApplyManagedAnnotations(ingress, spec.Annotations)
+	if !equality.Semantic.DeepEqual(ingress.Spec, desiredSpec) {
+		ingress.Spec = desiredSpec
+		changed = true
+	}
+	ownerChanged, err := utils.SetControllerReference(jwksObj, ingress, m.client.Scheme())
+	if err != nil {
+		return err
+	}
+
+	if !changed && !ownerChanged {
+		return nil
+	}
+	return m.client.Update(ctx, ingress)
+}
+
+// deleteIngress deletes the Ingress created for JWKS, unmanaged objects are kept
+func (m *Manager) deleteIngress(ctx context.Context, jwksObj *v1alpha1.JWKS) error {
+	ingress := &networkingv1.Ingress{}
+	key := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Name}
+
+	if err := m.client.Get(ctx, key, ingress); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Ingress: %w", err)
+	}
+
+	if !isManaged(ingress, jwksObj) {
+		return nil
+	}
+
+	if err := m.client.Delete(ctx, ingress); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package expose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// ErrNotManaged is returned if an object with the same name exists and is not managed by the operator
+var ErrNotManaged = errors.New("object exists and is not managed by the operator")
+
+// Manager creates Ingress and HTTPRoute resources exposing the JWKS Service
+type Manager struct {
+	client client.Client
+}
+
+// NewManager creates a new expose manager
+func NewManager(client client.Client) *Manager {
+	return &Manager{
+		client: client,
+	}
+}
+
+// Sync creates or updates resources from spec.expose and deletes resources removed from the spec
+// jwksPath is the JWKS path served by nginx, used to build the public URL
+// Returns the public URL of the JWKS endpoint, empty if nothing is exposed
+func (m *Manager) Sync(ctx context.Context, jwksObj *v1alpha1.JWKS, jwksPath string) (string, error) {
+	var ingressSpec *v1alpha1.IngressExposeSpec
+	var routeSpec *v1alpha1.HTTPRouteExposeSpec
+	if jwksObj.Spec.Expose != nil {
+		ingressSpec = jwksObj.Spec.Expose.Ingress
+		routeSpec = jwksObj.Spec.Expose.HTTPRoute
+	}
+
+	if ingressSpec != nil {
+		if err := m.ensureIngress(ctx, jwksObj, ingressSpec); err != nil {
+			return "", fmt.Errorf("failed to ensure Ingress: %w", err)
+		}
+	} else if err := m.deleteIngress(ctx, jwksObj); err != nil {
+		return "", fmt.Errorf("failed to delete Ingress: %w", err)
+	}
+
+	if routeSpec != nil {
+		if err := m.ensureHTTPRoute(ctx, jwksObj, routeSpec); err != nil {
+			return "", fmt.Errorf("failed to ensure HTTPRoute: %w", err)
+		}
+	} else if err := m.deleteHTTPRoute(ctx, jwksObj); err != nil {
+		return "", fmt.Errorf("failed to delete HTTPRoute: %w", err)
+	}
+
+	return PublicURL(jwksObj.Spec.Expose, jwksPath), nil
+}
+
+// PublicURL returns the public URL of the JWKS endpoint, Ingress takes precedence over HTTPRoute
+func PublicURL(spec *v1alpha1.ExposeSpec, jwksPath string) string {
+	if spec == nil {
+		return ""
+	}
+
+	if spec.Ingress != nil {
+		scheme := config.ExposeSchemeHTTP
+		if spec.Ingress.TLSSecretName != "" {
+			scheme = config.ExposeSchemeHTTPS
+		}
+		return scheme + "://" + spec.Ingress.Host + jwksPath
+	}
+
+	if spec.HTTPRoute != nil && len(spec.HTTPRoute.Hostnames) > 0 {
+		scheme := spec.HTTPRoute.Scheme
+		if scheme == "" {
+			scheme = config.ExposeSchemeHTTPS
+		}
+		return scheme + "://" + spec.HTTPRoute.Hostnames[0] + jwksPath
+	}
+
+	return ""
+}
+
+// labels returns labels of resources exposing the JWKS Service
+func labels(jwksObj *v1alpha1.JWKS) map[string]string {
+	return map[string]string{
+		config.LabelJWKSConfig: jwksObj.Name,
+		config.LabelManagedBy:  config.LabelManagedByValue,
+	}
+}
+
+// isManaged checks if the object carries the operator labels for this JWKS
+func isManaged(obj client.Object, jwksObj *v1alpha1.JWKS) bool {
+	objLabels := obj.GetLabels()
+	return objLabels[config.LabelManagedBy] == config.LabelManagedByValue &&
+		objLabels[config.LabelJWKSConfig] == jwksObj.Name
+}
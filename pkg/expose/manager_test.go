@@ -0,0 +1,178 @@
+package expose
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// newExposedJWKS returns a JWKS resource with the given expose and service settings
+func newExposedJWKS(expose *v1alpha1.ExposeSpec, service *v1alpha1.ServiceSpec) *v1alpha1.JWKS {
+	return &v1alpha1.JWKS{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+		Spec:       v1alpha1.JWKSSpec{Expose: expose, Service: service},
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     *v1alpha1.ExposeSpec
+		jwksPath string
+		want     string
+	}{
+		{
+			name: "not exposed",
+			spec: nil,
+			want: "",
+		},
+		{
+			name: "ingress without TLS",
+			spec: &v1alpha1.ExposeSpec{Ingress: &v1alpha1.IngressExposeSpec{Host: "jwks.example.com"}},
+			want: "http://jwks.example.com" + config.JWKSEndpointPath,
+		},
+		{
+			name: "ingress with TLS takes precedence",
+			spec: &v1alpha1.ExposeSpec{
+				Ingress:   &v1alpha1.IngressExposeSpec{Host: "jwks.example.com", TLSSecretName: "tls"},
+				HTTPRoute: &v1alpha1.HTTPRouteExposeSpec{Hostnames: []string{"route.example.com"}},
+			},
+			want: "https://jwks.example.com" + config.JWKSEndpointPath,
+		},
+		{
+			name: "HTTPRoute defaults to https",
+			spec: &v1alpha1.ExposeSpec{HTTPRoute: &v1alpha1.HTTPRouteExposeSpec{Hostnames: []string{"route.example.com"}}},
+			want: "https://route.example.com" + config.JWKSEndpointPath,
+		},
+		{
+			name: "HTTPRoute with scheme",
+			spec: &v1alpha1.ExposeSpec{HTTPRoute: &v1alpha1.HTTPRouteExposeSpec{
+				Hostnames: []string{"route.example.com"}, Scheme: config.ExposeSchemeHTTP,
+			}},
+			want: "http://route.example.com" + config.JWKSEndpointPath,
+		},
+		{
+			name:     "served JWKS path",
+			spec:     &v1alpha1.ExposeSpec{Ingress: &v1alpha1.IngressExposeSpec{Host: "jwks.example.com"}},
+			jwksPath: "/auth/.well-known/jwks.json",
+			want:     "http://jwks.example.com/auth/.well-known/jwks.json",
+		},
+		{
+			name: "HTTPRoute without hostnames",
+			spec: &v1alpha1.ExposeSpec{HTTPRoute: &v1alpha1.HTTPRouteExposeSpec{}},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwksPath := tt.jwksPath
+			if jwksPath == "" {
+				jwksPath = config.JWKSEndpointPath
+			}
+			if got := PublicURL(tt.spec, jwksPath); got != tt.want {
+				t.Errorf("PublicURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildIngressSpec(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      *v1alpha1.IngressExposeSpec
+		service   *v1alpha1.ServiceSpec
+		wantPort  int32
+		wantClass string
+		wantTLS   bool
+	}{
+		{
+			name:     "default Service port",
+			spec:     &v1alpha1.IngressExposeSpec{Host: "jwks.example.com"},
+			wantPort: config.DefaultServicePort,
+		},
+		{
+			name:      "class, TLS and custom Service port",
+			spec:      &v1alpha1.IngressExposeSpec{Host: "jwks.example.com", ClassName: "nginx", TLSSecretName: "tls"},
+			service:   &v1alpha1.ServiceSpec{Port: 8080},
+			wantPort:  8080,
+			wantClass: "nginx",
+			wantTLS:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwksObj := newExposedJWKS(&v1alpha1.ExposeSpec{Ingress: tt.spec}, tt.service)
+			spec := buildIngressSpec(jwksObj, tt.spec)
+
+			if len(spec.Rules) != 1 || spec.Rules[0].Host != tt.spec.Host || len(spec.Rules[0].HTTP.Paths) != 1 {
+				t.Fatalf("rules = %+v, want one rule for %s", spec.Rules, tt.spec.Host)
+			}
+			backend := spec.Rules[0].HTTP.Paths[0].Backend.Service
+			if backend.Name != jwksObj.Name || backend.Port.Number != tt.wantPort {
+				t.Errorf("backend = %s:%d, want %s:%d", backend.Name, backend.Port.Number, jwksObj.Name, tt.wantPort)
+			}
+			className := ""
+			if spec.IngressClassName != nil {
+				className = *spec.IngressClassName
+			}
+			if className != tt.wantClass {
+				t.Errorf("ingressClassName = %q, want %q", className, tt.wantClass)
+			}
+			if (len(spec.TLS) == 1) != tt.wantTLS {
+				t.Errorf("tls = %+v, want TLS %v", spec.TLS, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRouteSpec(t *testing.T) {
+	spec := &v1alpha1.HTTPRouteExposeSpec{
+		ParentRefs: []v1alpha1.ParentReference{{Name: "public", Namespace: "gateways", SectionName: "https"}},
+		Hostnames:  []string{"route.example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		service  *v1alpha1.ServiceSpec
+		wantPort int64
+	}{
+		{name: "default Service port", wantPort: int64(config.DefaultServicePort)},
+		{name: "custom Service port", service: &v1alpha1.ServiceSpec{Port: 8080}, wantPort: 8080},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeSpec := buildHTTPRouteSpec(newExposedJWKS(nil, tt.service), spec)
+
+			parentRefs := routeSpec["parentRefs"].([]interface{})
+			parentRef := parentRefs[0].(map[string]interface{})
+			if len(parentRefs) != 1 || parentRef["name"] != "public" || parentRef["namespace"] != "gateways" ||
+				parentRef["sectionName"] != "https" {
+				t.Errorf("parentRefs = %v", parentRefs)
+			}
+			if _, ok := parentRef["group"]; ok {
+				t.Errorf("empty group must be omitted, got %v", parentRef)
+			}
+
+			rule := routeSpec["rules"].([]interface{})[0].(map[string]interface{})
+			backend := rule["backendRefs"].([]interface{})[0].(map[string]interface{})
+			if backend["name"] != "auth" || backend["port"] != tt.wantPort {
+				t.Errorf("backendRef = %v, want auth:%d", backend, tt.wantPort)
+			}
+
+			hash, err := specHash(routeSpec)
+			if err != nil {
+				t.Fatalf("specHash() unexpected error: %v", err)
+			}
+			again, _ := specHash(buildHTTPRouteSpec(newExposedJWKS(nil, tt.service), spec))
+			if hash != again {
+				t.Error("specHash() is not stable for the same spec")
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package jwks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Digest returns lowercase hex SHA-256 of the given content
+func Digest(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// KeyFingerprint returns a digest of the JWK content
+// Two keys with the same kid but different material have different fingerprints
+func KeyFingerprint(jwk *JWK) (string, error) {
+	if jwk == nil {
+		return "", fmt.Errorf("JWK is nil")
+	}
+
+	data, err := json.Marshal(jwk)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWK: %w", err)
+	}
+
+	return Digest(data), nil
+}
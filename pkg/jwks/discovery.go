@@ -0,0 +1,123 @@
+package jwks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Default values of required OpenID Connect discovery metadata
+var (
+	// DefaultResponseTypesSupported is used when response types are not configured
+	DefaultResponseTypesSupported = []string{"code"}
+	// DefaultSubjectTypesSupported is used when subject types are not configured
+	DefaultSubjectTypesSupported = []string{"public"}
+	// DefaultSigningAlg is used when served keys don't declare an algorithm
+	DefaultSigningAlg = "RS256"
+)
+
+// DiscoveryDocument represents an OpenID Connect discovery document
+type DiscoveryDocument struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                    string   `json:"token_endpoint,omitempty"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                  []string `json:"scopes_supported,omitempty"`
+	ClaimsSupported                  []string `json:"claims_supported,omitempty"`
+}
+
+// DefaultJWKSURI returns the JWKS URL served under the issuer
+func DefaultJWKSURI(issuer, jwksPath string) string {
+	return strings.TrimSuffix(issuer, "/") + jwksPath
+}
+
+// BuildDiscovery fills required metadata of the discovery document that was not set explicitly
+// Signing algorithms default to the algorithms of the served keys
+func BuildDiscovery(doc DiscoveryDocument, keySet *JWKS) (*DiscoveryDocument, error) {
+	if doc.Issuer == "" {
+		return nil, fmt.Errorf("issuer cannot be empty")
+	}
+	if doc.JWKSURI == "" {
+		return nil, fmt.Errorf("jwks_uri cannot be empty")
+	}
+
+	if len(doc.ResponseTypesSupported) == 0 {
+		doc.ResponseTypesSupported = DefaultResponseTypesSupported
+	}
+	if len(doc.SubjectTypesSupported) == 0 {
+		doc.SubjectTypesSupported = DefaultSubjectTypesSupported
+	}
+	if len(doc.IDTokenSigningAlgValuesSupported) == 0 {
+		doc.IDTokenSigningAlgValuesSupported = signingAlgs(keySet)
+	}
+
+	return &doc, nil
+}
+
+// ValidateDiscovery checks that a discovery document is consistent with the expected issuer,
+// JWKS URL and served key set
+func ValidateDiscovery(doc *DiscoveryDocument, issuer, jwksURI string, keySet *JWKS) error {
+	if doc == nil {
+		return fmt.Errorf("discovery document is nil")
+	}
+	if doc.Issuer != issuer {
+		return fmt.Errorf("issuer mismatch: expected %q, got %q", issuer, doc.Issuer)
+	}
+	if doc.JWKSURI != jwksURI {
+		return fmt.Errorf("jwks_uri mismatch: expected %q, got %q", jwksURI, doc.JWKSURI)
+	}
+	if len(doc.ResponseTypesSupported) == 0 {
+		return fmt.Errorf("response_types_supported cannot be empty")
+	}
+	if len(doc.SubjectTypesSupported) == 0 {
+		return fmt.Errorf("subject_types_supported cannot be empty")
+	}
+
+	supported := make(map[string]bool, len(doc.IDTokenSigningAlgValuesSupported))
+	for _, alg := range doc.IDTokenSigningAlgValuesSupported {
+		supported[alg] = true
+	}
+	if keySet != nil {
+		for _, key := range keySet.Keys {
+			if key.Alg != "" && !supported[key.Alg] {
+				return fmt.Errorf("key %s uses algorithm %s not listed in id_token_signing_alg_values_supported", key.Kid, key.Alg)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DiscoveryToJSON converts a discovery document to JSON
+func DiscoveryToJSON(doc *DiscoveryDocument) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal discovery document: %w", err)
+	}
+	return data, nil
+}
+
+// signingAlgs returns sorted unique algorithms of the key set
+func signingAlgs(keySet *JWKS) []string {
+	seen := make(map[string]bool)
+	algs := make([]string, 0)
+	if keySet != nil {
+		for _, key := range keySet.Keys {
+			if key.Alg != "" && !seen[key.Alg] {
+				seen[key.Alg] = true
+				algs = append(algs, key.Alg)
+			}
+		}
+	}
+
+	if len(algs) == 0 {
+		return []string{DefaultSigningAlg}
+	}
+	sort.Strings(algs)
+	return algs
+}
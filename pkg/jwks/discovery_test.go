@@ -0,0 +1,149 @@
+package jwks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+func TestBuildDiscovery(t *testing.T) {
+	keySet := &JWKS{Keys: []JWK{{Kid: "a", Alg: "RS512"}, {Kid: "b", Alg: "RS256"}, {Kid: "c", Alg: "RS512"}}}
+
+	tests := []struct {
+		name     string
+		doc      DiscoveryDocument
+		keySet   *JWKS
+		wantAlgs []string
+		wantErr  string
+	}{
+		{
+			name:     "algorithms of served keys",
+			doc:      DiscoveryDocument{Issuer: testIssuer, JWKSURI: testIssuer + "/jwks.json"},
+			keySet:   keySet,
+			wantAlgs: []string{"RS256", "RS512"},
+		},
+		{
+			name:     "keys without algorithm",
+			doc:      DiscoveryDocument{Issuer: testIssuer, JWKSURI: testIssuer + "/jwks.json"},
+			keySet:   &JWKS{Keys: []JWK{{Kid: "a"}}},
+			wantAlgs: []string{DefaultSigningAlg},
+		},
+		{
+			name: "explicit algorithms",
+			doc: DiscoveryDocument{Issuer: testIssuer, JWKSURI: testIssuer + "/jwks.json",
+				IDTokenSigningAlgValuesSupported: []string{"ES256"}},
+			keySet:   keySet,
+			wantAlgs: []string{"ES256"},
+		},
+		{
+			name:    "missing issuer",
+			doc:     DiscoveryDocument{JWKSURI: testIssuer + "/jwks.json"},
+			wantErr: "issuer cannot be empty",
+		},
+		{
+			name:    "missing jwks_uri",
+			doc:     DiscoveryDocument{Issuer: testIssuer},
+			wantErr: "jwks_uri cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := BuildDiscovery(tt.doc, tt.keySet)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BuildDiscovery() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildDiscovery() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(doc.IDTokenSigningAlgValuesSupported, tt.wantAlgs) {
+				t.Errorf("id_token_signing_alg_values_supported = %v, want %v", doc.IDTokenSigningAlgValuesSupported, tt.wantAlgs)
+			}
+			if !reflect.DeepEqual(doc.ResponseTypesSupported, DefaultResponseTypesSupported) {
+				t.Errorf("response_types_supported = %v, want %v", doc.ResponseTypesSupported, DefaultResponseTypesSupported)
+			}
+			if !reflect.DeepEqual(doc.SubjectTypesSupported, DefaultSubjectTypesSupported) {
+				t.Errorf("subject_types_supported = %v, want %v", doc.SubjectTypesSupported, DefaultSubjectTypesSupported)
+			}
+			if err := ValidateDiscovery(doc, tt.doc.Issuer, tt.doc.JWKSURI, nil); err != nil {
+				t.Errorf("ValidateDiscovery() of a built document: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDiscovery(t *testing.T) {
+	jwksURI := DefaultJWKSURI(testIssuer+"/", "/jwks.json")
+	valid := func() *DiscoveryDocument {
+		return &DiscoveryDocument{
+			Issuer:                           testIssuer,
+			JWKSURI:                          jwksURI,
+			ResponseTypesSupported:           []string{"code"},
+			SubjectTypesSupported:            []string{"public"},
+			IDTokenSigningAlgValuesSupported: []string{"RS512"},
+		}
+	}
+	keySet := &JWKS{Keys: []JWK{{Kid: "a", Alg: "RS512"}}}
+
+	tests := []struct {
+		name    string
+		modify  func(doc *DiscoveryDocument)
+		keySet  *JWKS
+		wantErr string
+	}{
+		{
+			name:   "valid document",
+			modify: func(*DiscoveryDocument) {},
+			keySet: keySet,
+		},
+		{
+			name:    "other issuer",
+			modify:  func(doc *DiscoveryDocument) { doc.Issuer = "https://other.example.com" },
+			wantErr: "issuer mismatch",
+		},
+		{
+			name:    "other jwks_uri",
+			modify:  func(doc *DiscoveryDocument) { doc.JWKSURI = testIssuer + "/keys" },
+			wantErr: "jwks_uri mismatch",
+		},
+		{
+			name:    "empty response types",
+			modify:  func(doc *DiscoveryDocument) { doc.ResponseTypesSupported = nil },
+			wantErr: "response_types_supported cannot be empty",
+		},
+		{
+			name:    "empty subject types",
+			modify:  func(doc *DiscoveryDocument) { doc.SubjectTypesSupported = nil },
+			wantErr: "subject_types_supported cannot be empty",
+		},
+		{
+			name:    "key algorithm not listed",
+			modify:  func(doc *DiscoveryDocument) { doc.IDTokenSigningAlgValuesSupported = []string{"RS256"} },
+			keySet:  keySet,
+			wantErr: "not listed in id_token_signing_alg_values_supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := valid()
+			tt.modify(doc)
+
+			err := ValidateDiscovery(doc, testIssuer, jwksURI, tt.keySet)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateDiscovery() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateDiscovery() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package jwks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+)
+
+// Output format names
+const (
+	// FormatJWKS renders the key set as JSON Web Key Set
+	FormatJWKS = "jwks"
+	// FormatPEMBundle renders all public keys as a single PEM bundle
+	FormatPEMBundle = "pem-bundle"
+	// FormatPerKidPEM renders every public key into a separate <kid>.pem file
+	FormatPerKidPEM = "per-kid-pem"
+	// FormatSPKIPins renders SHA-256 pins of SubjectPublicKeyInfo for every key
+	FormatSPKIPins = "spki-pins"
+)
+
+// Format describes an output format of a key set
+type Format struct {
+	// Name is the format name used in spec.outputs[].format
+	Name string
+	// DefaultKey is the file name used when output key is not set
+	// Empty for formats that choose file names themselves
+	DefaultKey string
+	// Render renders the key set into files (file name -> content)
+	// key is the requested file name, formats with their own naming may ignore it
+	Render func(keySet *JWKS, key string) (map[string][]byte, error)
+}
+
+// formats is the registry of supported output formats
+var formats = map[string]Format{}
+
+func init() {
+	RegisterFormat(Format{Name: FormatJWKS, DefaultKey: "jwks.json", Render: renderJWKS})
+	RegisterFormat(Format{Name: FormatPEMBundle, DefaultKey: "keys.pem", Render: renderPEMBundle})
+	RegisterFormat(Format{Name: FormatPerKidPEM, Render: renderPerKidPEM})
+	RegisterFormat(Format{Name: FormatSPKIPins, DefaultKey: "pins.json", Render: renderSPKIPins})
+}
+
+// RegisterFormat adds a format to the registry, replacing a format with the same name
+func RegisterFormat(format Format) {
+	formats[format.Name] = format
+}
+
+// LookupFormat returns a registered format by name
+func LookupFormat(name string) (Format, bool) {
+	format, ok := formats[name]
+	return format, ok
+}
+
+// SupportedFormats returns sorted names of all registered formats
+func SupportedFormats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders the key set in the named format
+func Render(formatName string, keySet *JWKS, key string) (map[string][]byte, error) {
+	if keySet == nil {
+		return nil, fmt.Errorf("JWKS is nil")
+	}
+
+	format, ok := LookupFormat(formatName)
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format: %s", formatName)
+	}
+
+	if key == "" {
+		key = format.DefaultKey
+	}
+
+	return format.Render(keySet, key)
+}
+
+// renderJWKS renders the key set as JWKS JSON
+func renderJWKS(keySet *JWKS, key string) (map[string][]byte, error) {
+	data, err := ToJSON(keySet)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{key: data}, nil
+}
+
+// renderPEMBundle renders all public keys into one PEM file
+func renderPEMBundle(keySet *JWKS, key string) (map[string][]byte, error) {
+	var buf bytes.Buffer
+	for i := range keySet.Keys {
+		block, err := publicKeyPEM(&keySet.Keys[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(block)
+	}
+	return map[string][]byte{key: buf.Bytes()}, nil
+}
+
+// renderPerKidPEM renders every public key into a separate <kid>.pem file
+func renderPerKidPEM(keySet *JWKS, _ string) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(keySet.Keys))
+	for i := range keySet.Keys {
+		jwk := &keySet.Keys[i]
+		if jwk.Kid == "" {
+			return nil, fmt.Errorf("key %d has no kid", i)
+		}
+		block, err := publicKeyPEM(jwk)
+		if err != nil {
+			return nil, err
+		}
+		files[jwk.Kid+".pem"] = block
+	}
+	return files, nil
+}
+
+// SPKIPin is a SHA-256 pin of a key's SubjectPublicKeyInfo
+type SPKIPin struct {
+	Kid    string `json:"kid"`
+	SHA256 string `json:"sha256"`
+}
+
+// renderSPKIPins renders base64 SHA-256 SPKI pins as JSON
+func renderSPKIPins(keySet *JWKS, key string) (map[string][]byte, error) {
+	pins := struct {
+		Pins []SPKIPin `json:"pins"`
+	}{
+		Pins: make([]SPKIPin, 0, len(keySet.Keys)),
+	}
+
+	for i := range keySet.Keys {
+		der, err := publicKeyDER(&keySet.Keys[i])
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(der)
+		pins.Pins = append(pins.Pins, SPKIPin{
+			Kid:    keySet.Keys[i].Kid,
+			SHA256: base64.StdEncoding.EncodeToString(hash[:]),
+		})
+	}
+
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SPKI pins: %w", err)
+	}
+	return map[string][]byte{key: data}, nil
+}
+
+// publicKeyDER returns PKIX (SubjectPublicKeyInfo) DER encoding of the JWK public key
+func publicKeyDER(jwk *JWK) ([]byte, error) {
+	publicKey, err := PublicKeyFromJWK(jwk)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", jwk.Kid, err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: failed to marshal public key: %w", jwk.Kid, err)
+	}
+	return der, nil
+}
+
+// publicKeyPEM returns the JWK public key as a PEM "PUBLIC KEY" block
+func publicKeyPEM(jwk *JWK) ([]byte, error) {
+	der, err := publicKeyDER(jwk)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
@@ -0,0 +1,177 @@
+package jwks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"sort"
+	"testing"
+)
+
+// newTestKeySet returns a key set with an RSA key for every kid
+func newTestKeySet(t *testing.T, kids ...string) (*JWKS, map[string]*rsa.PublicKey) {
+	t.Helper()
+
+	keySet := &JWKS{}
+	publicKeys := make(map[string]*rsa.PublicKey, len(kids))
+	for _, kid := range kids {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate RSA key: %v", err)
+		}
+		jwk, err := FormatRSAKey(&privateKey.PublicKey, kid, nil)
+		if err != nil {
+			t.Fatalf("FormatRSAKey() unexpected error: %v", err)
+		}
+		keySet.Keys = append(keySet.Keys, *jwk)
+		publicKeys[kid] = &privateKey.PublicKey
+	}
+	return keySet, publicKeys
+}
+
+func TestRender(t *testing.T) {
+	keySet, publicKeys := newTestKeySet(t, "key-1", "key-2")
+
+	tests := []struct {
+		name      string
+		format    string
+		key       string
+		wantFiles []string
+		wantPEMs  map[string][]string
+	}{
+		{
+			name:      "jwks default key",
+			format:    FormatJWKS,
+			wantFiles: []string{"jwks.json"},
+		},
+		{
+			name:      "jwks custom key",
+			format:    FormatJWKS,
+			key:       "keys.json",
+			wantFiles: []string{"keys.json"},
+		},
+		{
+			name:      "pem bundle",
+			format:    FormatPEMBundle,
+			wantFiles: []string{"keys.pem"},
+			wantPEMs:  map[string][]string{"keys.pem": {"key-1", "key-2"}},
+		},
+		{
+			name:      "per-kid pem ignores key",
+			format:    FormatPerKidPEM,
+			key:       "keys.pem",
+			wantFiles: []string{"key-1.pem", "key-2.pem"},
+			wantPEMs:  map[string][]string{"key-1.pem": {"key-1"}, "key-2.pem": {"key-2"}},
+		},
+		{
+			name:      "spki pins",
+			format:    FormatSPKIPins,
+			wantFiles: []string{"pins.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Render(tt.format, keySet, tt.key)
+			if err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(files))
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if len(names) != len(tt.wantFiles) {
+				t.Fatalf("Render() files = %v, want %v", names, tt.wantFiles)
+			}
+			for i := range names {
+				if names[i] != tt.wantFiles[i] {
+					t.Fatalf("Render() files = %v, want %v", names, tt.wantFiles)
+				}
+			}
+
+			for file, kids := range tt.wantPEMs {
+				assertPEMKeys(t, files[file], kids, publicKeys)
+			}
+			if tt.format == FormatSPKIPins {
+				assertSPKIPins(t, files["pins.json"], publicKeys)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	keySet, _ := newTestKeySet(t, "")
+
+	tests := []struct {
+		name   string
+		format string
+		keySet *JWKS
+	}{
+		{name: "unsupported format", format: "der", keySet: keySet},
+		{name: "nil key set", format: FormatJWKS, keySet: nil},
+		{name: "per-kid pem without kid", format: FormatPerKidPEM, keySet: keySet},
+		{name: "unsupported key type", format: FormatPEMBundle, keySet: &JWKS{Keys: []JWK{{Kty: "EC", Kid: "ec"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Render(tt.format, tt.keySet, ""); err == nil {
+				t.Fatal("Render() expected error, got nil")
+			}
+		})
+	}
+}
+
+// assertPEMKeys checks that a PEM file contains the public keys of the given kids in order
+func assertPEMKeys(t *testing.T, data []byte, kids []string, publicKeys map[string]*rsa.PublicKey) {
+	t.Helper()
+
+	for _, kid := range kids {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil || block.Type != "PUBLIC KEY" {
+			t.Fatalf("missing PUBLIC KEY block for %s", kid)
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Fatalf("failed to parse public key of %s: %v", kid, err)
+		}
+		if !publicKeys[kid].Equal(parsed) {
+			t.Errorf("PEM block does not match the public key of %s", kid)
+		}
+	}
+	if len(data) != 0 {
+		t.Errorf("unexpected data after %d PEM blocks", len(kids))
+	}
+}
+
+// assertSPKIPins checks SHA-256 pins against the SubjectPublicKeyInfo of every key
+func assertSPKIPins(t *testing.T, data []byte, publicKeys map[string]*rsa.PublicKey) {
+	t.Helper()
+
+	var pins struct {
+		Pins []SPKIPin `json:"pins"`
+	}
+	if err := json.Unmarshal(data, &pins); err != nil {
+		t.Fatalf("failed to unmarshal pins: %v", err)
+	}
+	if len(pins.Pins) != len(publicKeys) {
+		t.Fatalf("got %d pins, want %d", len(pins.Pins), len(publicKeys))
+	}
+	for _, pin := range pins.Pins {
+		der, err := x509.MarshalPKIXPublicKey(publicKeys[pin.Kid])
+		if err != nil {
+			t.Fatalf("failed to marshal public key of %s: %v", pin.Kid, err)
+		}
+		hash := sha256.Sum256(der)
+		if want := base64.StdEncoding.EncodeToString(hash[:]); pin.SHA256 != want {
+			t.Errorf("pin of %s = %q, want %q", pin.Kid, pin.SHA256, want)
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"crypto/rsa"
 	"crypto/sha1" //nolint:gosec // SHA-1 is required for certificate fingerprint per RFC standards
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math"
+	"math/big"
 )
 
 // ExtractPublicKey extracts the public key from a certificate
@@ -57,3 +60,33 @@ func certFingerprint(cert *x509.Certificate) string {
 	hash := sha1.Sum(cert.Raw) //nolint:gosec // SHA-1 is required for certificate fingerprint
 	return hex.EncodeToString(hash[:])
 }
+
+// PublicKeyFromJWK reconstructs an RSA public key from JWK modulus and exponent
+func PublicKeyFromJWK(jwk *JWK) (*rsa.PublicKey, error) {
+	if jwk == nil {
+		return nil, fmt.Errorf("JWK is nil")
+	}
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+
+	nBytes, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus: %w", err)
+	}
+
+	eBytes, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent: %w", err)
+	}
+
+	e := new(big.Int).SetBytes(eBytes)
+	if !e.IsInt64() || e.Int64() <= 0 || e.Int64() > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(e.Int64()),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package jwksserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+const (
+	// combinedTimeLayout is the $time_local layout of nginx
+	combinedTimeLayout = "02/Jan/2006:15:04:05 -0700"
+	// isoTimeLayout is the $time_iso8601 layout of nginx, UTC is written as +00:00
+	isoTimeLayout = "2006-01-02T15:04:05-07:00"
+)
+
+// accessRecord is a JSON access log record, the fields match the json format of the nginx config
+type accessRecord struct {
+	Time          string      `json:"time"`
+	JWKS          string      `json:"jwks"`
+	Namespace     string      `json:"namespace"`
+	RemoteAddr    string      `json:"remote_addr"`
+	RequestMethod string      `json:"request_method"`
+	RequestURI    string      `json:"request_uri"`
+	Status        int         `json:"status"`
+	BodyBytesSent int         `json:"body_bytes_sent"`
+	RequestTime   json.Number `json:"request_time"`
+	HTTPReferer   string      `json:"http_referer"`
+	HTTPUserAgent string      `json:"http_user_agent"`
+	ContentDigest string      `json:"content_digest"`
+	RequestID     string      `json:"request_id"`
+}
+
+// accessLogger writes access log records in the combined or json format of the nginx config
+type accessLogger struct {
+	mu  sync.Mutex
+	out io.Writer
+	// probes counts probe requests, every request whose count crosses a percent boundary is logged
+	probes atomic.Uint64
+}
+
+// newAccessLogger creates an access logger writing to out
+func newAccessLogger(out io.Writer) *accessLogger {
+	return &accessLogger{out: out}
+}
+
+// sampled checks if the request is logged, probe requests are logged evenly at the configured percentage
+func (l *accessLogger) sampled(cfg *AccessLogConfig, r *http.Request) bool {
+	if !strings.HasPrefix(r.UserAgent(), config.ProbeUserAgentPrefix) {
+		return true
+	}
+	n := l.probes.Add(1)
+	percent := uint64(cfg.ProbeSamplePercent)
+	return n*percent/100 != (n-1)*percent/100
+}
+
+// write logs the request if cfg enables the access log
+func (l *accessLogger) write(cfg *AccessLogConfig, r *http.Request, recorder *statusRecorder, start time.Time) {
+	if cfg == nil || !l.sampled(cfg, r) {
+		return
+	}
+
+	var line []byte
+	if cfg.Format == config.AccessLogFormatJSON {
+		record := accessRecord{
+			Time:          start.Format(isoTimeLayout),
+			JWKS:          cfg.Name,
+			Namespace:     cfg.Namespace,
+			RemoteAddr:    clientIP(r),
+			RequestMethod: r.Method,
+			RequestURI:    r.RequestURI,
+			Status:        recorder.status,
+			BodyBytesSent: recorder.size,
+			RequestTime:   json.Number(strconv.FormatFloat(time.Since(start).Seconds(), 'f', 3, 64)),
+			HTTPReferer:   r.Referer(),
+			HTTPUserAgent: r.UserAgent(),
+			ContentDigest: recorder.Header().Get(config.HeaderContentDigest),
+			RequestID:     requestID(),
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return
+		}
+		line = append(data, '\n')
+	} else {
+		line = []byte(fmt.Sprintf("%s - - [%s] \"%s\" %d %d \"%s\" \"%s\"\n",
+			clientIP(r), start.Format(combinedTimeLayout),
+			escapeLogValue(r.Method+" "+r.RequestURI+" "+r.Proto), recorder.status, recorder.size,
+			escapeLogValue(orDash(r.Referer())), escapeLogValue(orDash(r.UserAgent()))))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(line)
+}
+
+// requestID returns 16 random bytes in hex, like $request_id of nginx
+func requestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// orDash returns "-" for empty values, as nginx logs missing headers
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// escapeLogValue escapes quotes, backslashes and non-printable bytes as \xHH, as nginx does in combined records
+func escapeLogValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '"' || c == '\\' || c < 0x20 || c >= 0x7f {
+			fmt.Fprintf(&b, `\x%02X`, c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package jwksserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestAccessLoggerWrite(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		cfg       *AccessLogConfig
+		userAgent string
+		want      string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:      "combined",
+			cfg:       &AccessLogConfig{Format: config.AccessLogFormatCombined},
+			userAgent: `client "1"`,
+			want:      `192.0.2.1 - - [02/Jan/2024:03:04:05 +0000] "GET /jwks.json HTTP/1.1" 200 42 "-" "client \x221\x22"` + "\n",
+		},
+		{
+			name:      "probe not logged",
+			cfg:       &AccessLogConfig{Format: config.AccessLogFormatCombined},
+			userAgent: config.ProbeUserAgentPrefix + "1.29",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := newAccessLogger(&out)
+			req := httptest.NewRequest(http.MethodGet, "/jwks.json", nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			recorder := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK, size: 42}
+
+			logger.write(tt.cfg, req, recorder, start)
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("access log = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLoggerWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	logger := newAccessLogger(&out)
+	cfg := &AccessLogConfig{Format: config.AccessLogFormatJSON, Name: "auth", Namespace: "default"}
+
+	req := httptest.NewRequest(http.MethodGet, "/jwks.json", nil)
+	recorder := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusNotModified}
+	recorder.Header().Set(config.HeaderContentDigest, "abc123")
+
+	logger.write(cfg, req, recorder, time.Now())
+
+	var record accessRecord
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("access log record is not JSON: %v: %s", err, out.String())
+	}
+	if record.JWKS != "auth" || record.Namespace != "default" || record.Status != http.StatusNotModified ||
+		record.ContentDigest != "abc123" || len(record.RequestID) != 32 {
+		t.Errorf("record = %+v, want auth/default, status 304, digest abc123 and a request ID", record)
+	}
+}
+
+func TestAccessLoggerProbeSampling(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent int
+		probes  int
+		want    int
+	}{
+		{name: "not logged", percent: 0, probes: 100, want: 0},
+		{name: "ten percent", percent: 10, probes: 100, want: 10},
+		{name: "every probe", percent: 100, probes: 20, want: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := newAccessLogger(&out)
+			cfg := &AccessLogConfig{Format: config.AccessLogFormatCombined, ProbeSamplePercent: tt.percent}
+
+			for i := 0; i < tt.probes; i++ {
+				req := httptest.NewRequest(http.MethodGet, config.HealthCheckPath, nil)
+				req.Header.Set("User-Agent", config.ProbeUserAgentPrefix+"1.29")
+				logger.write(cfg, req, &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}, time.Now())
+			}
+
+			if got := strings.Count(out.String(), "\n"); got != tt.want {
+				t.Errorf("logged %d of %d probes, want %d", got, tt.probes, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package jwksserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// Config is the configuration of the built-in JWKS server
+// The operator generates it from the JWKS resource and stores it in the server ConfigMap
+type Config struct {
+	// JWKSPaths are exact paths serving jwks.json, the primary path first
+	JWKSPaths []string `json:"jwksPaths"`
+	// DiscoveryPath is the path of the OpenID Connect discovery document, empty if not served
+	DiscoveryPath string `json:"discoveryPath,omitempty"`
+	// CacheControl is the Cache-Control header of JWKS and discovery responses
+	CacheControl string `json:"cacheControl"`
+	// CORS is the resolved CORS policy
+	CORS CORSConfig `json:"cors"`
+	// RateLimit limits JWKS and discovery requests per client IP, nil if not limited
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+	// MaxBodySize is the largest accepted request body in bytes, 0 if not limited
+	MaxBodySize int64 `json:"maxBodySize,omitempty"`
+	// AccessLog writes access log records to stdout, nil if requests are not logged
+	AccessLog *AccessLogConfig `json:"accessLog,omitempty"`
+}
+
+// AccessLogConfig is the access log of the built-in server
+type AccessLogConfig struct {
+	// Format is combined or json, the operator does not generate the config for off
+	Format string `json:"format"`
+	// ProbeSamplePercent is the percentage of kubelet probe requests that are logged
+	ProbeSamplePercent int `json:"probeSamplePercent,omitempty"`
+	// Name and Namespace identify the JWKS in json records
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RateLimitConfig is the per client IP request rate of the built-in server
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained request rate of a client IP
+	RequestsPerSecond int `json:"requestsPerSecond"`
+	// Burst is the number of requests a client IP may send over the rate
+	Burst int `json:"burst,omitempty"`
+	// Status is the response status of rejected requests
+	Status int `json:"status"`
+}
+
+// CORSConfig is the CORS policy of the built-in server with defaults already applied
+type CORSConfig struct {
+	// Enabled is false if CORS headers are not sent
+	Enabled bool `json:"enabled"`
+	// AnyOrigin allows every origin with "*"
+	AnyOrigin bool `json:"anyOrigin,omitempty"`
+	// Origins are allowed by exact match
+	Origins []string `json:"origins,omitempty"`
+	// OriginPatterns are allowed by regular expression
+	OriginPatterns []string `json:"originPatterns,omitempty"`
+	// Methods are sent in Access-Control-Allow-Methods
+	Methods []string `json:"methods,omitempty"`
+	// Headers are sent in Access-Control-Allow-Headers
+	Headers []string `json:"headers,omitempty"`
+	// MaxAge is sent in Access-Control-Max-Age if set
+	MaxAge *int32 `json:"maxAge,omitempty"`
+	// Credentials sends Access-Control-Allow-Credentials
+	Credentials bool `json:"credentials,omitempty"`
+
+	patterns []*regexp.Regexp
+}
+
+// ParseConfig parses and validates the server configuration
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse server config: %w", err)
+	}
+
+	if len(cfg.JWKSPaths) == 0 {
+		return nil, fmt.Errorf("server config has no JWKS paths")
+	}
+	for _, path := range append(cfg.JWKSPaths, cfg.DiscoveryPath) {
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("path %q must start with '/'", path)
+		}
+	}
+
+	if limit := cfg.RateLimit; limit != nil {
+		if limit.RequestsPerSecond <= 0 || limit.Burst < 0 {
+			return nil, fmt.Errorf("invalid rate limit of %d requests per second with burst %d",
+				limit.RequestsPerSecond, limit.Burst)
+		}
+		if limit.Status < 400 || limit.Status > 599 {
+			return nil, fmt.Errorf("rate limit status %d must be between 400 and 599", limit.Status)
+		}
+	}
+
+	if accessLog := cfg.AccessLog; accessLog != nil {
+		if accessLog.Format != config.AccessLogFormatCombined && accessLog.Format != config.AccessLogFormatJSON {
+			return nil, fmt.Errorf("unknown access log format %q", accessLog.Format)
+		}
+		if accessLog.ProbeSamplePercent < 0 || accessLog.ProbeSamplePercent > 100 {
+			return nil, fmt.Errorf("probeSamplePercent %d must be between 0 and 100", accessLog.ProbeSamplePercent)
+		}
+	}
+
+	for _, pattern := range cfg.CORS.OriginPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CORS origin pattern %q: %w", pattern, err)
+		}
+		cfg.CORS.patterns = append(cfg.CORS.patterns, re)
+	}
+
+	return cfg, nil
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for the request origin
+// Returns an empty string if the origin is not allowed
+func (c *CORSConfig) allowOrigin(origin string) string {
+	if !c.Enabled {
+		return ""
+	}
+	if c.AnyOrigin {
+		return "*"
+	}
+	if origin == "" {
+		return ""
+	}
+
+	for _, allowed := range c.Origins {
+		if allowed == origin {
+			return origin
+		}
+	}
+	for _, re := range c.patterns {
+		if re.MatchString(origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,130 @@
+package jwksserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "minimal config",
+			data: `{"jwksPaths": ["/.well-known/jwks.json"], "cacheControl": "public, max-age=60"}`,
+		},
+		{
+			name: "full config",
+			data: `{
+				"jwksPaths": ["/.well-known/jwks.json", "/jwks.json"],
+				"discoveryPath": "/.well-known/openid-configuration",
+				"cacheControl": "no-cache",
+				"cors": {"enabled": true, "originPatterns": ["^https://.*\\.example\\.com$"]},
+				"rateLimit": {"requestsPerSecond": 10, "burst": 5, "status": 429},
+				"accessLog": {"format": "json", "probeSamplePercent": 10}
+			}`,
+		},
+		{
+			name:    "invalid JSON",
+			data:    `{`,
+			wantErr: "failed to parse server config",
+		},
+		{
+			name:    "no JWKS paths",
+			data:    `{"jwksPaths": []}`,
+			wantErr: "no JWKS paths",
+		},
+		{
+			name:    "relative JWKS path",
+			data:    `{"jwksPaths": ["jwks.json"]}`,
+			wantErr: "must start with '/'",
+		},
+		{
+			name:    "relative discovery path",
+			data:    `{"jwksPaths": ["/jwks.json"], "discoveryPath": "openid-configuration"}`,
+			wantErr: "must start with '/'",
+		},
+		{
+			name:    "zero rate",
+			data:    `{"jwksPaths": ["/jwks.json"], "rateLimit": {"requestsPerSecond": 0, "status": 429}}`,
+			wantErr: "invalid rate limit",
+		},
+		{
+			name:    "negative burst",
+			data:    `{"jwksPaths": ["/jwks.json"], "rateLimit": {"requestsPerSecond": 1, "burst": -1, "status": 429}}`,
+			wantErr: "invalid rate limit",
+		},
+		{
+			name:    "rate limit status out of range",
+			data:    `{"jwksPaths": ["/jwks.json"], "rateLimit": {"requestsPerSecond": 1, "status": 200}}`,
+			wantErr: "must be between 400 and 599",
+		},
+		{
+			name:    "unknown access log format",
+			data:    `{"jwksPaths": ["/jwks.json"], "accessLog": {"format": "off"}}`,
+			wantErr: "unknown access log format",
+		},
+		{
+			name:    "probe sample percent out of range",
+			data:    `{"jwksPaths": ["/jwks.json"], "accessLog": {"format": "combined", "probeSamplePercent": 101}}`,
+			wantErr: "must be between 0 and 100",
+		},
+		{
+			name:    "invalid CORS origin pattern",
+			data:    `{"jwksPaths": ["/jwks.json"], "cors": {"enabled": true, "originPatterns": ["("]}}`,
+			wantErr: "invalid CORS origin pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseConfig() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig() unexpected error: %v", err)
+			}
+			if len(cfg.CORS.patterns) != len(cfg.CORS.OriginPatterns) {
+				t.Errorf("compiled %d origin patterns, want %d", len(cfg.CORS.patterns), len(cfg.CORS.OriginPatterns))
+			}
+		})
+	}
+}
+
+func TestCORSConfigAllowOrigin(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"jwksPaths": ["/jwks.json"],
+		"cors": {"enabled": true, "origins": ["https://app.example.com"], "originPatterns": ["^https://[a-z]+\\.example\\.org$"]}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseConfig() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		cors   *CORSConfig
+		origin string
+		want   string
+	}{
+		{name: "exact origin", cors: &cfg.CORS, origin: "https://app.example.com", want: "https://app.example.com"},
+		{name: "pattern origin", cors: &cfg.CORS, origin: "https://auth.example.org", want: "https://auth.example.org"},
+		{name: "origin not allowed", cors: &cfg.CORS, origin: "https://evil.example.net", want: ""},
+		{name: "no origin", cors: &cfg.CORS, origin: "", want: ""},
+		{name: "any origin", cors: &CORSConfig{Enabled: true, AnyOrigin: true}, origin: "https://x.test", want: "*"},
+		{name: "disabled", cors: &CORSConfig{AnyOrigin: true}, origin: "https://x.test", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cors.allowOrigin(tt.origin); got != tt.want {
+				t.Errorf("allowOrigin(%q) = %q, want %q", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package jwksserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// gzipMinLength is the minimum body size compressed for clients accepting gzip, as in the nginx config
+const gzipMinLength = 256
+
+// document is a served JSON file with precomputed ETag and compressed body
+type document struct {
+	body       []byte
+	compressed []byte
+	etag       string
+	digest     string
+}
+
+// newDocument builds a document from the file content
+// The ETag is the content digest, so it is identical on every replica and matches the nginx server
+func newDocument(body []byte) (*document, error) {
+	digest := jwks.Digest(body)
+	doc := &document{
+		body:   body,
+		etag:   `"` + digest + `"`,
+		digest: digest,
+	}
+
+	if len(body) >= gzipMinLength {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(body); err != nil {
+			return nil, fmt.Errorf("failed to compress content: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress content: %w", err)
+		}
+		doc.compressed = buf.Bytes()
+	}
+
+	return doc, nil
+}
+
+// state is the configuration and content served at a time
+// It is replaced as a whole on reload, so a request never sees a mix of old and new files
+type state struct {
+	config    *Config
+	jwks      *document
+	discovery *document
+	keys      int
+	// limiter enforces config.RateLimit, it is kept across reloads that do not change the limit
+	limiter *rateLimiter
+
+	// raw file contents used to detect changes
+	rawConfig    []byte
+	rawJWKS      []byte
+	rawDiscovery []byte
+}
+
+// unchanged checks if the files read from disk match the served state
+func (s *state) unchanged(rawConfig, rawJWKS, rawDiscovery []byte) bool {
+	return s != nil &&
+		bytes.Equal(s.rawConfig, rawConfig) &&
+		bytes.Equal(s.rawJWKS, rawJWKS) &&
+		bytes.Equal(s.rawDiscovery, rawDiscovery)
+}
+
+// readOptionalFile reads a file, a missing file is returned as nil content
+func readOptionalFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// readFiles reads the server config and the served files
+func readFiles(configPath, dataDir string) (rawConfig, rawJWKS, rawDiscovery []byte, err error) {
+	rawConfig, err = os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read server config: %w", err)
+	}
+	rawJWKS, err = readOptionalFile(filepath.Join(dataDir, config.ConfigMapKeyJWKS))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	rawDiscovery, err = readOptionalFile(filepath.Join(dataDir, config.ConfigMapKeyDiscovery))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read discovery document: %w", err)
+	}
+	return rawConfig, rawJWKS, rawDiscovery, nil
+}
+
+// buildState parses the files into a new state
+// A missing jwks.json is served as 404, invalid JSON is rejected and the previous state is kept
+func buildState(rawConfig, rawJWKS, rawDiscovery []byte) (*state, error) {
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &state{
+		config:       cfg,
+		rawConfig:    rawConfig,
+		rawJWKS:      rawJWKS,
+		rawDiscovery: rawDiscovery,
+	}
+
+	if rawJWKS != nil {
+		var set jwks.JWKS
+		if err := json.Unmarshal(rawJWKS, &set); err != nil {
+			return nil, fmt.Errorf("invalid JWKS: %w", err)
+		}
+		next.keys = len(set.Keys)
+		if next.jwks, err = newDocument(rawJWKS); err != nil {
+			return nil, err
+		}
+	}
+
+	if rawDiscovery != nil && cfg.DiscoveryPath != "" {
+		if !json.Valid(rawDiscovery) {
+			return nil, fmt.Errorf("invalid discovery document")
+		}
+		if next.discovery, err = newDocument(rawDiscovery); err != nil {
+			return nil, err
+		}
+	}
+
+	return next, nil
+}
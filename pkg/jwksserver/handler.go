@@ -0,0 +1,202 @@
+package jwksserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// statusRecorder records the status code and body size of a response for metrics
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+// WriteHeader records the status code
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body size
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(data)
+	r.size += n
+	return n, err
+}
+
+// ServeHTTP serves the JWKS, the discovery document and the health check
+// Every other path returns 404, as in the nginx config
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	s.route(recorder, r)
+	s.accessLog.write(s.state.Load().config.AccessLog, r, recorder, start)
+
+	status := strconv.Itoa(recorder.status)
+	s.metrics.responses.WithLabelValues(r.Method, status).Inc()
+	s.metrics.responseSize.WithLabelValues(r.Method, status).Add(float64(recorder.size))
+	s.metrics.responseTime.WithLabelValues(r.Method, status).Observe(time.Since(start).Seconds())
+}
+
+// route dispatches the request by exact path
+// Methods and body size are checked for every path, as in the server block of the nginx config
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("X-XSS-Protection", "1; mode=block")
+
+	current := s.state.Load()
+	cfg := current.config
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		header.Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if cfg.MaxBodySize > 0 && r.ContentLength > cfg.MaxBodySize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if r.URL.Path == config.HealthCheckPath {
+		header.Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("ok\n"))
+		return
+	}
+
+	for _, path := range cfg.JWKSPaths {
+		if r.URL.Path == path {
+			if current.jwks != nil {
+				// Identifies the served key set in access logs, as in the nginx config
+				header.Set(config.HeaderContentDigest, current.jwks.digest)
+			}
+			serveDocument(w, r, current.jwks, cfg, current.limiter)
+			return
+		}
+	}
+	if cfg.DiscoveryPath != "" && r.URL.Path == cfg.DiscoveryPath {
+		serveDocument(w, r, current.discovery, cfg, current.limiter)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// setCORSHeaders sets CORS headers of the response, preflight responses also get allowed methods and headers
+func setCORSHeaders(header http.Header, cors *CORSConfig, origin string, preflight bool) {
+	if !cors.AnyOrigin {
+		header.Add("Vary", "Origin")
+	}
+
+	allowOrigin := cors.allowOrigin(origin)
+	if allowOrigin == "" {
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+
+	if preflight {
+		header.Set("Access-Control-Allow-Methods", strings.Join(cors.Methods, ", "))
+		header.Set("Access-Control-Allow-Headers", strings.Join(cors.Headers, ", "))
+		if cors.MaxAge != nil {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(*cors.MaxAge)))
+		}
+	}
+	if cors.Credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// acceptsGzip checks if the client accepts gzip-compressed responses
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.TrimSpace(name) == "gzip" && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified checks If-None-Match against the ETag with weak comparison
+func notModified(r *http.Request, etag string) bool {
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// serveDocument serves a JSON document with CORS, Cache-Control, ETag, gzip handling and the rate limit
+// The rate limit applies before CORS preflights and 304 responses, as limit_req does in nginx
+func serveDocument(w http.ResponseWriter, r *http.Request, doc *document, cfg *Config, limiter *rateLimiter) {
+	header := w.Header()
+	origin := r.Header.Get("Origin")
+
+	// Preflight and 304 responses are limited as well, as with limit_req in the nginx config
+	if !limiter.allow(clientIP(r), time.Now()) {
+		http.Error(w, http.StatusText(cfg.RateLimit.Status), cfg.RateLimit.Status)
+		return
+	}
+
+	if r.Method == http.MethodOptions && cfg.CORS.Enabled {
+		setCORSHeaders(header, &cfg.CORS, origin, true)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		header.Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if doc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if cfg.CORS.Enabled {
+		setCORSHeaders(header, &cfg.CORS, origin, false)
+	}
+	header.Set("Cache-Control", cfg.CacheControl)
+
+	body, etag := doc.body, doc.etag
+	if doc.compressed != nil {
+		header.Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			// Compressed responses get a weak ETag, as nginx does
+			body, etag = doc.compressed, "W/"+doc.etag
+			header.Set("Content-Encoding", "gzip")
+		}
+	}
+	header.Set("ETag", etag)
+
+	if notModified(r, etag) {
+		header.Del("Content-Encoding")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/json")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
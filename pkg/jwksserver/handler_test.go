@@ -0,0 +1,334 @@
+package jwksserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+const (
+	testJWKSPath      = "/.well-known/jwks.json"
+	testDiscoveryPath = "/.well-known/openid-configuration"
+	testJWKS          = `{"keys":[{"kty":"RSA","kid":"key-1","use":"sig","n":"AQAB","e":"AQAB"}]}`
+	testDiscovery     = `{"issuer":"https://issuer.example.com"}`
+	testOrigin        = "https://app.example.com"
+)
+
+// newTestServer writes the server config and served files to a temporary directory and loads them
+func newTestServer(t *testing.T, serverConfig string) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, config.ConfigMapKeyBuiltinConfig)
+	files := map[string]string{
+		configPath: serverConfig,
+		filepath.Join(dir, config.ConfigMapKeyJWKS):      testJWKS,
+		filepath.Join(dir, config.ConfigMapKeyDiscovery): testDiscovery,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	server, err := NewServer(Options{ConfigPath: configPath, DataDir: dir}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error: %v", err)
+	}
+	return server
+}
+
+func TestServerServeHTTP(t *testing.T) {
+	server := newTestServer(t, `{
+		"jwksPaths": ["`+testJWKSPath+`"],
+		"discoveryPath": "`+testDiscoveryPath+`",
+		"cacheControl": "public, max-age=60",
+		"cors": {"enabled": true, "origins": ["`+testOrigin+`"], "methods": ["GET", "OPTIONS"]},
+		"maxBodySize": 16
+	}`)
+	etag := `"` + jwks.Digest([]byte(testJWKS)) + `"`
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     map[string]string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "JWKS",
+			method:     http.MethodGet,
+			path:       testJWKSPath,
+			wantStatus: http.StatusOK,
+			wantBody:   testJWKS,
+			wantHeader: map[string]string{
+				"Content-Type":                "application/json",
+				"Cache-Control":               "public, max-age=60",
+				"ETag":                        etag,
+				config.HeaderContentDigest:    jwks.Digest([]byte(testJWKS)),
+				"X-Content-Type-Options":      "nosniff",
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:       "JWKS with allowed origin",
+			method:     http.MethodGet,
+			path:       testJWKSPath,
+			header:     map[string]string{"Origin": testOrigin},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"Access-Control-Allow-Origin": testOrigin},
+		},
+		{
+			name:       "HEAD without body",
+			method:     http.MethodHead,
+			path:       testJWKSPath,
+			wantStatus: http.StatusOK,
+			wantBody:   "",
+		},
+		{
+			name:       "matching ETag",
+			method:     http.MethodGet,
+			path:       testJWKSPath,
+			header:     map[string]string{"If-None-Match": etag},
+			wantStatus: http.StatusNotModified,
+			wantHeader: map[string]string{"ETag": etag},
+		},
+		{
+			name:       "any ETag",
+			method:     http.MethodGet,
+			path:       testJWKSPath,
+			header:     map[string]string{"If-None-Match": "*"},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "other ETag",
+			method:     http.MethodGet,
+			path:       testJWKSPath,
+			header:     map[string]string{"If-None-Match": `"other"`},
+			wantStatus: http.StatusOK,
+			wantBody:   testJWKS,
+		},
+		{
+			name:       "CORS preflight",
+			method:     http.MethodOptions,
+			path:       testJWKSPath,
+			header:     map[string]string{"Origin": testOrigin},
+			wantStatus: http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  testOrigin,
+				"Access-Control-Allow-Methods": "GET, OPTIONS",
+			},
+		},
+		{
+			name:       "discovery document",
+			method:     http.MethodGet,
+			path:       testDiscoveryPath,
+			wantStatus: http.StatusOK,
+			wantBody:   testDiscovery,
+		},
+		{
+			name:       "health check",
+			method:     http.MethodGet,
+			path:       config.HealthCheckPath,
+			wantStatus: http.StatusOK,
+			wantBody:   "ok\n",
+		},
+		{
+			name:       "unknown path",
+			method:     http.MethodGet,
+			path:       "/keys",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			path:       testJWKSPath,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantHeader: map[string]string{"Allow": "GET, HEAD, OPTIONS"},
+		},
+		{
+			name:       "body too large",
+			method:     http.MethodGet,
+			path:       testJWKSPath,
+			body:       strings.Repeat("x", 17),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" || tt.method == http.MethodHead {
+				if got := rec.Body.String(); got != tt.wantBody {
+					t.Errorf("body = %q, want %q", got, tt.wantBody)
+				}
+			}
+			for key, want := range tt.wantHeader {
+				if got := rec.Header().Get(key); got != want {
+					t.Errorf("header %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerServeHTTPGzip(t *testing.T) {
+	large := `{"keys":[{"kty":"RSA","kid":"` + strings.Repeat("k", 300) + `","n":"AQAB","e":"AQAB"}]}`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, config.ConfigMapKeyBuiltinConfig)
+	if err := os.WriteFile(configPath, []byte(`{"jwksPaths": ["`+testJWKSPath+`"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, config.ConfigMapKeyJWKS), []byte(large), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(Options{ConfigPath: configPath, DataDir: dir}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error: %v", err)
+	}
+	etag := `"` + jwks.Digest([]byte(large)) + `"`
+
+	tests := []struct {
+		name         string
+		header       map[string]string
+		wantStatus   int
+		wantEncoding string
+		wantETag     string
+	}{
+		{
+			name:       "identity",
+			wantStatus: http.StatusOK,
+			wantETag:   etag,
+		},
+		{
+			name:         "gzip with weak ETag",
+			header:       map[string]string{"Accept-Encoding": "gzip"},
+			wantStatus:   http.StatusOK,
+			wantEncoding: "gzip",
+			wantETag:     "W/" + etag,
+		},
+		{
+			name:       "weak ETag matches the strong one",
+			header:     map[string]string{"Accept-Encoding": "gzip", "If-None-Match": etag},
+			wantStatus: http.StatusNotModified,
+			wantETag:   "W/" + etag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, testJWKSPath, nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestServerServeHTTPRateLimit(t *testing.T) {
+	serverConfig := `{
+		"jwksPaths": ["` + testJWKSPath + `"],
+		"cors": {"enabled": true, "anyOrigin": true},
+		"rateLimit": {"requestsPerSecond": 1, "status": 429}
+	}`
+
+	type request struct {
+		method string
+		path   string
+		header map[string]string
+	}
+	get := request{method: http.MethodGet, path: testJWKSPath}
+	preflight := request{method: http.MethodOptions, path: testJWKSPath, header: map[string]string{"Origin": testOrigin}}
+	conditional := request{method: http.MethodGet, path: testJWKSPath, header: map[string]string{"If-None-Match": "*"}}
+	health := request{method: http.MethodGet, path: config.HealthCheckPath}
+
+	tests := []struct {
+		name       string
+		requests   []request
+		wantStatus []int
+	}{
+		{
+			name:       "requests over the rate",
+			requests:   []request{get, get},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:       "preflight is limited",
+			requests:   []request{get, preflight},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:       "preflight counts against the limit",
+			requests:   []request{preflight, get},
+			wantStatus: []int{http.StatusNoContent, http.StatusTooManyRequests},
+		},
+		{
+			name:       "conditional request is limited",
+			requests:   []request{get, conditional},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:       "not modified counts against the limit",
+			requests:   []request{conditional, get},
+			wantStatus: []int{http.StatusNotModified, http.StatusTooManyRequests},
+		},
+		{
+			name:       "health check is not limited",
+			requests:   []request{get, health, health},
+			wantStatus: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, serverConfig)
+			for i, req := range tt.requests {
+				httpReq := httptest.NewRequest(req.method, req.path, nil)
+				for key, value := range req.header {
+					httpReq.Header.Set(key, value)
+				}
+				rec := httptest.NewRecorder()
+
+				server.ServeHTTP(rec, httpReq)
+
+				if rec.Code != tt.wantStatus[i] {
+					t.Errorf("request %d %s %s: status = %d, want %d", i, req.method, req.path, rec.Code, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
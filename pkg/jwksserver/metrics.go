@@ -0,0 +1,79 @@
+package jwksserver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+const (
+	// reloadResultSuccess labels reloads that replaced the served content
+	reloadResultSuccess = "success"
+	// reloadResultError labels reloads rejected because of invalid files
+	reloadResultError = "error"
+)
+
+// serverMetrics are Prometheus metrics of the built-in server
+// HTTP metric names match the metrics exporter sidecar of nginx servers, so dashboards work for both
+type serverMetrics struct {
+	responses     *prometheus.CounterVec
+	responseSize  *prometheus.CounterVec
+	responseTime  *prometheus.HistogramVec
+	reloads       *prometheus.CounterVec
+	lastReload    prometheus.Gauge
+	keys          prometheus.Gauge
+	jwksAvailable prometheus.Gauge
+}
+
+// newServerMetrics creates the server metrics and registers them with Go and process collectors
+func newServerMetrics(registry *prometheus.Registry) *serverMetrics {
+	namespace := config.ExporterMetricsNamespace
+	m := &serverMetrics{
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_response_count_total",
+			Help:      "Total number of HTTP responses",
+		}, []string{"method", "status"}),
+		responseSize: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_response_size_bytes",
+			Help:      "Total size of HTTP response bodies in bytes",
+		}, []string{"method", "status"}),
+		responseTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_response_time_seconds",
+			Help:      "Time spent on HTTP requests in seconds",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"method", "status"}),
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reloads_total",
+			Help:      "Total number of reloads after changes of the mounted files",
+		}, []string{"result"}),
+		lastReload: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_reload_timestamp_seconds",
+			Help:      "Unix time of the last successful reload",
+		}),
+		keys: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jwks_keys",
+			Help:      "Number of keys in the served JWKS",
+		}),
+		jwksAvailable: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jwks_available",
+			Help:      "1 if jwks.json is present and served, 0 otherwise",
+		}),
+	}
+
+	registry.MustRegister(
+		m.responses, m.responseSize, m.responseTime,
+		m.reloads, m.lastReload, m.keys, m.jwksAvailable,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
@@ -0,0 +1,101 @@
+package jwksserver
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is the interval idle client states are removed at
+const rateLimitSweepInterval = time.Minute
+
+// clientState is the request count of a client IP above the rate at the time of its last accepted request
+type clientState struct {
+	excess float64
+	last   time.Time
+}
+
+// rateLimiter limits requests per client IP with the leaky bucket of nginx limit_req
+// Requests over the burst are rejected immediately, as with limit_req nodelay
+type rateLimiter struct {
+	config RateLimitConfig
+
+	mu        sync.Mutex
+	clients   map[string]*clientState
+	lastSweep time.Time
+}
+
+// newRateLimiter creates a limiter, nil if cfg does not limit requests
+func newRateLimiter(cfg *RateLimitConfig) *rateLimiter {
+	if cfg == nil {
+		return nil
+	}
+	return &rateLimiter{
+		config:  *cfg,
+		clients: make(map[string]*clientState),
+	}
+}
+
+// allow checks if a request of the client IP is accepted at now and counts it
+// A nil limiter accepts every request
+func (l *rateLimiter) allow(client string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	rate := float64(l.config.RequestsPerSecond)
+	state, ok := l.clients[client]
+	if !ok {
+		l.clients[client] = &clientState{last: now}
+		return true
+	}
+
+	excess := state.excess - rate*now.Sub(state.last).Seconds() + 1
+	if excess < 0 {
+		excess = 0
+	}
+	if excess > float64(l.config.Burst) {
+		return false
+	}
+
+	state.excess, state.last = excess, now
+	return true
+}
+
+// sweep removes clients whose excess has drained, so they would be accepted as new clients
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	rate := float64(l.config.RequestsPerSecond)
+	for client, state := range l.clients {
+		if state.excess-rate*now.Sub(state.last).Seconds()+1 <= 0 {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// sameConfig checks if the limiter enforces cfg, so its counts are kept across reloads
+func (l *rateLimiter) sameConfig(cfg *RateLimitConfig) bool {
+	if l == nil || cfg == nil {
+		return l == nil && cfg == nil
+	}
+	return l.config == *cfg
+}
+
+// clientIP returns the IP of the connection, forwarded headers are not trusted like in the nginx config
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
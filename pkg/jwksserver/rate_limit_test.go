@@ -0,0 +1,116 @@
+package jwksserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type request struct {
+		client string
+		offset time.Duration
+		want   bool
+	}
+
+	tests := []struct {
+		name     string
+		config   *RateLimitConfig
+		requests []request
+	}{
+		{
+			name:   "no limit",
+			config: nil,
+			requests: []request{
+				{client: "a", want: true},
+				{client: "a", want: true},
+				{client: "a", want: true},
+			},
+		},
+		{
+			name:   "rate without burst",
+			config: &RateLimitConfig{RequestsPerSecond: 1, Status: 429},
+			requests: []request{
+				{client: "a", want: true},
+				{client: "a", offset: 100 * time.Millisecond, want: false},
+				{client: "a", offset: 1100 * time.Millisecond, want: true},
+			},
+		},
+		{
+			name:   "burst over the rate",
+			config: &RateLimitConfig{RequestsPerSecond: 1, Burst: 2, Status: 429},
+			requests: []request{
+				{client: "a", want: true},
+				{client: "a", want: true},
+				{client: "a", want: true},
+				{client: "a", want: false},
+			},
+		},
+		{
+			name:   "clients are limited separately",
+			config: &RateLimitConfig{RequestsPerSecond: 1, Status: 429},
+			requests: []request{
+				{client: "a", want: true},
+				{client: "b", want: true},
+				{client: "a", want: false},
+				{client: "b", want: false},
+			},
+		},
+		{
+			name:   "rejected requests are not counted",
+			config: &RateLimitConfig{RequestsPerSecond: 2, Status: 429},
+			requests: []request{
+				{client: "a", want: true},
+				{client: "a", offset: 100 * time.Millisecond, want: false},
+				{client: "a", offset: 200 * time.Millisecond, want: false},
+				{client: "a", offset: 500 * time.Millisecond, want: true},
+			},
+		},
+		{
+			name:   "drained clients are accepted again",
+			config: &RateLimitConfig{RequestsPerSecond: 1, Status: 429},
+			requests: []request{
+				{client: "a", want: true},
+				{client: "b", offset: 2 * rateLimitSweepInterval, want: true},
+				{client: "a", offset: 2 * rateLimitSweepInterval, want: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter(tt.config)
+			for i, req := range tt.requests {
+				if got := limiter.allow(req.client, start.Add(req.offset)); got != req.want {
+					t.Errorf("request %d of %s at +%s: allow() = %v, want %v", i, req.client, req.offset, got, req.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterSameConfig(t *testing.T) {
+	limit := &RateLimitConfig{RequestsPerSecond: 5, Burst: 1, Status: 429}
+
+	tests := []struct {
+		name    string
+		limiter *rateLimiter
+		config  *RateLimitConfig
+		want    bool
+	}{
+		{name: "both unlimited", limiter: nil, config: nil, want: true},
+		{name: "limit added", limiter: nil, config: limit, want: false},
+		{name: "limit removed", limiter: newRateLimiter(limit), config: nil, want: false},
+		{name: "same limit", limiter: newRateLimiter(limit), config: &RateLimitConfig{RequestsPerSecond: 5, Burst: 1, Status: 429}, want: true},
+		{name: "changed rate", limiter: newRateLimiter(limit), config: &RateLimitConfig{RequestsPerSecond: 6, Burst: 1, Status: 429}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limiter.sameConfig(tt.config); got != tt.want {
+				t.Errorf("sameConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
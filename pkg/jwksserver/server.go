@@ -0,0 +1,215 @@
+package jwksserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// shutdownTimeout is the time given to in-flight requests on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// Options are command line settings of the built-in server
+type Options struct {
+	// ConfigPath is the path of the server config generated by the operator
+	ConfigPath string
+	// DataDir is the directory the JWKS ConfigMap is mounted at
+	DataDir string
+	// ListenAddress is the address JWKS and the health check are served on
+	ListenAddress string
+	// MetricsAddress is the address Prometheus metrics are served on, empty disables metrics
+	MetricsAddress string
+	// ReloadInterval is the interval mounted files are checked for changes
+	ReloadInterval time.Duration
+	// TLSListenAddress is the address JWKS is served on over https, empty disables https
+	TLSListenAddress string
+	// TLSCertFile and TLSKeyFile are the PEM certificate and key of the https listener
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// tlsConfig returns the TLS settings of the https listener: TLS 1.2 with forward secret AEAD suites and TLS 1.3
+// The certificate is loaded at startup, the operator rolls the pods when it changes
+func tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// Server serves a mounted JWKS and reloads it when kubelet refreshes the ConfigMap volume
+type Server struct {
+	options  Options
+	state    atomic.Pointer[state]
+	registry *prometheus.Registry
+	metrics  *serverMetrics
+	logger   *zap.Logger
+	// accessLog writes access log records to stdout next to the zap logs
+	accessLog *accessLogger
+}
+
+// NewServer creates a server and loads the mounted files
+// Invalid files at startup are an error, later they are rejected and the served content is kept
+func NewServer(options Options, logger *zap.Logger) (*Server, error) {
+	if options.ReloadInterval <= 0 {
+		options.ReloadInterval = config.DefaultBuiltinReloadInterval
+	}
+
+	registry := prometheus.NewRegistry()
+	s := &Server{
+		options:   options,
+		registry:  registry,
+		metrics:   newServerMetrics(registry),
+		logger:    logger,
+		accessLog: newAccessLogger(os.Stdout),
+	}
+
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload reads the mounted files and replaces the served state if they changed
+// Returns true if the state was replaced
+func (s *Server) Reload() (bool, error) {
+	rawConfig, rawJWKS, rawDiscovery, err := readFiles(s.options.ConfigPath, s.options.DataDir)
+	if err != nil {
+		s.metrics.reloads.WithLabelValues(reloadResultError).Inc()
+		return false, err
+	}
+
+	if s.state.Load().unchanged(rawConfig, rawJWKS, rawDiscovery) {
+		return false, nil
+	}
+
+	next, err := buildState(rawConfig, rawJWKS, rawDiscovery)
+	if err != nil {
+		s.metrics.reloads.WithLabelValues(reloadResultError).Inc()
+		return false, err
+	}
+
+	if previous := s.state.Load(); previous != nil && previous.limiter.sameConfig(next.config.RateLimit) {
+		next.limiter = previous.limiter
+	} else {
+		next.limiter = newRateLimiter(next.config.RateLimit)
+	}
+	s.state.Store(next)
+	s.metrics.reloads.WithLabelValues(reloadResultSuccess).Inc()
+	s.metrics.lastReload.SetToCurrentTime()
+	s.metrics.keys.Set(float64(next.keys))
+	if next.jwks != nil {
+		s.metrics.jwksAvailable.Set(1)
+	} else {
+		s.metrics.jwksAvailable.Set(0)
+	}
+
+	etag := ""
+	if next.jwks != nil {
+		etag = next.jwks.etag
+	}
+	s.logger.Info("served content loaded",
+		zap.Strings("jwksPaths", next.config.JWKSPaths),
+		zap.String("discoveryPath", next.config.DiscoveryPath),
+		zap.Int("keys", next.keys),
+		zap.String("etag", etag),
+	)
+
+	return true, nil
+}
+
+// watch reloads the mounted files until the context is canceled
+// ConfigMap volumes are updated by swapping a symlink, so polling sees complete files only
+func (s *Server) watch(ctx context.Context) {
+	ticker := time.NewTicker(s.options.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Reload(); err != nil {
+				s.logger.Warn("failed to reload mounted files, serving previous content", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Run serves JWKS and metrics until the context is canceled
+func (s *Server) Run(ctx context.Context) error {
+	servers := []*http.Server{
+		{Addr: s.options.ListenAddress, Handler: s, ReadHeaderTimeout: 10 * time.Second},
+	}
+	if s.options.TLSListenAddress != "" {
+		servers = append(servers, &http.Server{
+			Addr:              s.options.TLSListenAddress,
+			Handler:           s,
+			TLSConfig:         tlsConfig(),
+			ReadHeaderTimeout: 10 * time.Second,
+		})
+	}
+	if s.options.MetricsAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle(config.ExporterMetricsPath, promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
+		servers = append(servers, &http.Server{Addr: s.options.MetricsAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			s.logger.Info("listening", zap.String("address", server.Addr), zap.Bool("tls", server.TLSConfig != nil))
+			var err error
+			if server.TLSConfig != nil {
+				err = server.ListenAndServeTLS(s.options.TLSCertFile, s.options.TLSKeyFile)
+			} else {
+				err = server.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("failed to serve on %s: %w", server.Addr, err)
+				return
+			}
+			errs <- nil
+		}(server)
+	}
+
+	go s.watch(ctx)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case runErr = <-errs:
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil && runErr == nil {
+			runErr = fmt.Errorf("failed to shut down %s: %w", server.Addr, err)
+		}
+	}
+
+	return runErr
+}
@@ -0,0 +1,79 @@
+package jwksserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestServerReload(t *testing.T) {
+	tests := []struct {
+		name        string
+		jwks        string
+		remove      bool
+		wantChanged bool
+		wantErr     bool
+		wantKeys    int
+		wantServed  bool
+	}{
+		{
+			name:       "unchanged files",
+			jwks:       testJWKS,
+			wantKeys:   1,
+			wantServed: true,
+		},
+		{
+			name:        "updated JWKS",
+			jwks:        `{"keys":[]}`,
+			wantChanged: true,
+			wantServed:  true,
+		},
+		{
+			name:        "removed JWKS",
+			remove:      true,
+			wantChanged: true,
+		},
+		{
+			name:       "invalid JWKS keeps the served content",
+			jwks:       "{",
+			wantErr:    true,
+			wantKeys:   1,
+			wantServed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, `{"jwksPaths": ["`+testJWKSPath+`"]}`)
+			jwksFile := filepath.Join(server.options.DataDir, config.ConfigMapKeyJWKS)
+
+			var err error
+			if tt.remove {
+				err = os.Remove(jwksFile)
+			} else {
+				err = os.WriteFile(jwksFile, []byte(tt.jwks), 0o600)
+			}
+			if err != nil {
+				t.Fatalf("failed to change %s: %v", jwksFile, err)
+			}
+
+			changed, err := server.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, want error %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("Reload() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			current := server.state.Load()
+			if current.keys != tt.wantKeys {
+				t.Errorf("served keys = %d, want %d", current.keys, tt.wantKeys)
+			}
+			if (current.jwks != nil) != tt.wantServed {
+				t.Errorf("JWKS served = %v, want %v", current.jwks != nil, tt.wantServed)
+			}
+		})
+	}
+}
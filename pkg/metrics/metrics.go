@@ -68,6 +68,15 @@ var (
 		[]string{"result"}, // result: success, error
 	)
 
+	// JWKSPropagationDuration is a histogram for the time new JWKS content takes to reach every server pod
+	JWKSPropagationDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "jwks_operator_jwks_propagation_duration_seconds",
+			Help:    "Time from a JWKS ConfigMap update until every ready server pod serves the new content",
+			Buckets: []float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 300},
+		},
+	)
+
 	// ErrorsTotal is a counter for errors by type
 	ErrorsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -104,6 +113,11 @@ func RecordJWKSVerification(result string) {
 	JWKSVerificationTotal.WithLabelValues(result).Inc()
 }
 
+// RecordJWKSPropagation records the propagation time of new JWKS content
+func RecordJWKSPropagation(duration float64) {
+	JWKSPropagationDuration.Observe(duration)
+}
+
 // RecordError records an error by type
 func RecordError(errorType string) {
 	ErrorsTotal.WithLabelValues(errorType).Inc()
@@ -0,0 +1,110 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// ErrNotManaged is returned if an object with the same name exists and is not managed by the operator
+var ErrNotManaged = errors.New("object exists and is not managed by the operator")
+
+// IsPrometheusOperatorNotInstalled checks if the error is caused by missing Prometheus Operator CRDs
+func IsPrometheusOperatorNotInstalled(err error) bool {
+	return meta.IsNoMatchError(err)
+}
+
+// Manager creates ServiceMonitor, PodMonitor and metrics Service resources scraping the JWKS server
+type Manager struct {
+	client client.Client
+}
+
+// NewManager creates a new monitoring manager
+func NewManager(client client.Client) *Manager {
+	return &Manager{
+		client: client,
+	}
+}
+
+// Monitor returns spec.server.metrics.monitor, nil if no monitor is requested
+func Monitor(server *v1alpha1.ServerSpec) *v1alpha1.MonitorSpec {
+	if server == nil || server.Metrics == nil {
+		return nil
+	}
+	return server.Metrics.Monitor
+}
+
+// MonitorKind returns the kind of the monitor with the default applied
+func MonitorKind(spec *v1alpha1.MonitorSpec) string {
+	if spec == nil || spec.Kind == "" {
+		return config.ServiceMonitorKind
+	}
+	return spec.Kind
+}
+
+// MetricsServiceName returns the name of the metrics Service of the JWKS server
+func MetricsServiceName(jwksName string) string {
+	return jwksName + config.MetricsServiceSuffix
+}
+
+// Sync creates or updates the monitor from spec and deletes objects of the other kind
+// A nil spec deletes the monitors and the metrics Service
+func (m *Manager) Sync(ctx context.Context, jwksObj *v1alpha1.JWKS, spec *v1alpha1.MonitorSpec) error {
+	monitorKey := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Name}
+	serviceKey := types.NamespacedName{Namespace: jwksObj.Namespace, Name: MetricsServiceName(jwksObj.Name)}
+	managed := func(obj client.Object) bool {
+		return isManaged(obj, jwksObj)
+	}
+
+	kind := ""
+	if spec != nil {
+		kind = MonitorKind(spec)
+		if err := m.ensureMonitor(ctx, buildJWKSMonitor(jwksObj, spec), jwksObj, managed); err != nil {
+			return fmt.Errorf("failed to ensure %s: %w", kind, err)
+		}
+	}
+
+	for _, other := range []string{config.ServiceMonitorKind, config.PodMonitorKind} {
+		if other == kind {
+			continue
+		}
+		if err := m.deleteMonitor(ctx, other, monitorKey, managed); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", other, err)
+		}
+	}
+
+	// The metrics Service is only scraped through a ServiceMonitor
+	if kind == config.ServiceMonitorKind {
+		if err := m.ensureService(ctx, buildJWKSMetricsService(jwksObj), jwksObj, managed); err != nil {
+			return fmt.Errorf("failed to ensure metrics Service: %w", err)
+		}
+		return nil
+	}
+	if err := m.deleteService(ctx, serviceKey, managed); err != nil {
+		return fmt.Errorf("failed to delete metrics Service: %w", err)
+	}
+	return nil
+}
+
+// labels returns labels of monitoring resources of the JWKS server
+func labels(jwksObj *v1alpha1.JWKS) map[string]string {
+	return map[string]string{
+		config.LabelJWKSConfig: jwksObj.Name,
+		config.LabelManagedBy:  config.LabelManagedByValue,
+		config.LabelComponent:  config.LabelComponentMetrics,
+	}
+}
+
+// isManaged checks if the object carries the operator labels for this JWKS
+func isManaged(obj client.Object, jwksObj *v1alpha1.JWKS) bool {
+	objLabels := obj.GetLabels()
+	return objLabels[config.LabelManagedBy] == config.LabelManagedByValue &&
+		objLabels[config.LabelJWKSConfig] == jwksObj.Name
+}
@@ -0,0 +1,204 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/utils"
+)
+
+// desiredMonitor is the desired state of a ServiceMonitor or PodMonitor
+type desiredMonitor struct {
+	kind   string
+	key    types.NamespacedName
+	labels map[string]string
+	spec   map[string]interface{}
+}
+
+// newMonitor returns an empty unstructured monitor of the kind
+// Monitors are handled as unstructured objects so Prometheus Operator CRDs stay optional
+func newMonitor(kind string) *unstructured.Unstructured {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   config.MonitoringGroup,
+		Version: config.MonitoringVersion,
+		Kind:    kind,
+	})
+	return monitor
+}
+
+// buildEndpoint builds the scrape endpoint of the metrics port
+func buildEndpoint(path, interval string) map[string]interface{} {
+	endpoint := map[string]interface{}{
+		"port": config.MetricsPortName,
+		"path": path,
+	}
+	if interval != "" {
+		endpoint["interval"] = interval
+	}
+	return endpoint
+}
+
+// buildMonitorSpec builds the spec of a monitor scraping objects selected by matchLabels
+func buildMonitorSpec(kind string, matchLabels map[string]string, endpoint map[string]interface{}) map[string]interface{} {
+	selector := make(map[string]interface{}, len(matchLabels))
+	for key, value := range matchLabels {
+		selector[key] = value
+	}
+
+	endpointsField := "endpoints"
+	if kind == config.PodMonitorKind {
+		endpointsField = "podMetricsEndpoints"
+	}
+
+	return map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": selector,
+		},
+		endpointsField: []interface{}{endpoint},
+	}
+}
+
+// buildJWKSMonitor builds the monitor of the JWKS server from spec.server.metrics.monitor
+// A ServiceMonitor selects the metrics Service, a PodMonitor selects the server pods
+func buildJWKSMonitor(jwksObj *v1alpha1.JWKS, spec *v1alpha1.MonitorSpec) desiredMonitor {
+	kind := MonitorKind(spec)
+
+	matchLabels := nginx.SelectorLabels(jwksObj.Name)
+	if kind == config.ServiceMonitorKind {
+		matchLabels = map[string]string{
+			config.LabelJWKSConfig: jwksObj.Name,
+			config.LabelComponent:  config.LabelComponentMetrics,
+		}
+	}
+
+	return desiredMonitor{
+		kind:   kind,
+		key:    types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Name},
+		labels: mergeLabels(spec.Labels, labels(jwksObj)),
+		spec:   buildMonitorSpec(kind, matchLabels, buildEndpoint(config.ExporterMetricsPath, spec.Interval)),
+	}
+}
+
+// mergeLabels returns user labels with the operator labels set over them
+func mergeLabels(user, operator map[string]string) map[string]string {
+	merged := make(map[string]string, len(user)+len(operator))
+	for key, value := range user {
+		merged[key] = value
+	}
+	for key, value := range operator {
+		merged[key] = value
+	}
+	return merged
+}
+
+// specHash returns a hash of the desired monitor labels and spec
+// The API server adds defaults to monitors, so the desired state is compared by hash instead of content
+func specHash(desired desiredMonitor) (string, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"labels": desired.labels,
+		"spec":   desired.spec,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s spec: %w", desired.kind, err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// ensureMonitor creates or updates the monitor, labels and spec are replaced when the desired state changes
+// owner is set as the controller of the monitor if not nil
+func (m *Manager) ensureMonitor(ctx context.Context, desired desiredMonitor, owner metav1.Object, managed func(client.Object) bool) error {
+	hash, err := specHash(desired)
+	if err != nil {
+		return err
+	}
+
+	monitor := newMonitor(desired.kind)
+	err = m.client.Get(ctx, desired.key, monitor)
+	if apierrors.IsNotFound(err) {
+		monitor = newMonitor(desired.kind)
+		monitor.SetName(desired.key.Name)
+		monitor.SetNamespace(desired.key.Namespace)
+		monitor.SetLabels(desired.labels)
+		setSpecHash(monitor, hash)
+		monitor.Object["spec"] = desired.spec
+		if owner != nil {
+			if _, err := utils.SetControllerReference(owner, monitor, m.client.Scheme()); err != nil {
+				return err
+			}
+		}
+		return m.client.Create(ctx, monitor)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", desired.kind, err)
+	}
+
+	if !managed(monitor) {
+		return fmt.Errorf("%s %s: %w", desired.kind, desired.key.Name, ErrNotManaged)
+	}
+
+	changed := false
+	if monitor.GetAnnotations()[config.AnnotationSpecHash] != hash {
+		monitor.SetLabels(desired.labels)
+		setSpecHash(monitor, hash)
+		monitor.Object["spec"] = desired.spec
+		changed = true
+	}
+	if owner != nil {
+		ownerChanged, err := utils.SetControllerReference(owner, monitor, m.client.Scheme())
+		if err != nil {
+			return err
+		}
+		changed = changed || ownerChanged
+	}
+
+	if !changed {
+		return nil
+	}
+	return m.client.Update(ctx, monitor)
+}
+
+// setSpecHash stores the hash of the desired state in the monitor annotations
+func setSpecHash(monitor *unstructured.Unstructured, hash string) {
+	annotations := monitor.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[config.AnnotationSpecHash] = hash
+	monitor.SetAnnotations(annotations)
+}
+
+// deleteMonitor deletes the monitor of the kind, unmanaged objects are kept
+// Missing Prometheus Operator CRDs mean there is nothing to delete
+func (m *Manager) deleteMonitor(ctx context.Context, kind string, key types.NamespacedName, managed func(client.Object) bool) error {
+	monitor := newMonitor(kind)
+	if err := m.client.Get(ctx, key, monitor); err != nil {
+		if apierrors.IsNotFound(err) || IsPrometheusOperatorNotInstalled(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+
+	if !managed(monitor) {
+		return nil
+	}
+
+	if err := m.client.Delete(ctx, monitor); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
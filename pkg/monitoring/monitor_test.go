@@ -0,0 +1,157 @@
+package monitoring
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// newMonitorJWKS returns a JWKS resource named auth/default
+func newMonitorJWKS() *v1alpha1.JWKS {
+	return &v1alpha1.JWKS{ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"}}
+}
+
+func TestBuildJWKSMonitor(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          *v1alpha1.MonitorSpec
+		wantKind      string
+		wantSelector  map[string]string
+		wantEndpoints string
+		wantInterval  string
+	}{
+		{
+			name:     "ServiceMonitor by default",
+			spec:     &v1alpha1.MonitorSpec{},
+			wantKind: config.ServiceMonitorKind,
+			wantSelector: map[string]string{
+				config.LabelJWKSConfig: "auth",
+				config.LabelComponent:  config.LabelComponentMetrics,
+			},
+			wantEndpoints: "endpoints",
+		},
+		{
+			name:          "PodMonitor with interval",
+			spec:          &v1alpha1.MonitorSpec{Kind: config.PodMonitorKind, Interval: "15s"},
+			wantKind:      config.PodMonitorKind,
+			wantSelector:  nginx.SelectorLabels("auth"),
+			wantEndpoints: "podMetricsEndpoints",
+			wantInterval:  "15s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := buildJWKSMonitor(newMonitorJWKS(), tt.spec)
+
+			if desired.kind != tt.wantKind || desired.key.Name != "auth" || desired.key.Namespace != "default" {
+				t.Errorf("monitor = %s %s, want %s default/auth", desired.kind, desired.key, tt.wantKind)
+			}
+
+			selector := desired.spec["selector"].(map[string]interface{})["matchLabels"].(map[string]interface{})
+			if len(selector) != len(tt.wantSelector) {
+				t.Errorf("selector = %v, want %v", selector, tt.wantSelector)
+			}
+			for key, value := range tt.wantSelector {
+				if selector[key] != value {
+					t.Errorf("selector = %v, want %v", selector, tt.wantSelector)
+				}
+			}
+
+			endpoints, ok := desired.spec[tt.wantEndpoints].([]interface{})
+			if !ok || len(endpoints) != 1 {
+				t.Fatalf("spec = %v, want one endpoint in %s", desired.spec, tt.wantEndpoints)
+			}
+			endpoint := endpoints[0].(map[string]interface{})
+			if endpoint["port"] != config.MetricsPortName || endpoint["path"] != config.ExporterMetricsPath {
+				t.Errorf("endpoint = %v, want port %s and path %s", endpoint, config.MetricsPortName, config.ExporterMetricsPath)
+			}
+			if interval, _ := endpoint["interval"].(string); interval != tt.wantInterval {
+				t.Errorf("interval = %q, want %q", interval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		user map[string]string
+		want map[string]string
+	}{
+		{name: "operator labels only", want: labels(newMonitorJWKS())},
+		{
+			name: "user labels are added",
+			user: map[string]string{"release": "prometheus"},
+			want: map[string]string{
+				"release":              "prometheus",
+				config.LabelJWKSConfig: "auth",
+				config.LabelManagedBy:  config.LabelManagedByValue,
+				config.LabelComponent:  config.LabelComponentMetrics,
+			},
+		},
+		{
+			name: "operator labels win",
+			user: map[string]string{config.LabelManagedBy: "helm"},
+			want: labels(newMonitorJWKS()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeLabels(tt.user, labels(newMonitorJWKS())); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	base := buildJWKSMonitor(newMonitorJWKS(), &v1alpha1.MonitorSpec{Interval: "30s"})
+	baseHash, err := specHash(base)
+	if err != nil {
+		t.Fatalf("specHash() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		spec      *v1alpha1.MonitorSpec
+		wantEqual bool
+	}{
+		{name: "same spec", spec: &v1alpha1.MonitorSpec{Interval: "30s"}, wantEqual: true},
+		{name: "changed interval", spec: &v1alpha1.MonitorSpec{Interval: "1m"}},
+		{name: "added label", spec: &v1alpha1.MonitorSpec{Interval: "30s", Labels: map[string]string{"release": "prometheus"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := specHash(buildJWKSMonitor(newMonitorJWKS(), tt.spec))
+			if err != nil {
+				t.Fatalf("specHash() unexpected error: %v", err)
+			}
+			if (hash == baseHash) != tt.wantEqual {
+				t.Errorf("specHash() = %s, base %s, want equal %v", hash, baseHash, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestBuildJWKSMetricsService(t *testing.T) {
+	service := buildJWKSMetricsService(newMonitorJWKS())
+
+	if service.Name != MetricsServiceName("auth") || service.Spec.ClusterIP != "None" {
+		t.Errorf("service = %s clusterIP %q, want headless %s", service.Name, service.Spec.ClusterIP, MetricsServiceName("auth"))
+	}
+	if !reflect.DeepEqual(service.Spec.Selector, map[string]string{config.LabelApp: "auth"}) {
+		t.Errorf("selector = %v, want the server pods", service.Spec.Selector)
+	}
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != config.ExporterPort ||
+		service.Spec.Ports[0].TargetPort.StrVal != config.MetricsPortName {
+		t.Errorf("ports = %+v, want the metrics port", service.Spec.Ports)
+	}
+}
@@ -0,0 +1,161 @@
+package monitoring
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// OperatorMonitor creates the metrics Service and ServiceMonitor of the operator from metrics.serviceMonitor
+// It runs as a manager Runnable on the leader and restores deleted objects every reconcile interval
+type OperatorMonitor struct {
+	manager *Manager
+	config  *config.Config
+	logger  *zap.Logger
+	// notInstalledLogged limits the log of missing Prometheus Operator CRDs to the first check
+	notInstalledLogged bool
+}
+
+// NewOperatorMonitor creates the operator monitor
+func NewOperatorMonitor(client client.Client, cfg *config.Config, logger *zap.Logger) *OperatorMonitor {
+	return &OperatorMonitor{
+		manager: NewManager(client),
+		config:  cfg,
+		logger:  logger,
+	}
+}
+
+// NeedLeaderElection runs the operator monitor on the leader only
+func (o *OperatorMonitor) NeedLeaderElection() bool {
+	return true
+}
+
+// Start ensures the operator monitoring objects until ctx is done
+// Errors are logged and never stop the manager
+func (o *OperatorMonitor) Start(ctx context.Context) error {
+	if !o.config.Metrics.ServiceMonitor.Enabled {
+		// The config file is read at start, objects of a previous run are deleted once
+		o.delete(ctx)
+		return nil
+	}
+
+	interval := o.config.ReconcileInterval.Duration
+	if interval <= 0 {
+		interval = config.DefaultReconcileInterval().Duration
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		o.ensure(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// key returns the name of the operator metrics Service and ServiceMonitor
+func (o *OperatorMonitor) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: o.config.Namespace, Name: config.OperatorMetricsName}
+}
+
+// operatorLabels returns labels of the operator metrics Service and ServiceMonitor
+func operatorLabels() map[string]string {
+	return map[string]string{
+		config.LabelApp:       config.LabelManagedByValue,
+		config.LabelManagedBy: config.LabelManagedByValue,
+		config.LabelComponent: config.LabelComponentMetrics,
+	}
+}
+
+// isOperatorManaged checks if the object carries the labels of the operator metrics objects
+func isOperatorManaged(obj client.Object) bool {
+	objLabels := obj.GetLabels()
+	return objLabels[config.LabelManagedBy] == config.LabelManagedByValue &&
+		objLabels[config.LabelApp] == config.LabelManagedByValue &&
+		objLabels[config.LabelComponent] == config.LabelComponentMetrics
+}
+
+// selector returns the labels of the operator pods
+func (o *OperatorMonitor) selector() map[string]string {
+	if selector := o.config.Metrics.ServiceMonitor.Selector; len(selector) > 0 {
+		return selector
+	}
+	return map[string]string{config.LabelControlPlane: config.LabelControlPlaneValue}
+}
+
+// ensure creates or updates the ServiceMonitor and the metrics Service it scrapes
+// Missing Prometheus Operator CRDs skip both
+func (o *OperatorMonitor) ensure(ctx context.Context) {
+	key := o.key()
+	settings := o.config.Metrics.ServiceMonitor
+
+	monitor := desiredMonitor{
+		kind:   config.ServiceMonitorKind,
+		key:    key,
+		labels: mergeLabels(settings.Labels, operatorLabels()),
+		spec: buildMonitorSpec(config.ServiceMonitorKind, operatorLabels(),
+			buildEndpoint(o.config.Metrics.Path, settings.Interval)),
+	}
+	if err := o.manager.ensureMonitor(ctx, monitor, nil, isOperatorManaged); err != nil {
+		if IsPrometheusOperatorNotInstalled(err) {
+			if !o.notInstalledLogged {
+				o.notInstalledLogged = true
+				o.logger.Info("Prometheus Operator CRDs not installed, skipping operator ServiceMonitor",
+					zap.String("namespace", key.Namespace),
+					zap.String("name", key.Name),
+				)
+			}
+			return
+		}
+		o.logger.Error("failed to ensure operator ServiceMonitor",
+			zap.String("namespace", key.Namespace),
+			zap.String("name", key.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	port := intstr.FromInt32(int32(o.config.Metrics.Port)) //nolint:gosec // G115: metrics port is validated by config.Validate
+	service := buildMetricsService(key, operatorLabels(), o.selector(), port, port.IntVal)
+	if err := o.manager.ensureService(ctx, service, nil, isOperatorManaged); err != nil {
+		o.logger.Error("failed to ensure operator metrics Service",
+			zap.String("namespace", key.Namespace),
+			zap.String("name", key.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	o.logger.Debug("operator ServiceMonitor ensured",
+		zap.String("namespace", key.Namespace),
+		zap.String("name", key.Name),
+	)
+}
+
+// delete removes the operator metrics Service and ServiceMonitor created while metrics.serviceMonitor was enabled
+func (o *OperatorMonitor) delete(ctx context.Context) {
+	key := o.key()
+	if err := o.manager.deleteMonitor(ctx, config.ServiceMonitorKind, key, isOperatorManaged); err != nil {
+		o.logger.Warn("failed to delete operator ServiceMonitor",
+			zap.String("namespace", key.Namespace),
+			zap.String("name", key.Name),
+			zap.Error(err),
+		)
+	}
+	if err := o.manager.deleteService(ctx, key, isOperatorManaged); err != nil {
+		o.logger.Warn("failed to delete operator metrics Service",
+			zap.String("namespace", key.Namespace),
+			zap.String("name", key.Name),
+			zap.Error(err),
+		)
+	}
+}
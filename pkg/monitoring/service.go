@@ -0,0 +1,129 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/utils"
+)
+
+// buildMetricsService builds a headless Service exposing the metrics port of the selected pods
+// Metrics are kept off the Service serving JWKS, which may be exposed publicly
+func buildMetricsService(key types.NamespacedName, serviceLabels, selector map[string]string, targetPort intstr.IntOrString, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    serviceLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  selector,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       config.MetricsPortName,
+					Port:       port,
+					TargetPort: targetPort,
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// buildJWKSMetricsService builds the metrics Service of the JWKS server pods
+// The selector matches the Service serving JWKS
+func buildJWKSMetricsService(jwksObj *v1alpha1.JWKS) *corev1.Service {
+	return buildMetricsService(
+		types.NamespacedName{Namespace: jwksObj.Namespace, Name: MetricsServiceName(jwksObj.Name)},
+		labels(jwksObj),
+		map[string]string{config.LabelApp: jwksObj.Name},
+		intstr.FromString(config.MetricsPortName),
+		config.ExporterPort,
+	)
+}
+
+// ensureService creates or updates the metrics Service
+// owner is set as the controller of the Service if not nil
+func (m *Manager) ensureService(ctx context.Context, desired *corev1.Service, owner metav1.Object, managed func(client.Object) bool) error {
+	service := &corev1.Service{}
+	key := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
+
+	err := m.client.Get(ctx, key, service)
+	if apierrors.IsNotFound(err) {
+		if owner != nil {
+			if _, err := utils.SetControllerReference(owner, desired, m.client.Scheme()); err != nil {
+				return err
+			}
+		}
+		return m.client.Create(ctx, desired)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get metrics Service: %w", err)
+	}
+
+	if !managed(service) {
+		return fmt.Errorf("metrics Service %s: %w", desired.Name, ErrNotManaged)
+	}
+
+	changed := false
+	for key, value := range desired.Labels {
+		if service.Labels[key] != value {
+			if service.Labels == nil {
+				service.Labels = make(map[string]string)
+			}
+			service.Labels[key] = value
+			changed = true
+		}
+	}
+	if !equality.Semantic.DeepEqual(service.Spec.Selector, desired.Spec.Selector) {
+		service.Spec.Selector = desired.Spec.Selector
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(service.Spec.Ports, desired.Spec.Ports) {
+		service.Spec.Ports = desired.Spec.Ports
+		changed = true
+	}
+	if owner != nil {
+		ownerChanged, err := utils.SetControllerReference(owner, service, m.client.Scheme())
+		if err != nil {
+			return err
+		}
+		changed = changed || ownerChanged
+	}
+
+	if !changed {
+		return nil
+	}
+	return m.client.Update(ctx, service)
+}
+
+// deleteService deletes the metrics Service, unmanaged Services are kept
+func (m *Manager) deleteService(ctx context.Context, key types.NamespacedName, managed func(client.Object) bool) error {
+	service := &corev1.Service{}
+	if err := m.client.Get(ctx, key, service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get metrics Service: %w", err)
+	}
+
+	if !managed(service) {
+		return nil
+	}
+
+	if err := m.client.Delete(ctx, service); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package nginx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+const (
+	// accessLogVariable is the nginx variable set to 0 for probe requests that are not logged
+	accessLogVariable = "$jwks_access_log"
+	// probeSampleVariable is the nginx variable set to 1 for the sampled share of probe requests
+	probeSampleVariable = "$jwks_probe_sampled"
+	// jsonLogFormat is the name of the JSON access log format
+	jsonLogFormat = "jwks_json"
+	// accessLogPath is the access log of the official nginx images, a symlink to stdout
+	accessLogPath = "/var/log/nginx/access.log"
+)
+
+// AccessLogPolicy is the access log resolved from spec.server.accessLog
+type AccessLogPolicy struct {
+	// Enabled is false if spec.server.accessLog is not set and the image defaults are kept
+	Enabled bool
+	// Format is combined, json or off
+	Format string
+	// ProbeSamplePercent is the percentage of probe requests that are logged
+	ProbeSamplePercent int
+}
+
+// ResolveAccessLog resolves the access log from spec.server.accessLog
+func ResolveAccessLog(spec *v1alpha1.AccessLogSpec) AccessLogPolicy {
+	if spec == nil {
+		return AccessLogPolicy{}
+	}
+
+	policy := AccessLogPolicy{
+		Enabled:            true,
+		Format:             spec.Format,
+		ProbeSamplePercent: int(spec.ProbeSamplePercent),
+	}
+	if policy.Format == "" {
+		policy.Format = config.AccessLogFormatCombined
+	}
+	return policy
+}
+
+// AccessLog returns spec.server.accessLog, nil if the image defaults are kept
+func AccessLog(server *v1alpha1.ServerSpec) *v1alpha1.AccessLogSpec {
+	if server == nil {
+		return nil
+	}
+	return server.AccessLog
+}
+
+// Validate checks the access log for values that cannot be put into nginx configuration
+func (p AccessLogPolicy) Validate() error {
+	switch p.Format {
+	case "", config.AccessLogFormatCombined, config.AccessLogFormatJSON, config.AccessLogFormatOff:
+	default:
+		return fmt.Errorf("unknown access log format %q", p.Format)
+	}
+	if p.ProbeSamplePercent < 0 || p.ProbeSamplePercent > 100 {
+		return fmt.Errorf("probeSamplePercent %d must be between 0 and 100", p.ProbeSamplePercent)
+	}
+	return nil
+}
+
+// logged checks if any access log records are written to stdout
+func (p AccessLogPolicy) logged() bool {
+	return p.Enabled && p.Format != config.AccessLogFormatOff
+}
+
+// contentDigestVariable is the nginx variable of the content digest response header
+func contentDigestVariable() string {
+	return "$sent_http_" + strings.ToLower(strings.ReplaceAll(config.HeaderContentDigest, "-", "_"))
+}
+
+// GenerateJSONLogFormat generates the http-level JSON log format
+// The JWKS name and namespace are constants, the digest is taken from the response header of JWKS responses
+// Returns an empty string unless the json format is selected
+func (g *ConfigGenerator) GenerateJSONLogFormat(policy AccessLogPolicy, name, namespace string) string {
+	if !policy.Enabled || policy.Format != config.AccessLogFormatJSON {
+		return ""
+	}
+
+	return fmt.Sprintf(`log_format %s escape=json
+    '{"time":"$time_iso8601","jwks":"%s","namespace":"%s",'
+    '"remote_addr":"$remote_addr","request_method":"$request_method","request_uri":"$request_uri",'
+    '"status":$status,"body_bytes_sent":$body_bytes_sent,"request_time":$request_time,'
+    '"http_referer":"$http_referer","http_user_agent":"$http_user_agent",'
+    '"content_digest":"%s","request_id":"$request_id"}';`,
+		jsonLogFormat, name, namespace, contentDigestVariable())
+}
+
+// GenerateProbeLogMap generates the http-level variables selecting the probe requests that are logged
+// Returns an empty string if every request is logged or nothing is logged
+func (g *ConfigGenerator) GenerateProbeLogMap(policy AccessLogPolicy) string {
+	if !policy.logged() || policy.ProbeSamplePercent >= 100 {
+		return ""
+	}
+
+	probeValue := "0"
+	lines := []string{}
+	if policy.ProbeSamplePercent > 0 {
+		probeValue = probeSampleVariable
+		lines = append(lines,
+			fmt.Sprintf("split_clients $request_id %s {", probeSampleVariable),
+			fmt.Sprintf("    %d%% 1;", policy.ProbeSamplePercent),
+			"    * 0;",
+			"}",
+			"",
+		)
+	}
+	lines = append(lines,
+		fmt.Sprintf("map $http_user_agent %s {", accessLogVariable),
+		"    default 1;",
+		fmt.Sprintf(`    "~^%s" %s;`, config.ProbeUserAgentPrefix, probeValue),
+		"}",
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+// GenerateAccessLogBlock generates the server-level access logs from spec.server.accessLog
+// A server-level access_log replaces the inherited one, so the metrics exporter log is declared here as well
+func (g *ConfigGenerator) GenerateAccessLogBlock(policy AccessLogPolicy, metrics bool) string {
+	lines := []string{"    # Access log"}
+
+	if policy.logged() {
+		format := policy.Format
+		if format == config.AccessLogFormatJSON {
+			format = jsonLogFormat
+		}
+		line := fmt.Sprintf("    access_log %s %s", accessLogPath, format)
+		if policy.ProbeSamplePercent < 100 {
+			line += " if=" + accessLogVariable
+		}
+		lines = append(lines, line+";")
+	}
+
+	switch {
+	case metrics:
+		lines = append(lines, "    "+metricsAccessLog())
+	case !policy.logged():
+		lines = append(lines, "    access_log off;")
+	}
+
+	return strings.Join(lines, "\n")
+}
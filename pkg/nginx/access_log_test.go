@@ -0,0 +1,162 @@
+package nginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestResolveAccessLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *v1alpha1.AccessLogSpec
+		want    AccessLogPolicy
+		wantErr string
+	}{
+		{name: "image defaults", want: AccessLogPolicy{}},
+		{
+			name: "default format",
+			spec: &v1alpha1.AccessLogSpec{ProbeSamplePercent: 10},
+			want: AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatCombined, ProbeSamplePercent: 10},
+		},
+		{
+			name: "json",
+			spec: &v1alpha1.AccessLogSpec{Format: config.AccessLogFormatJSON, ProbeSamplePercent: 100},
+			want: AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatJSON, ProbeSamplePercent: 100},
+		},
+		{
+			name:    "unknown format",
+			spec:    &v1alpha1.AccessLogSpec{Format: "main"},
+			want:    AccessLogPolicy{Enabled: true, Format: "main"},
+			wantErr: "unknown access log format",
+		},
+		{
+			name:    "sample percent out of range",
+			spec:    &v1alpha1.AccessLogSpec{ProbeSamplePercent: 101},
+			want:    AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatCombined, ProbeSamplePercent: 101},
+			wantErr: "must be between 0 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := ResolveAccessLog(tt.spec)
+			if policy != tt.want {
+				t.Errorf("ResolveAccessLog() = %+v, want %+v", policy, tt.want)
+			}
+
+			err := policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateProbeLogMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      AccessLogPolicy
+		wantContain []string
+		wantMissing []string
+	}{
+		{name: "image defaults", policy: AccessLogPolicy{}},
+		{name: "every probe logged", policy: AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatCombined, ProbeSamplePercent: 100}},
+		{name: "log off", policy: AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatOff}},
+		{
+			name:   "probes not logged",
+			policy: AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatCombined},
+			wantContain: []string{
+				"map $http_user_agent " + accessLogVariable + " {",
+				`"~^` + config.ProbeUserAgentPrefix + `" 0;`,
+			},
+			wantMissing: []string{"split_clients"},
+		},
+		{
+			name:   "sampled probes",
+			policy: AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatJSON, ProbeSamplePercent: 5},
+			wantContain: []string{
+				"split_clients $request_id " + probeSampleVariable + " {\n    5% 1;\n    * 0;\n}",
+				`"~^` + config.ProbeUserAgentPrefix + `" ` + probeSampleVariable + ";",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := newTestGenerator().GenerateProbeLogMap(tt.policy)
+			if len(tt.wantContain) == 0 && block != "" {
+				t.Fatalf("GenerateProbeLogMap() = %q, want empty block", block)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(block, want) {
+					t.Errorf("probe log map does not contain %q:\n%s", want, block)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(block, unwanted) {
+					t.Errorf("probe log map contains %q:\n%s", unwanted, block)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateAccessLogBlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      AccessLogPolicy
+		metrics     bool
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name:        "combined with sampled probes",
+			policy:      AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatCombined, ProbeSamplePercent: 10},
+			wantContain: []string{"access_log " + accessLogPath + " combined if=" + accessLogVariable + ";"},
+			wantMissing: []string{"access_log off;", "syslog:"},
+		},
+		{
+			name:        "json with every probe",
+			policy:      AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatJSON, ProbeSamplePercent: 100},
+			wantContain: []string{"access_log " + accessLogPath + " " + jsonLogFormat + ";"},
+		},
+		{
+			name:        "off",
+			policy:      AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatOff},
+			wantContain: []string{"access_log off;"},
+			wantMissing: []string{accessLogPath},
+		},
+		{
+			name:        "off keeps the metrics exporter log",
+			policy:      AccessLogPolicy{Enabled: true, Format: config.AccessLogFormatOff},
+			metrics:     true,
+			wantContain: []string{metricsAccessLog()},
+			wantMissing: []string{"access_log off;", accessLogPath},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := newTestGenerator().GenerateAccessLogBlock(tt.policy, tt.metrics)
+			for _, want := range tt.wantContain {
+				if !strings.Contains(block, want) {
+					t.Errorf("access log block does not contain %q:\n%s", want, block)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(block, unwanted) {
+					t.Errorf("access log block contains %q:\n%s", unwanted, block)
+				}
+			}
+		})
+	}
+}
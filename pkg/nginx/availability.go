@@ -0,0 +1,273 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// Availability is the state of the server pods reported by the PodDisruptionBudget
+type Availability struct {
+	// Configured is false if spec.server.podDisruptionBudget is not set
+	Configured bool
+	// Observed is false until the disruption controller has processed the current PodDisruptionBudget
+	Observed bool
+	// CurrentHealthy is the number of healthy server pods
+	CurrentHealthy int32
+	// DesiredHealthy is the minimum number of healthy server pods
+	DesiredHealthy int32
+	// ExpectedPods is the number of server pods expected by the Deployment
+	ExpectedPods int32
+}
+
+// Met checks if the minimum availability of the PodDisruptionBudget is met
+func (a Availability) Met() bool {
+	return a.CurrentHealthy >= a.DesiredHealthy
+}
+
+// AvailabilityManager manages PodDisruptionBudget and HorizontalPodAutoscaler of the nginx Deployment
+type AvailabilityManager struct {
+	client client.Client
+}
+
+// NewAvailabilityManager creates a new availability manager
+func NewAvailabilityManager(client client.Client) *AvailabilityManager {
+	return &AvailabilityManager{
+		client: client,
+	}
+}
+
+// ValidateAvailability checks spec.server settings that cannot be expressed in the CRD schema
+func ValidateAvailability(server *v1alpha1.ServerSpec) error {
+	if server == nil {
+		return nil
+	}
+
+	if pdb := server.PodDisruptionBudget; pdb != nil && pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		return fmt.Errorf("podDisruptionBudget: only one of minAvailable and maxUnavailable can be set")
+	}
+
+	if autoscaling := server.Autoscaling; autoscaling != nil &&
+		autoscaling.MinReplicas != nil && *autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		return fmt.Errorf("autoscaling: minReplicas %d is greater than maxReplicas %d",
+			*autoscaling.MinReplicas, autoscaling.MaxReplicas)
+	}
+
+	return nil
+}
+
+// autoscalingMinReplicas returns the lower replica limit of spec.server.autoscaling
+func autoscalingMinReplicas(spec *v1alpha1.AutoscalingSpec) int32 {
+	if spec.MinReplicas != nil {
+		return *spec.MinReplicas
+	}
+	return config.DefaultAutoscalingMinReplicas
+}
+
+// buildPodDisruptionBudgetSpec builds the PodDisruptionBudget spec selecting the server pods
+// Without minAvailable and maxUnavailable one pod may be evicted at a time
+func buildPodDisruptionBudgetSpec(name string, spec *v1alpha1.PodDisruptionBudgetSpec) policyv1.PodDisruptionBudgetSpec {
+	pdbSpec := policyv1.PodDisruptionBudgetSpec{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: buildSelectorLabels(name),
+		},
+		MinAvailable:   spec.MinAvailable,
+		MaxUnavailable: spec.MaxUnavailable,
+	}
+
+	if pdbSpec.MinAvailable == nil && pdbSpec.MaxUnavailable == nil {
+		maxUnavailable := intstr.FromInt(config.DefaultPDBMaxUnavailable)
+		pdbSpec.MaxUnavailable = &maxUnavailable
+	}
+
+	return pdbSpec
+}
+
+// buildHorizontalPodAutoscalerSpec builds the HorizontalPodAutoscaler spec scaling the server Deployment
+// CPU utilization is used as the target if no target is set
+func buildHorizontalPodAutoscalerSpec(name string, spec *v1alpha1.AutoscalingSpec) autoscalingv2.HorizontalPodAutoscalerSpec {
+	minReplicas := autoscalingMinReplicas(spec)
+	hpaSpec := autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       GetDeploymentName(name),
+		},
+		MinReplicas: &minReplicas,
+		MaxReplicas: spec.MaxReplicas,
+	}
+
+	utilizationMetric := func(resource corev1.ResourceName, target int32) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: resource,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &target,
+				},
+			},
+		}
+	}
+
+	targetCPU := spec.TargetCPUUtilizationPercentage
+	if targetCPU == nil && spec.TargetMemoryUtilizationPercentage == nil {
+		defaultTarget := int32(config.DefaultAutoscalingTargetCPU)
+		targetCPU = &defaultTarget
+	}
+	if targetCPU != nil {
+		hpaSpec.Metrics = append(hpaSpec.Metrics, utilizationMetric(corev1.ResourceCPU, *targetCPU))
+	}
+	if spec.TargetMemoryUtilizationPercentage != nil {
+		hpaSpec.Metrics = append(hpaSpec.Metrics,
+			utilizationMetric(corev1.ResourceMemory, *spec.TargetMemoryUtilizationPercentage))
+	}
+
+	return hpaSpec
+}
+
+// isOperatorManaged checks if the object carries the operator labels for the JWKS server
+func isOperatorManaged(obj client.Object, name string) bool {
+	labels := obj.GetLabels()
+	return labels[config.LabelManagedBy] == config.LabelManagedByValue &&
+		labels[config.LabelJWKSConfig] == name
+}
+
+// EnsurePodDisruptionBudget creates, updates or deletes the PodDisruptionBudget of the server pods
+// A PodDisruptionBudget not created by the operator is never changed
+func (m *AvailabilityManager) EnsurePodDisruptionBudget(
+	ctx context.Context,
+	namespace, name string,
+	spec *v1alpha1.PodDisruptionBudgetSpec,
+) error {
+	pdb := &policyv1.PodDisruptionBudget{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	err := m.client.Get(ctx, key, pdb)
+	if apierrors.IsNotFound(err) {
+		if spec == nil {
+			return nil // Not configured, nothing to clean up
+		}
+		pdb = &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    buildLabels(name),
+			},
+			Spec: buildPodDisruptionBudgetSpec(name, spec),
+		}
+		return m.client.Create(ctx, pdb)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get PodDisruptionBudget: %w", err)
+	}
+
+	if !isOperatorManaged(pdb, name) {
+		if spec == nil {
+			return nil
+		}
+		return fmt.Errorf("PodDisruptionBudget %s exists and is not managed by the operator", name)
+	}
+
+	if spec == nil {
+		if err := m.client.Delete(ctx, pdb); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PodDisruptionBudget: %w", err)
+		}
+		return nil
+	}
+
+	desiredSpec := buildPodDisruptionBudgetSpec(name, spec)
+	if equality.Semantic.DeepEqual(pdb.Spec, desiredSpec) {
+		return nil
+	}
+	pdb.Spec = desiredSpec
+	return m.client.Update(ctx, pdb)
+}
+
+// EnsureHorizontalPodAutoscaler creates, updates or deletes the HorizontalPodAutoscaler of the server Deployment
+// A HorizontalPodAutoscaler not created by the operator is never changed
+func (m *AvailabilityManager) EnsureHorizontalPodAutoscaler(
+	ctx context.Context,
+	namespace, name string,
+	spec *v1alpha1.AutoscalingSpec,
+) error {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	err := m.client.Get(ctx, key, hpa)
+	if apierrors.IsNotFound(err) {
+		if spec == nil {
+			return nil // Not configured, nothing to clean up
+		}
+		hpa = &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    buildLabels(name),
+			},
+			Spec: buildHorizontalPodAutoscalerSpec(name, spec),
+		}
+		return m.client.Create(ctx, hpa)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get HorizontalPodAutoscaler: %w", err)
+	}
+
+	if !isOperatorManaged(hpa, name) {
+		if spec == nil {
+			return nil
+		}
+		return fmt.Errorf("HorizontalPodAutoscaler %s exists and is not managed by the operator", name)
+	}
+
+	if spec == nil {
+		if err := m.client.Delete(ctx, hpa); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete HorizontalPodAutoscaler: %w", err)
+		}
+		return nil
+	}
+
+	desiredSpec := buildHorizontalPodAutoscalerSpec(name, spec)
+	if equality.Semantic.DeepEqual(hpa.Spec, desiredSpec) {
+		return nil
+	}
+	hpa.Spec = desiredSpec
+	return m.client.Update(ctx, hpa)
+}
+
+// GetAvailability returns the availability of the server pods from the PodDisruptionBudget status
+func (m *AvailabilityManager) GetAvailability(ctx context.Context, namespace, name string) (Availability, error) {
+	pdb := &policyv1.PodDisruptionBudget{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	if err := m.client.Get(ctx, key, pdb); err != nil {
+		if apierrors.IsNotFound(err) {
+			return Availability{}, nil
+		}
+		return Availability{}, fmt.Errorf("failed to get PodDisruptionBudget: %w", err)
+	}
+
+	if !isOperatorManaged(pdb, name) {
+		return Availability{}, nil
+	}
+
+	return Availability{
+		Configured:     true,
+		Observed:       pdb.Status.ObservedGeneration >= pdb.Generation,
+		CurrentHealthy: pdb.Status.CurrentHealthy,
+		DesiredHealthy: pdb.Status.DesiredHealthy,
+		ExpectedPods:   pdb.Status.ExpectedPods,
+	}, nil
+}
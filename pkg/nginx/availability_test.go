@@ -0,0 +1,201 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+func TestValidateAvailability(t *testing.T) {
+	one := intstr.FromInt(1)
+	minReplicas := int32(3)
+
+	tests := []struct {
+		name    string
+		server  *v1alpha1.ServerSpec
+		wantErr string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "valid settings",
+			server: &v1alpha1.ServerSpec{
+				PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetSpec{MinAvailable: &one},
+				Autoscaling:         &v1alpha1.AutoscalingSpec{MinReplicas: &minReplicas, MaxReplicas: 3},
+			},
+		},
+		{
+			name: "both PodDisruptionBudget limits",
+			server: &v1alpha1.ServerSpec{
+				PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetSpec{MinAvailable: &one, MaxUnavailable: &one},
+			},
+			wantErr: "only one of minAvailable and maxUnavailable",
+		},
+		{
+			name: "minReplicas above maxReplicas",
+			server: &v1alpha1.ServerSpec{
+				Autoscaling: &v1alpha1.AutoscalingSpec{MinReplicas: &minReplicas, MaxReplicas: 2},
+			},
+			wantErr: "minReplicas 3 is greater than maxReplicas 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAvailability(tt.server)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateAvailability() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateAvailability() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildPodDisruptionBudgetSpec(t *testing.T) {
+	two := intstr.FromInt(2)
+	half := intstr.FromString("50%")
+
+	tests := []struct {
+		name               string
+		spec               *v1alpha1.PodDisruptionBudgetSpec
+		wantMinAvailable   *intstr.IntOrString
+		wantMaxUnavailable *intstr.IntOrString
+	}{
+		{
+			name:               "default allows one eviction",
+			spec:               &v1alpha1.PodDisruptionBudgetSpec{},
+			wantMaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+		},
+		{
+			name:             "minAvailable",
+			spec:             &v1alpha1.PodDisruptionBudgetSpec{MinAvailable: &two},
+			wantMinAvailable: &two,
+		},
+		{
+			name:               "maxUnavailable",
+			spec:               &v1alpha1.PodDisruptionBudgetSpec{MaxUnavailable: &half},
+			wantMaxUnavailable: &half,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := buildPodDisruptionBudgetSpec("jwks", tt.spec)
+
+			if !equalIntOrString(spec.MinAvailable, tt.wantMinAvailable) {
+				t.Errorf("minAvailable = %v, want %v", spec.MinAvailable, tt.wantMinAvailable)
+			}
+			if !equalIntOrString(spec.MaxUnavailable, tt.wantMaxUnavailable) {
+				t.Errorf("maxUnavailable = %v, want %v", spec.MaxUnavailable, tt.wantMaxUnavailable)
+			}
+			if spec.Selector == nil || len(spec.Selector.MatchLabels) == 0 {
+				t.Errorf("selector = %v, want the server pod labels", spec.Selector)
+			}
+		})
+	}
+}
+
+func TestBuildHorizontalPodAutoscalerSpec(t *testing.T) {
+	minReplicas, cpu, memory := int32(2), int32(60), int32(70)
+
+	tests := []struct {
+		name            string
+		spec            *v1alpha1.AutoscalingSpec
+		wantMinReplicas int32
+		wantMetrics     map[corev1.ResourceName]int32
+	}{
+		{
+			name:            "default CPU target",
+			spec:            &v1alpha1.AutoscalingSpec{MaxReplicas: 5},
+			wantMinReplicas: 1,
+			wantMetrics:     map[corev1.ResourceName]int32{corev1.ResourceCPU: 80},
+		},
+		{
+			name:            "memory target only",
+			spec:            &v1alpha1.AutoscalingSpec{MinReplicas: &minReplicas, MaxReplicas: 5, TargetMemoryUtilizationPercentage: &memory},
+			wantMinReplicas: 2,
+			wantMetrics:     map[corev1.ResourceName]int32{corev1.ResourceMemory: 70},
+		},
+		{
+			name: "CPU and memory targets",
+			spec: &v1alpha1.AutoscalingSpec{
+				MaxReplicas:                       5,
+				TargetCPUUtilizationPercentage:    &cpu,
+				TargetMemoryUtilizationPercentage: &memory,
+			},
+			wantMinReplicas: 1,
+			wantMetrics:     map[corev1.ResourceName]int32{corev1.ResourceCPU: 60, corev1.ResourceMemory: 70},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := buildHorizontalPodAutoscalerSpec("jwks", tt.spec)
+
+			if spec.ScaleTargetRef.Kind != "Deployment" || spec.ScaleTargetRef.Name != GetDeploymentName("jwks") {
+				t.Errorf("scaleTargetRef = %+v, want the server Deployment", spec.ScaleTargetRef)
+			}
+			if spec.MinReplicas == nil || *spec.MinReplicas != tt.wantMinReplicas {
+				t.Errorf("minReplicas = %v, want %d", spec.MinReplicas, tt.wantMinReplicas)
+			}
+			if spec.MaxReplicas != tt.spec.MaxReplicas {
+				t.Errorf("maxReplicas = %d, want %d", spec.MaxReplicas, tt.spec.MaxReplicas)
+			}
+
+			metrics := make(map[corev1.ResourceName]int32, len(spec.Metrics))
+			for _, metric := range spec.Metrics {
+				metrics[metric.Resource.Name] = *metric.Resource.Target.AverageUtilization
+			}
+			if !reflect.DeepEqual(metrics, tt.wantMetrics) {
+				t.Errorf("metrics = %v, want %v", metrics, tt.wantMetrics)
+			}
+		})
+	}
+}
+
+func TestBuildTopologySpreadConstraints(t *testing.T) {
+	selectorLabels := map[string]string{"app": "jwks"}
+	customLabels := map[string]string{"tier": "edge"}
+	zone := corev1.TopologySpreadConstraint{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone"}
+	host := corev1.TopologySpreadConstraint{
+		MaxSkew:       1,
+		TopologyKey:   "kubernetes.io/hostname",
+		LabelSelector: &metav1.LabelSelector{MatchLabels: customLabels},
+	}
+
+	constraints := buildTopologySpreadConstraints([]corev1.TopologySpreadConstraint{zone, host}, selectorLabels)
+	if len(constraints) != 2 {
+		t.Fatalf("constraints = %v, want 2", constraints)
+	}
+	for i, want := range []map[string]string{selectorLabels, customLabels} {
+		if constraints[i].LabelSelector == nil || !reflect.DeepEqual(constraints[i].LabelSelector.MatchLabels, want) {
+			t.Errorf("%s label selector = %v, want %v", constraints[i].TopologyKey, constraints[i].LabelSelector, want)
+		}
+	}
+	if zone.LabelSelector != nil {
+		t.Error("buildTopologySpreadConstraints() modified spec.server constraints")
+	}
+	if got := buildTopologySpreadConstraints(nil, selectorLabels); got != nil {
+		t.Errorf("buildTopologySpreadConstraints(nil) = %v, want nil", got)
+	}
+}
+
+// equalIntOrString compares optional IntOrString values
+func equalIntOrString(a, b *intstr.IntOrString) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,222 @@
+package nginx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwksserver"
+)
+
+// nginxContainerName is the name of the nginx server container
+const nginxContainerName = "nginx"
+
+// ErrBuiltinImageNotConfigured is returned for the built-in server without spec.server.image or nginx.builtinImage
+var ErrBuiltinImageNotConfigured = errors.New("built-in server image is not configured")
+
+// BuiltinServer checks if spec.server selects the built-in Go server instead of nginx
+func BuiltinServer(server *v1alpha1.ServerSpec) bool {
+	return server != nil && server.Type == config.ServerTypeBuiltin
+}
+
+// ValidateImage checks that the server image of spec.server is known
+// The built-in server image is not published with the operator, it is set in spec.server.image or nginx.builtinImage
+func (m *DeploymentManager) ValidateImage(server *v1alpha1.ServerSpec) error {
+	if BuiltinServer(server) && m.resolveImage(server) == "" {
+		return fmt.Errorf("%w: set spec.server.image or nginx.builtinImage in the operator config", ErrBuiltinImageNotConfigured)
+	}
+	return nil
+}
+
+// serverContainerName returns the name of the first pod container serving JWKS for spec.server
+func serverContainerName(server *v1alpha1.ServerSpec) string {
+	if BuiltinServer(server) {
+		return config.BuiltinContainerName
+	}
+	return nginxContainerName
+}
+
+// GenerateBuiltinConfig generates the built-in server config for the same settings as the nginx config
+// The server computes the ETag from the mounted jwks.json, so the config does not change with the JWKS content
+func (g *ConfigGenerator) GenerateBuiltinConfig(opts ConfigOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	cors := ResolveCORS(opts.CORS)
+	if err := cors.Validate(); err != nil {
+		return "", fmt.Errorf("invalid CORS policy: %w", err)
+	}
+
+	limits := ResolveRequestLimits(opts.RateLimit, opts.MaxBodySize)
+	if err := limits.Validate(); err != nil {
+		return "", fmt.Errorf("invalid request limits: %w", err)
+	}
+
+	accessLog := ResolveAccessLog(opts.AccessLog)
+	if err := accessLog.Validate(); err != nil {
+		return "", fmt.Errorf("invalid access log: %w", err)
+	}
+
+	serverConfig := jwksserver.Config{
+		JWKSPaths:    opts.JWKSPaths(),
+		CacheControl: g.CacheControl(opts.Cache),
+		CORS: jwksserver.CORSConfig{
+			Enabled:        cors.Enabled,
+			AnyOrigin:      cors.AnyOrigin,
+			Origins:        cors.Origins,
+			OriginPatterns: cors.OriginPatterns,
+			Methods:        cors.Methods,
+			Headers:        cors.Headers,
+			MaxAge:         cors.MaxAge,
+			Credentials:    cors.Credentials,
+		},
+		MaxBodySize: limits.MaxBodySize,
+	}
+	if accessLog.logged() {
+		serverConfig.AccessLog = &jwksserver.AccessLogConfig{
+			Format:             accessLog.Format,
+			ProbeSamplePercent: accessLog.ProbeSamplePercent,
+			Name:               opts.Name,
+			Namespace:          opts.Namespace,
+		}
+	}
+	if limits.Enabled() {
+		serverConfig.RateLimit = &jwksserver.RateLimitConfig{
+			RequestsPerSecond: limits.RequestsPerSecond,
+			Burst:             limits.Burst,
+			Status:            limits.Status,
+		}
+	}
+	if opts.Discovery {
+		serverConfig.DiscoveryPath = opts.DiscoveryPath()
+	}
+
+	data, err := json.MarshalIndent(serverConfig, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal built-in server config: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// builtinArgs returns the command line of the built-in server listening on the port
+// A tlsPort other than 0 adds the https listener with the mounted TLS Secret
+func builtinArgs(port, tlsPort int) []string {
+	args := []string{
+		"-config", filepath.Join(config.BuiltinConfigMountPath, config.ConfigMapKeyBuiltinConfig),
+		"-data-dir", config.JWKSDataMountPath,
+		"-listen-address", ":" + strconv.Itoa(port),
+		"-metrics-address", ":" + strconv.Itoa(config.ExporterPort),
+	}
+	if tlsPort > 0 {
+		args = append(args,
+			"-tls-listen-address", ":"+strconv.Itoa(tlsPort),
+			"-tls-cert-file", TLSCertificatePath,
+			"-tls-key-file", TLSKeyPath,
+		)
+	}
+	return args
+}
+
+// buildBuiltinContainer builds the built-in server container
+// It mounts the same ConfigMaps as nginx and exposes metrics itself, so no sidecars are needed
+func buildBuiltinContainer(image string, port, tlsPort int, readinessPath string) corev1.Container {
+	return corev1.Container{
+		Name:  config.BuiltinContainerName,
+		Image: image,
+		Args:  builtinArgs(port, tlsPort),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: safeIntToInt32(port),
+				Protocol:      corev1.ProtocolTCP,
+			},
+			{
+				Name:          config.MetricsPortName,
+				ContainerPort: config.ExporterPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      config.VolumeNameNginxConfig,
+				MountPath: config.BuiltinConfigMountPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      config.VolumeNameJWKSData,
+				MountPath: config.JWKSDataMountPath,
+				ReadOnly:  true,
+			},
+		},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: config.HealthCheckPath,
+					Port: intstr.FromInt(port),
+				},
+			},
+			InitialDelaySeconds: config.DefaultLivenessProbeInitialDelay,
+			PeriodSeconds:       config.DefaultLivenessProbePeriod,
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: readinessPath,
+					Port: intstr.FromInt(port),
+				},
+			},
+			InitialDelaySeconds: config.DefaultReadinessProbeInitialDelay,
+			PeriodSeconds:       config.DefaultReadinessProbePeriod,
+		},
+	}
+}
+
+// syncBuiltinArgs updates the command line of the built-in server container to the configured ports
+// Returns true if the Deployment was changed
+func syncBuiltinArgs(deployment *appsv1.Deployment, port, tlsPort int) bool {
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return false
+	}
+
+	desired := builtinArgs(port, tlsPort)
+	if equality.Semantic.DeepEqual(containers[0].Args, desired) {
+		return false
+	}
+	containers[0].Args = desired
+	return true
+}
+
+// syncServerType replaces the server containers if spec.server.type changed
+// The pod template is rebuilt from spec, so nginx sidecars are removed when switching to the built-in server
+// Returns true if the Deployment was changed
+func (m *DeploymentManager) syncServerType(
+	deployment *appsv1.Deployment,
+	nginxConfigMapName, jwksConfigMapName, endpoint string,
+	nginxResources *NginxResources,
+	server *v1alpha1.ServerSpec,
+) bool {
+	podSpec := &deployment.Spec.Template.Spec
+	if len(podSpec.Containers) > 0 && podSpec.Containers[0].Name == serverContainerName(server) {
+		return false
+	}
+
+	desired := m.createDeployment(deployment.Name, deployment.Namespace, nginxConfigMapName, jwksConfigMapName,
+		endpoint, nginxResources, server).Spec.Template
+	podSpec.Containers = desired.Spec.Containers
+	podSpec.Volumes = desired.Spec.Volumes
+	podSpec.SecurityContext = desired.Spec.SecurityContext
+	podSpec.ShareProcessNamespace = desired.Spec.ShareProcessNamespace
+	syncPrometheusAnnotations(&deployment.Spec.Template, MetricsEnabled(server))
+	return true
+}
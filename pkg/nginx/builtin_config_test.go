@@ -0,0 +1,205 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwksserver"
+)
+
+func TestGenerateBuiltinConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ConfigOptions
+		want    jwksserver.Config
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint},
+			want: jwksserver.Config{
+				JWKSPaths:    []string{config.JWKSEndpointPath, "/"},
+				CacheControl: "public, max-age=300",
+				CORS: jwksserver.CORSConfig{
+					Enabled:   true,
+					AnyOrigin: true,
+					Methods:   defaultCORSMethods,
+					Headers:   defaultCORSHeaders,
+				},
+				MaxBodySize: config.DefaultMaxBodySize,
+			},
+		},
+		{
+			name: "discovery and allow-list",
+			opts: ConfigOptions{
+				Endpoint:  "/keys",
+				Discovery: true,
+				CORS:      &v1alpha1.CORSSpec{AllowedOrigins: []string{"https://app.example.com"}},
+			},
+			want: jwksserver.Config{
+				JWKSPaths:     []string{"/keys", "/"},
+				DiscoveryPath: config.DiscoveryEndpointPath,
+				CacheControl:  "public, max-age=300",
+				CORS: jwksserver.CORSConfig{
+					Enabled: true,
+					Origins: []string{"https://app.example.com"},
+					Methods: defaultCORSMethods,
+					Headers: defaultCORSHeaders,
+				},
+				MaxBodySize: config.DefaultMaxBodySize,
+			},
+		},
+		{
+			name: "rate limit and body size",
+			opts: ConfigOptions{
+				Endpoint:    DefaultEndpoint,
+				RateLimit:   &v1alpha1.RateLimitSpec{RequestsPerSecond: 10, Burst: 20},
+				MaxBodySize: 64,
+			},
+			want: jwksserver.Config{
+				JWKSPaths:    []string{config.JWKSEndpointPath, "/"},
+				CacheControl: "public, max-age=300",
+				CORS: jwksserver.CORSConfig{
+					Enabled:   true,
+					AnyOrigin: true,
+					Methods:   defaultCORSMethods,
+					Headers:   defaultCORSHeaders,
+				},
+				RateLimit:   &jwksserver.RateLimitConfig{RequestsPerSecond: 10, Burst: 20, Status: config.DefaultRateLimitStatus},
+				MaxBodySize: 64,
+			},
+		},
+		{
+			name: "access log",
+			opts: ConfigOptions{
+				Endpoint:  DefaultEndpoint,
+				Name:      "auth",
+				Namespace: "default",
+				AccessLog: &v1alpha1.AccessLogSpec{Format: config.AccessLogFormatJSON, ProbeSamplePercent: 10},
+			},
+			want: jwksserver.Config{
+				JWKSPaths:    []string{config.JWKSEndpointPath, "/"},
+				CacheControl: "public, max-age=300",
+				CORS: jwksserver.CORSConfig{
+					Enabled:   true,
+					AnyOrigin: true,
+					Methods:   defaultCORSMethods,
+					Headers:   defaultCORSHeaders,
+				},
+				MaxBodySize: config.DefaultMaxBodySize,
+				AccessLog: &jwksserver.AccessLogConfig{
+					Format:             config.AccessLogFormatJSON,
+					ProbeSamplePercent: 10,
+					Name:               "auth",
+					Namespace:          "default",
+				},
+			},
+		},
+		{
+			name: "access log off",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint, AccessLog: &v1alpha1.AccessLogSpec{Format: config.AccessLogFormatOff}},
+			want: jwksserver.Config{
+				JWKSPaths:    []string{config.JWKSEndpointPath, "/"},
+				CacheControl: "public, max-age=300",
+				CORS: jwksserver.CORSConfig{
+					Enabled:   true,
+					AnyOrigin: true,
+					Methods:   defaultCORSMethods,
+					Headers:   defaultCORSHeaders,
+				},
+				MaxBodySize: config.DefaultMaxBodySize,
+			},
+		},
+		{
+			name:    "invalid CORS policy",
+			opts:    ConfigOptions{Endpoint: DefaultEndpoint, CORS: &v1alpha1.CORSSpec{AllowCredentials: true}},
+			wantErr: "invalid CORS policy",
+		},
+		{
+			name:    "invalid request limits",
+			opts:    ConfigOptions{Endpoint: DefaultEndpoint, RateLimit: &v1alpha1.RateLimitSpec{RequestsPerSecond: 1, Status: 200}},
+			wantErr: "invalid request limits",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := newTestGenerator().GenerateBuiltinConfig(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GenerateBuiltinConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateBuiltinConfig() unexpected error: %v", err)
+			}
+
+			got, err := jwksserver.ParseConfig([]byte(data))
+			if err != nil {
+				t.Fatalf("generated config is rejected by the server: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("GenerateBuiltinConfig() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncBuiltinArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		tlsPort     int
+		wantChanged bool
+	}{
+		{name: "up to date", args: builtinArgs(8080, 0)},
+		{name: "old port", args: builtinArgs(80, 0), wantChanged: true},
+		{name: "https enabled", args: builtinArgs(8080, 0), tlsPort: 8443, wantChanged: true},
+		{name: "https disabled", args: builtinArgs(8080, 8443), wantChanged: true},
+		{name: "https up to date", args: builtinArgs(8080, 8443), tlsPort: 8443},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{}
+			deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: config.BuiltinContainerName, Args: tt.args}}
+
+			if changed := syncBuiltinArgs(deployment, 8080, tt.tlsPort); changed != tt.wantChanged {
+				t.Errorf("syncBuiltinArgs() = %v, want %v", changed, tt.wantChanged)
+			}
+			want := builtinArgs(8080, tt.tlsPort)
+			if got := deployment.Spec.Template.Spec.Containers[0].Args; !reflect.DeepEqual(got, want) {
+				t.Errorf("args = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBuiltinArgsTLS(t *testing.T) {
+	args := strings.Join(builtinArgs(8080, 8443), " ")
+	for _, want := range []string{"-tls-listen-address :8443", "-tls-cert-file " + TLSCertificatePath, "-tls-key-file " + TLSKeyPath} {
+		if !strings.Contains(args, want) {
+			t.Errorf("builtinArgs() = %s, want %s", args, want)
+		}
+	}
+	if args := strings.Join(builtinArgs(8080, 0), " "); strings.Contains(args, "-tls-") {
+		t.Errorf("builtinArgs() without TLS = %s, want no TLS flags", args)
+	}
+}
+
+func TestServerContainerName(t *testing.T) {
+	if got := serverContainerName(nil); got != nginxContainerName {
+		t.Errorf("serverContainerName(nil) = %q, want %q", got, nginxContainerName)
+	}
+	builtin := &v1alpha1.ServerSpec{Type: config.ServerTypeBuiltin}
+	if got := serverContainerName(builtin); got != config.BuiltinContainerName {
+		t.Errorf("serverContainerName(builtin) = %q, want %q", got, config.BuiltinContainerName)
+	}
+}
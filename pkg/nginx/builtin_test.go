@@ -0,0 +1,72 @@
+package nginx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestDeploymentManagerValidateImage(t *testing.T) {
+	builtin := &v1alpha1.ServerSpec{Type: config.ServerTypeBuiltin}
+
+	tests := []struct {
+		name      string
+		config    *config.NginxConfig
+		server    *v1alpha1.ServerSpec
+		wantImage string
+		wantErr   bool
+	}{
+		{
+			name:      "nginx default image",
+			config:    &config.NginxConfig{},
+			server:    nil,
+			wantImage: config.DefaultNginxImage,
+		},
+		{
+			name:    "built-in server without image",
+			config:  &config.NginxConfig{},
+			server:  builtin,
+			wantErr: true,
+		},
+		{
+			name:    "built-in server without operator config",
+			config:  nil,
+			server:  builtin,
+			wantErr: true,
+		},
+		{
+			name:      "built-in server image from operator config",
+			config:    &config.NginxConfig{BuiltinImage: "registry.test/jwks-server:v1"},
+			server:    builtin,
+			wantImage: "registry.test/jwks-server:v1",
+		},
+		{
+			name:      "built-in server image from spec",
+			config:    &config.NginxConfig{},
+			server:    &v1alpha1.ServerSpec{Type: config.ServerTypeBuiltin, Image: "registry.test/jwks-server:v2"},
+			wantImage: "registry.test/jwks-server:v2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewDeploymentManager(nil, tt.config)
+
+			err := manager.ValidateImage(tt.server)
+			if tt.wantErr {
+				if !errors.Is(err, ErrBuiltinImageNotConfigured) {
+					t.Fatalf("ValidateImage() error = %v, want %v", err, ErrBuiltinImageNotConfigured)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateImage() unexpected error: %v", err)
+			}
+			if got := manager.resolveImage(tt.server); got != tt.wantImage {
+				t.Errorf("resolveImage() = %q, want %q", got, tt.wantImage)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package nginx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// notModifiedVariable is the nginx variable set to a file suffix if If-None-Match matches the JWKS ETag
+// The suffix makes try_files pass conditional requests to the deferred location
+const notModifiedVariable = "$jwks_not_modified"
+
+// CacheControl returns the Cache-Control header value for spec.cache
+// max-age falls back to the global cacheMaxAge
+func (g *ConfigGenerator) CacheControl(spec *v1alpha1.CacheSpec) string {
+	maxAge := g.cacheMaxAge
+	if spec != nil && spec.MaxAge != nil {
+		maxAge = int(*spec.MaxAge)
+	}
+
+	directives := []string{"public", fmt.Sprintf("max-age=%d", maxAge)}
+	if spec != nil && spec.StaleWhileRevalidate != nil {
+		directives = append(directives, fmt.Sprintf("stale-while-revalidate=%d", *spec.StaleWhileRevalidate))
+	}
+	if spec != nil && spec.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// ETag returns the strong ETag value for the JWKS content digest
+func ETag(digest string) string {
+	return `"` + digest + `"`
+}
+
+// JWKSSnapshotFile returns the nginx ConfigMap key of the jwks.json copy with the content digest
+// The copy and the config sending its ETag are refreshed together in one ConfigMap volume, so the ETag
+// always matches the served file; kubelet refreshes the JWKS ConfigMap volume independently
+func JWKSSnapshotFile(digest string) string {
+	return config.ConfigMapKeyJWKSSnapshotPrefix + digest + config.ConfigMapKeyJWKSSnapshotSuffix
+}
+
+// GenerateNotModifiedMap generates the http-level map matching If-None-Match against the JWKS ETag
+// Weak comparison is used, so W/ ETags of gzip-compressed responses match as well
+// Returns an empty string if the digest is not known
+func (g *ConfigGenerator) GenerateNotModifiedMap(digest string) string {
+	if digest == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`map $http_if_none_match %s {
+    default "";
+    "*" "%s";
+    '~"%s"' "%s";
+}`, notModifiedVariable, deferredSuffix, digest, deferredSuffix)
+}
+
+// GenerateCompressionBlock generates server-level gzip settings for JSON responses
+// nginx turns strong ETags into weak ones for compressed responses
+func (g *ConfigGenerator) GenerateCompressionBlock() string {
+	return `    # Compression
+    gzip on;
+    gzip_types application/json;
+    gzip_min_length 256;
+    gzip_vary on;`
+}
+
+// GenerateETagBlock generates the ETag and content digest headers of the JWKS location
+// The ETag is derived from the content digest, so it is identical on every replica
+// The location serves JWKSSnapshotFile, the file the digest was computed from
+// The digest header identifies the served key set in access logs
+func (g *ConfigGenerator) GenerateETagBlock(digest string) string {
+	if digest == "" {
+		return ""
+	}
+
+	return strings.Join([]string{
+		"        # Conditional GET",
+		"        etag off;",
+		"        " + etagHeader(digest),
+		"        " + digestHeader(digest),
+	}, "\n")
+}
+
+// GenerateNotModifiedBlock generates the 304 response of the deferred JWKS location
+// Headers added inside "if" replace the location headers, so they are repeated here
+func (g *ConfigGenerator) GenerateNotModifiedBlock(digest, cacheControl string, cors CORSPolicy) string {
+	if digest == "" {
+		return ""
+	}
+
+	lines := []string{"        # Conditional GET", "        if (" + notModifiedVariable + ") {",
+		"            " + etagHeader(digest), "            " + digestHeader(digest),
+		fmt.Sprintf(`            add_header Cache-Control "%s" always;`, cacheControl)}
+	for _, line := range corsHeaderLines(cors, false) {
+		lines = append(lines, "            "+line)
+	}
+	lines = append(lines, "            return 304;", "        }")
+
+	return strings.Join(lines, "\n")
+}
+
+// etagHeader returns the add_header directive of the JWKS ETag
+func etagHeader(digest string) string {
+	return fmt.Sprintf(`add_header ETag '%s' always;`, ETag(digest))
+}
+
+// digestHeader returns the add_header directive of the content digest header
+func digestHeader(digest string) string {
+	return fmt.Sprintf(`add_header %s "%s" always;`, config.HeaderContentDigest, digest)
+}
@@ -0,0 +1,184 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestCacheControl(t *testing.T) {
+	maxAge, stale := int32(60), int32(30)
+
+	tests := []struct {
+		name string
+		spec *v1alpha1.CacheSpec
+		want string
+	}{
+		{
+			name: "global max-age",
+			want: "public, max-age=300",
+		},
+		{
+			name: "per-JWKS max-age",
+			spec: &v1alpha1.CacheSpec{MaxAge: &maxAge},
+			want: "public, max-age=60",
+		},
+		{
+			name: "all directives",
+			spec: &v1alpha1.CacheSpec{MaxAge: &maxAge, StaleWhileRevalidate: &stale, MustRevalidate: true},
+			want: "public, max-age=60, stale-while-revalidate=30, must-revalidate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestGenerator().CacheControl(tt.spec); got != tt.want {
+				t.Errorf("CacheControl() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateETagBlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		digest      string
+		wantContain []string
+	}{
+		{
+			name: "unknown digest",
+		},
+		{
+			name:   "digest",
+			digest: "abc123",
+			wantContain: []string{
+				"etag off;",
+				`add_header ETag '"abc123"' always;`,
+				`add_header ` + config.HeaderContentDigest + ` "abc123" always;`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := newTestGenerator().GenerateETagBlock(tt.digest)
+			if len(tt.wantContain) == 0 && block != "" {
+				t.Fatalf("GenerateETagBlock() = %q, want empty block", block)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(block, want) {
+					t.Errorf("ETag block does not contain %q:\n%s", want, block)
+				}
+			}
+			if strings.Contains(block, "return 304;") {
+				t.Errorf("ETag block answers 304 before the rate limit:\n%s", block)
+			}
+		})
+	}
+}
+
+func TestGenerateNotModifiedBlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		digest      string
+		cors        CORSPolicy
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name: "unknown digest",
+		},
+		{
+			name:   "digest with CORS",
+			digest: "abc123",
+			cors:   ResolveCORS(nil),
+			wantContain: []string{
+				"if (" + notModifiedVariable + ") {",
+				`add_header ETag '"abc123"' always;`,
+				`add_header ` + config.HeaderContentDigest + ` "abc123" always;`,
+				`add_header Cache-Control "public" always;`,
+				`add_header Access-Control-Allow-Origin "*" always;`,
+				"return 304;",
+			},
+		},
+		{
+			name:        "digest without CORS",
+			digest:      "abc123",
+			wantContain: []string{"return 304;"},
+			wantMissing: []string{"Access-Control-"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := newTestGenerator().GenerateNotModifiedBlock(tt.digest, "public", tt.cors)
+			if len(tt.wantContain) == 0 && block != "" {
+				t.Fatalf("GenerateNotModifiedBlock() = %q, want empty block", block)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(block, want) {
+					t.Errorf("not modified block does not contain %q:\n%s", want, block)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(block, unwanted) {
+					t.Errorf("not modified block contains %q:\n%s", unwanted, block)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncJWKSSnapshots(t *testing.T) {
+	served, previous, next := JWKSSnapshotFile("d1"), JWKSSnapshotFile("d0"), JWKSSnapshotFile("d2")
+	servedConfig := "try_files /" + served + " =404;"
+
+	tests := []struct {
+		name        string
+		current     map[string]string
+		desired     map[string]string
+		want        map[string]string
+		wantChanged bool
+	}{
+		{
+			name:    "digest unknown",
+			current: map[string]string{config.ConfigMapKeyNginxConfig: servedConfig, served: "v1"},
+			desired: map[string]string{config.ConfigMapKeyNginxConfig: "try_files /jwks.json =404;"},
+			want:    map[string]string{config.ConfigMapKeyNginxConfig: servedConfig, served: "v1"},
+		},
+		{
+			name:    "up to date keeps the previous snapshot",
+			current: map[string]string{config.ConfigMapKeyNginxConfig: servedConfig, served: "v1", previous: "v0"},
+			desired: map[string]string{served: "v1"},
+			want:    map[string]string{config.ConfigMapKeyNginxConfig: servedConfig, served: "v1", previous: "v0"},
+		},
+		{
+			name:        "new content keeps the served snapshot only",
+			current:     map[string]string{config.ConfigMapKeyNginxConfig: servedConfig, served: "v1", previous: "v0"},
+			desired:     map[string]string{next: "v2"},
+			want:        map[string]string{config.ConfigMapKeyNginxConfig: servedConfig, served: "v1", next: "v2"},
+			wantChanged: true,
+		},
+		{
+			name:        "first snapshot",
+			current:     map[string]string{config.ConfigMapKeyNginxConfig: "try_files /jwks.json =404;"},
+			desired:     map[string]string{served: "v1"},
+			want:        map[string]string{config.ConfigMapKeyNginxConfig: "try_files /jwks.json =404;", served: "v1"},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := syncJWKSSnapshots(tt.current, tt.desired); changed != tt.wantChanged {
+				t.Errorf("syncJWKSSnapshots() = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(tt.current, tt.want) {
+				t.Errorf("data = %v, want %v", tt.current, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package nginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+var (
+	// ErrConfigTestPending is returned while nginx -t is still running against a changed config
+	// The served config is kept until the test passes
+	ErrConfigTestPending = errors.New("nginx config test is running")
+	// ErrConfigTestFailed is returned if nginx -t rejected the changed config
+	ErrConfigTestFailed = fmt.Errorf("%w: nginx -t failed", ErrInvalidConfig)
+)
+
+// configTestContainerName is the name of the container running nginx -t
+const configTestContainerName = "nginx-test"
+
+// ConfigTester checks a changed nginx config with nginx -t in a one-off Job before it is promoted
+// The config under test is written to a candidate ConfigMap, the server ConfigMap is not touched
+type ConfigTester struct {
+	client      client.Client
+	deployments *DeploymentManager
+}
+
+// NewConfigTester creates a new nginx config tester
+func NewConfigTester(client client.Client, deployments *DeploymentManager) *ConfigTester {
+	return &ConfigTester{
+		client:      client,
+		deployments: deployments,
+	}
+}
+
+// ConfigTestJobName returns the name of the Job running nginx -t for JWKS
+func ConfigTestJobName(jwksName string) string {
+	return jwksName + config.ConfigTestJobSuffix
+}
+
+// configCandidateName returns the name of the ConfigMap with the config under test
+func configCandidateName(jwksName string) string {
+	return jwksName + config.ConfigCandidateSuffix
+}
+
+// Test runs nginx -t against data and reports the result of the Job
+// Returns nil once the config passed, ErrConfigTestPending while the Job runs and ErrConfigTestFailed if it failed
+// A Job testing an older config is replaced
+func (t *ConfigTester) Test(
+	ctx context.Context,
+	namespace, jwksName string,
+	data map[string]string,
+	server *v1alpha1.ServerSpec,
+) error {
+	hash := computeConfigMapHash(&corev1.ConfigMap{Data: data})
+
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Namespace: namespace, Name: ConfigTestJobName(jwksName)}
+	err := t.client.Get(ctx, key, job)
+	if apierrors.IsNotFound(err) {
+		if err := t.ensureCandidate(ctx, namespace, jwksName, data); err != nil {
+			return err
+		}
+		if err := t.client.Create(ctx, t.buildJob(namespace, jwksName, hash, server)); err != nil {
+			return fmt.Errorf("failed to create nginx config test Job: %w", err)
+		}
+		return ErrConfigTestPending
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get nginx config test Job: %w", err)
+	}
+
+	if !isOperatorManaged(job, jwksName) {
+		return fmt.Errorf("job %s exists and is not managed by the operator", key.Name)
+	}
+
+	if job.Annotations[config.AnnotationConfigHash] != hash {
+		// The config changed since the Job was created, test the new one
+		if err := t.deleteJob(ctx, job); err != nil {
+			return err
+		}
+		return ErrConfigTestPending
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return t.Cleanup(ctx, namespace, jwksName)
+		case batchv1.JobFailed:
+			return fmt.Errorf("%w: see logs of Job %s/%s: %s", ErrConfigTestFailed, namespace, key.Name, condition.Message)
+		}
+	}
+
+	return ErrConfigTestPending
+}
+
+// Cleanup deletes the nginx -t Job and the candidate ConfigMap created by the operator
+func (t *ConfigTester) Cleanup(ctx context.Context, namespace, jwksName string) error {
+	job := &batchv1.Job{}
+	err := t.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ConfigTestJobName(jwksName)}, job)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get nginx config test Job: %w", err)
+	case isOperatorManaged(job, jwksName):
+		if err := t.deleteJob(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	candidate := &corev1.ConfigMap{}
+	err = t.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: configCandidateName(jwksName)}, candidate)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get nginx config candidate ConfigMap: %w", err)
+	case isOperatorManaged(candidate, jwksName):
+		if err := t.client.Delete(ctx, candidate); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete nginx config candidate ConfigMap: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteJob deletes the Job together with its pods
+func (t *ConfigTester) deleteJob(ctx context.Context, job *batchv1.Job) error {
+	err := t.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete nginx config test Job: %w", err)
+	}
+	return nil
+}
+
+// ensureCandidate writes the config under test to the candidate ConfigMap
+func (t *ConfigTester) ensureCandidate(ctx context.Context, namespace, jwksName string, data map[string]string) error {
+	candidate := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: configCandidateName(jwksName)}
+	err := t.client.Get(ctx, key, candidate)
+	if apierrors.IsNotFound(err) {
+		candidate = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: namespace,
+				Labels:    buildLabels(jwksName),
+			},
+			Data: data,
+		}
+		if err := t.client.Create(ctx, candidate); err != nil {
+			return fmt.Errorf("failed to create nginx config candidate ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get nginx config candidate ConfigMap: %w", err)
+	}
+
+	if !isOperatorManaged(candidate, jwksName) {
+		return fmt.Errorf("ConfigMap %s exists and is not managed by the operator", key.Name)
+	}
+
+	candidate.Data = data
+	if err := t.client.Update(ctx, candidate); err != nil {
+		return fmt.Errorf("failed to update nginx config candidate ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// buildJob builds the Job running nginx -t with the candidate ConfigMap mounted like in server pods
+// Pods do not get the app label, so Services, PodDisruptionBudgets and NetworkPolicies do not select them
+func (t *ConfigTester) buildJob(namespace, jwksName, hash string, server *v1alpha1.ServerSpec) *batchv1.Job {
+	backoffLimit := int32(0)
+	deadline := int64(config.ConfigTestDeadlineSeconds)
+	ttl := int32(config.ConfigTestTTLSeconds)
+
+	container := corev1.Container{
+		Name:      configTestContainerName,
+		Image:     t.deployments.resolveImage(server),
+		Command:   []string{"nginx", "-t"},
+		Resources: t.deployments.buildResourceRequirements(nil),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      config.VolumeNameNginxConfig,
+				MountPath: "/etc/nginx/conf.d",
+				ReadOnly:  true,
+			},
+		},
+	}
+	if server != nil && server.ImagePullPolicy != "" {
+		container.ImagePullPolicy = server.ImagePullPolicy
+	}
+
+	podSpec := corev1.PodSpec{
+		RestartPolicy: corev1.RestartPolicyNever,
+		Containers:    []corev1.Container{container},
+		Volumes: []corev1.Volume{
+			{
+				Name: config.VolumeNameNginxConfig,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: configCandidateName(jwksName)},
+					},
+				},
+			},
+		},
+	}
+	if server != nil {
+		podSpec.ImagePullSecrets = server.ImagePullSecrets
+	}
+	if tls := ServerTLS(server); tls != nil {
+		// nginx -t loads the certificate and key referenced by the https listener
+		podSpec.Volumes = append(podSpec.Volumes, tlsVolume(tls))
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, tlsVolumeMount())
+	}
+	applySecurityProfile(&podSpec, t.deployments.resolveSecurityProfile(server), true)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ConfigTestJobName(jwksName),
+			Namespace:   namespace,
+			Labels:      buildLabels(jwksName),
+			Annotations: map[string]string{config.AnnotationConfigHash: hash},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   &deadline,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						config.LabelJWKSConfig: jwksName,
+						config.LabelManagedBy:  config.LabelManagedByValue,
+					},
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package nginx
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestConfigTesterBuildJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		server         *v1alpha1.ServerSpec
+		wantImage      string
+		wantPullPolicy corev1.PullPolicy
+	}{
+		{
+			name:      "defaults",
+			wantImage: config.DefaultNginxImage,
+		},
+		{
+			name:           "spec.server image",
+			server:         &v1alpha1.ServerSpec{Image: "registry.test/nginx:2", ImagePullPolicy: corev1.PullAlways},
+			wantImage:      "registry.test/nginx:2",
+			wantPullPolicy: corev1.PullAlways,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := NewConfigTester(nil, NewDeploymentManager(nil, &config.NginxConfig{}))
+			job := tester.buildJob("default", "auth", "hash", tt.server)
+
+			if job.Name != ConfigTestJobName("auth") || job.Annotations[config.AnnotationConfigHash] != "hash" {
+				t.Errorf("job = %s %v, want %s with the config hash", job.Name, job.Annotations, ConfigTestJobName("auth"))
+			}
+			if _, ok := job.Spec.Template.Labels["app"]; ok {
+				t.Error("config test pods must not be selected by the server Service")
+			}
+
+			podSpec := job.Spec.Template.Spec
+			if podSpec.RestartPolicy != corev1.RestartPolicyNever {
+				t.Errorf("restartPolicy = %s, want Never", podSpec.RestartPolicy)
+			}
+			container := podSpec.Containers[0]
+			if container.Image != tt.wantImage || container.ImagePullPolicy != tt.wantPullPolicy {
+				t.Errorf("container = %s %s, want %s %s", container.Image, container.ImagePullPolicy, tt.wantImage, tt.wantPullPolicy)
+			}
+			if source := podSpec.Volumes[0].ConfigMap; source == nil || source.Name != configCandidateName("auth") {
+				t.Errorf("config volume = %+v, want the candidate ConfigMap", podSpec.Volumes[0])
+			}
+			if podSpec.SecurityContext == nil {
+				t.Error("config test pod must follow the restricted security profile")
+			}
+		})
+	}
+}
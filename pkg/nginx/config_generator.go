@@ -4,132 +4,282 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
 )
 
+// deferredSuffix is appended to the file name by the preflight and not-modified maps
+// No such file exists, so try_files passes the request to the deferred location after the rate limit
+const deferredSuffix = ".deferred"
+
 // ConfigGenerator generates nginx configuration for JWKS server
 type ConfigGenerator struct {
 	cacheMaxAge int
+	port        int
+	statusPort  int
+	tlsPort     int
 }
 
 // NewConfigGenerator creates a new nginx config generator
-func NewConfigGenerator(cacheMaxAge int) *ConfigGenerator {
+// port is the container port nginx listens on, statusPort is the internal port serving stub_status
+// and tlsPort is the container port of the https listener enabled by spec.server.tls
+func NewConfigGenerator(cacheMaxAge int, port int, statusPort int, tlsPort int) *ConfigGenerator {
 	return &ConfigGenerator{
 		cacheMaxAge: cacheMaxAge,
+		port:        port,
+		statusPort:  statusPort,
+		tlsPort:     tlsPort,
 	}
 }
 
+// ConfigOptions defines per-JWKS settings of the generated nginx configuration
+type ConfigOptions struct {
+	// Endpoint is the primary JWKS path
+	Endpoint string
+	// Paths are additional paths serving JWKS, DefaultPaths are used if nil
+	Paths []string
+	// PathPrefix mounts all JWKS and discovery paths under the prefix
+	PathPrefix string
+	// Discovery enables the OpenID Connect discovery document location
+	Discovery bool
+	// CORS is the CORS policy from spec.cors
+	CORS *v1alpha1.CORSSpec
+	// Cache defines the Cache-Control header from spec.cache
+	Cache *v1alpha1.CacheSpec
+	// ContentDigest is the digest of the served jwks.json, used as ETag
+	// nginx generates its own ETag if it is empty
+	ContentDigest string
+	// Content is jwks.json with ContentDigest, nginx serves a copy stored in its own ConfigMap,
+	// see JWKSSnapshotFile
+	Content string
+	// Metrics sends access log records to the metrics exporter sidecar
+	Metrics bool
+	// Builtin generates the built-in server config instead of the nginx config
+	Builtin bool
+	// TLS adds the https listener with the certificate from spec.server.tls
+	TLS bool
+	// RateLimit limits JWKS and discovery requests per client IP, from spec.server.rateLimit
+	RateLimit *v1alpha1.RateLimitSpec
+	// MaxBodySize is the largest accepted request body in bytes, config.DefaultMaxBodySize if 0
+	MaxBodySize int64
+	// AccessLog is the access log from spec.server.accessLog
+	AccessLog *v1alpha1.AccessLogSpec
+	// Name and Namespace identify the JWKS in access log records
+	Name      string
+	Namespace string
+	// Template is the user-supplied nginx config template and snippets from spec.server.template
+	Template ConfigTemplate
+}
+
 // GenerateConfig generates nginx configuration for JWKS endpoint
-func (g *ConfigGenerator) GenerateConfig(jwksConfigMapName string, endpoint string) (string, error) {
+// The config is rendered from the user-supplied template or DefaultConfigTemplate
+// JWKS is served at the configured paths only, every other path returns 404
+func (g *ConfigGenerator) GenerateConfig(jwksConfigMapName string, opts ConfigOptions) (string, error) {
 	if jwksConfigMapName == "" {
 		return "", fmt.Errorf("JWKS ConfigMap name cannot be empty")
 	}
 
-	endpoint = NormalizeEndpoint(endpoint)
-	if err := ValidateEndpoint(endpoint); err != nil {
-		return "", fmt.Errorf("invalid endpoint: %w", err)
+	data, err := g.TemplateData(opts)
+	if err != nil {
+		return "", err
 	}
 
-	// Generate location block that serves jwks.json for all paths
-	allPathsLocationBlock := g.GenerateAllPathsLocationBlock()
+	return renderTemplate(opts.Template.Text, data)
+}
 
-	// Generate server block with location block inside
-	config := g.GenerateServerBlockWithLocations(config.DefaultNginxPort, allPathsLocationBlock, "")
+// TemplateData builds the data model config templates are rendered with
+func (g *ConfigGenerator) TemplateData(opts ConfigOptions) (*TemplateData, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
 
-	return config, nil
-}
+	cors := ResolveCORS(opts.CORS)
+	if err := cors.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CORS policy: %w", err)
+	}
 
-// GenerateServerBlock generates nginx server block
-func (g *ConfigGenerator) GenerateServerBlock(port int) string {
-	return fmt.Sprintf(`server {
-    listen %d;
-    server_name _;
+	limits := ResolveRequestLimits(opts.RateLimit, opts.MaxBodySize)
+	if err := limits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request limits: %w", err)
+	}
 
-    root /usr/share/nginx/html;
-    index index.html;
+	accessLog := ResolveAccessLog(opts.AccessLog)
+	if err := accessLog.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid access log: %w", err)
+	}
 
-    # Security headers
-    add_header X-Content-Type-Options "nosniff" always;
-    add_header X-Frame-Options "DENY" always;
-    add_header X-XSS-Protection "1; mode=block" always;
-}`, port)
-}
+	snippets := opts.Template.Snippets
+	data := &TemplateData{
+		Name:          opts.Name,
+		Namespace:     opts.Namespace,
+		Port:          g.port,
+		StatusPort:    g.statusPort,
+		Root:          config.JWKSDataMountPath,
+		HealthPath:    config.HealthCheckPath,
+		JWKSFile:      config.ConfigMapKeyJWKS,
+		DiscoveryFile: config.ConfigMapKeyDiscovery,
+		JWKSPaths:     opts.JWKSPaths(),
+		CacheControl:  g.CacheControl(opts.Cache),
+		CORS:          cors,
+		Metrics:       opts.Metrics,
+		Snippets:      snippets,
+	}
+	if opts.ContentDigest != "" {
+		data.ETag = ETag(opts.ContentDigest)
+		data.ConfigRoot = nginxConfigMountPath
+		data.JWKSSnapshotFile = JWKSSnapshotFile(opts.ContentDigest)
+	}
+	if opts.TLS {
+		data.TLSPort = g.tlsPort
+		data.Blocks.TLS = g.GenerateTLSListenBlock()
+	}
 
-// GenerateServerBlockWithLocations generates nginx server block with location blocks inside
-func (g *ConfigGenerator) GenerateServerBlockWithLocations(port int, rootLocationBlock, jwksLocationBlock string) string {
-	config := fmt.Sprintf(`server {
-    listen %d;
-    server_name _;
+	// Maps and log formats belong to the http context, the config file is included there
+	for _, httpBlock := range []string{
+		g.GenerateCORSMap(cors), g.GenerateCORSPreflightMap(cors), g.GenerateNotModifiedMap(opts.ContentDigest),
+		g.GenerateRateLimitZone(limits),
+		g.GenerateJSONLogFormat(accessLog, opts.Name, opts.Namespace), g.GenerateProbeLogMap(accessLog),
+	} {
+		if httpBlock != "" {
+			data.Blocks.HTTP = append(data.Blocks.HTTP, httpBlock)
+		}
+	}
+	if opts.Metrics {
+		data.Blocks.HTTP = append(data.Blocks.HTTP, g.GenerateMetricsLogFormat())
+	}
+	switch {
+	case accessLog.Enabled:
+		data.Blocks.Server = append(data.Blocks.Server, g.GenerateAccessLogBlock(accessLog, opts.Metrics))
+	case opts.Metrics:
+		data.Blocks.Server = append(data.Blocks.Server, g.GenerateMetricsAccessLogBlock())
+	}
+	data.Blocks.Server = append(data.Blocks.Server, g.GenerateRequestLimitsBlock(limits), g.GenerateCompressionBlock())
 
-    root /usr/share/nginx/html;
+	data.Blocks.Health = g.GenerateHealthLocationBlock()
+	for _, path := range data.JWKSPaths {
+		data.Blocks.JWKSLocations = append(data.Blocks.JWKSLocations,
+			g.GenerateJWKSLocationBlock(path, cors, limits, data.CacheControl, opts.ContentDigest, snippets.Location))
+	}
+	if opts.Discovery {
+		data.DiscoveryPath = opts.DiscoveryPath()
+		data.Blocks.DiscoveryLocation = g.GenerateDiscoveryLocationBlock(data.DiscoveryPath, cors, limits,
+			data.CacheControl, snippets.Location)
+	}
+	data.Blocks.NotFound = g.GenerateNotFoundLocationBlock()
+	data.Blocks.StatusServer = g.GenerateStatusServerBlock()
 
-    # Security headers
-    add_header X-Content-Type-Options "nosniff" always;
-    add_header X-Frame-Options "DENY" always;
-    add_header X-XSS-Protection "1; mode=block" always;
+	return data, nil
+}
 
-%s`, port, rootLocationBlock)
+// GenerateHealthLocationBlock generates nginx location block for the liveness probe
+func (g *ConfigGenerator) GenerateHealthLocationBlock() string {
+	return fmt.Sprintf(`    location = %s {
+        access_log off;
+        default_type text/plain;
+        return 200 "ok\n";
+    }`, config.HealthCheckPath)
+}
 
-	if jwksLocationBlock != "" {
-		config += fmt.Sprintf("\n\n%s", jwksLocationBlock)
+// GenerateJWKSLocationBlock generates nginx exact-match location block serving jwks.json at the path
+// With a known digest the location serves JWKSSnapshotFile from the nginx ConfigMap, the file the ETag belongs to
+// snippet is the user-supplied location snippet, added at the end of the block
+func (g *ConfigGenerator) GenerateJWKSLocationBlock(
+	path string,
+	cors CORSPolicy,
+	limits RequestLimits,
+	cacheControl, digest, snippet string,
+) string {
+	file := config.ConfigMapKeyJWKS
+	if digest != "" {
+		file = JWKSSnapshotFile(digest)
 	}
+	return g.generateFileLocationBlock(path, file, cors, limits, cacheControl, digest, snippet)
+}
 
-	config += "\n}"
-	return config
+// GenerateDiscoveryLocationBlock generates nginx location block for the OpenID Connect discovery document
+// The discovery document keeps the ETag generated by nginx
+func (g *ConfigGenerator) GenerateDiscoveryLocationBlock(
+	path string,
+	cors CORSPolicy,
+	limits RequestLimits,
+	cacheControl, snippet string,
+) string {
+	return g.generateFileLocationBlock(path, config.ConfigMapKeyDiscovery, cors, limits, cacheControl, "", snippet)
 }
 
-// GenerateRootLocationBlock generates nginx location block for root path "/"
-func (g *ConfigGenerator) GenerateRootLocationBlock(jwksPath string) string {
-	return fmt.Sprintf(`    location = / {
-        default_type application/json;
-        alias %s;
-        
-        # CORS headers (if needed)
-        add_header Access-Control-Allow-Origin "*" always;
-        add_header Access-Control-Allow-Methods "GET, OPTIONS" always;
-        add_header Access-Control-Allow-Headers "Content-Type" always;
-        
-        # Cache control
-        add_header Cache-Control "public, max-age=%d" always;
-    }`, jwksPath, g.cacheMaxAge)
+// generateFileLocationBlock generates nginx exact-match location block serving a file from the JWKS ConfigMap,
+// or the JWKS snapshot from the nginx ConfigMap if digest is set
+// CORS preflight and 304 responses are returned by a deferred named location reached through try_files,
+// so they pass the rate limit like every other response: "return" in the location itself would run before limit_req
+func (g *ConfigGenerator) generateFileLocationBlock(
+	path, file string,
+	cors CORSPolicy,
+	limits RequestLimits,
+	cacheControl, digest, snippet string,
+) string {
+	deferred := deferredLocationName(path)
+	suffixes := ""
+	if cors.Enabled {
+		suffixes += corsPreflightVariable
+	}
+	if digest != "" {
+		suffixes += notModifiedVariable
+	}
+
+	tryFiles := fmt.Sprintf("        try_files /%s =404;", file)
+	if suffixes != "" {
+		tryFiles = fmt.Sprintf("        try_files /%s%s %s;", file, suffixes, deferred)
+	}
+
+	sections := []string{"        default_type application/json;\n" + tryFiles}
+	if digest != "" {
+		sections[0] = fmt.Sprintf("        root %s;\n", nginxConfigMountPath) + sections[0]
+	}
+	for _, section := range []string{g.GenerateRateLimitBlock(limits), g.GenerateCORSBlock(cors), g.GenerateETagBlock(digest)} {
+		if section != "" {
+			sections = append(sections, section)
+		}
+	}
+	sections = append(sections, fmt.Sprintf(`        # Cache control
+        add_header Cache-Control "%s" always;`, cacheControl))
+	if strings.TrimSpace(snippet) != "" {
+		sections = append(sections, "        # User snippet\n"+indent(8, snippet))
+	}
+
+	block := fmt.Sprintf("    location = %s {\n%s\n    }", path, strings.Join(sections, "\n        \n"))
+	if suffixes == "" {
+		return block
+	}
+
+	deferredSections := []string{}
+	for _, section := range []string{g.GenerateCORSPreflightBlock(cors), g.GenerateNotModifiedBlock(digest, cacheControl, cors)} {
+		if section != "" {
+			deferredSections = append(deferredSections, section)
+		}
+	}
+	deferredSections = append(deferredSections, "        return 404;")
+
+	return fmt.Sprintf("%s\n\n    location %s {\n%s\n    }", block, deferred, strings.Join(deferredSections, "\n        \n"))
 }
 
-// GenerateAllPathsLocationBlock generates nginx location block that serves jwks.json for all paths
-func (g *ConfigGenerator) GenerateAllPathsLocationBlock() string {
-	// Always return /jwks.json for any path (including root /)
-	// Use try_files to serve /jwks.json for all requests
-	// This ensures root path / also returns the file
-	return fmt.Sprintf(`    location / {
-        default_type application/json;
-        try_files /jwks.json =404;
-        
-        # CORS headers (if needed)
-        add_header Access-Control-Allow-Origin "*" always;
-        add_header Access-Control-Allow-Methods "GET, OPTIONS" always;
-        add_header Access-Control-Allow-Headers "Content-Type" always;
-        
-        # Cache control
-        add_header Cache-Control "public, max-age=%d" always;
-    }`, g.cacheMaxAge)
+// deferredLocationName returns the name of the named location answering preflight and 304 responses for the path
+// Characters other than letters and digits are hex-encoded, so distinct paths never share a name
+func deferredLocationName(path string) string {
+	var name strings.Builder
+	name.WriteString("@deferred")
+	for i := 0; i < len(path); i++ {
+		if isAlphanumeric(path[i]) {
+			name.WriteByte(path[i])
+			continue
+		}
+		fmt.Fprintf(&name, "_%02x", path[i])
+	}
+	return name.String()
 }
 
-// GenerateLocationBlock generates nginx location block for JWKS endpoint
-func (g *ConfigGenerator) GenerateLocationBlock(endpoint string, jwksPath string) string {
-	// Ensure jwksPath starts with /
-	if !strings.HasPrefix(jwksPath, "/") {
-		jwksPath = "/" + jwksPath
-	}
-
-	return fmt.Sprintf(`    location %s {
-        default_type application/json;
-        alias %s;
-        
-        # CORS headers (if needed)
-        add_header Access-Control-Allow-Origin "*" always;
-        add_header Access-Control-Allow-Methods "GET, OPTIONS" always;
-        add_header Access-Control-Allow-Headers "Content-Type" always;
-        
-        # Cache control
-        add_header Cache-Control "public, max-age=%d" always;
-    }`, endpoint, jwksPath, g.cacheMaxAge)
+// GenerateNotFoundLocationBlock generates nginx location block returning 404 for paths not configured
+func (g *ConfigGenerator) GenerateNotFoundLocationBlock() string {
+	return `    location / {
+        return 404;
+    }`
 }
@@ -0,0 +1,325 @@
+package nginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// newTestGenerator returns a config generator with fixed cache max-age and ports
+func newTestGenerator() *ConfigGenerator {
+	return NewConfigGenerator(300, 8080, 8081, 8443)
+}
+
+func TestGenerateConfig(t *testing.T) {
+	disabled := false
+	deferredJWKS := deferredLocationName(config.JWKSEndpointPath)
+
+	tests := []struct {
+		name        string
+		opts        ConfigOptions
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name: "default paths",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint},
+			wantContain: []string{
+				"listen 8080;",
+				"root " + config.JWKSDataMountPath + ";",
+				"location = " + config.HealthCheckPath + " {",
+				"location = " + config.JWKSEndpointPath + " {",
+				"location = / {",
+				"try_files /" + config.ConfigMapKeyJWKS + corsPreflightVariable + " " + deferredJWKS + ";",
+				"map $request_method " + corsPreflightVariable + " {",
+				"location " + deferredJWKS + " {",
+				"location / {\n        return 404;",
+				`add_header Access-Control-Allow-Origin "*" always;`,
+				`add_header Access-Control-Allow-Methods "GET, OPTIONS" always;`,
+				`add_header Cache-Control "public, max-age=300" always;`,
+				"gzip on;",
+				"client_max_body_size 1024;",
+				"if ($request_method !~ ^(GET|HEAD|OPTIONS)$) {\n        return 405;",
+				"listen 8081;",
+				"location = " + StubStatusPath + " {\n        stub_status;",
+			},
+			wantMissing: []string{
+				config.DiscoveryEndpointPath, "map $http_origin", "map $http_if_none_match", "etag off;", "log_format jwks_metrics", "ssl", "limit_req",
+			},
+		},
+		{
+			name: "discovery document",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint, Discovery: true},
+			wantContain: []string{
+				"location = " + config.DiscoveryEndpointPath + " {",
+				"try_files /" + config.ConfigMapKeyDiscovery + corsPreflightVariable + " " +
+					deferredLocationName(config.DiscoveryEndpointPath) + ";",
+			},
+		},
+		{
+			name: "endpoint, extra paths and prefix",
+			opts: ConfigOptions{
+				Endpoint:   "/.well-known/jwks.json",
+				Paths:      []string{"/keys", "/v1/keys"},
+				PathPrefix: "/auth/",
+				Discovery:  true,
+			},
+			wantContain: []string{
+				"location = /auth/.well-known/jwks.json {",
+				"location = /auth/keys {",
+				"location = /auth/v1/keys {",
+				"location = /auth" + config.DiscoveryEndpointPath + " {",
+			},
+			wantMissing: []string{"location = / {", "location = /auth/ {"},
+		},
+		{
+			name: "CORS allow-list",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				CORS: &v1alpha1.CORSSpec{
+					AllowedOrigins:        []string{"https://app.example.com"},
+					AllowedOriginPatterns: []string{`^https://.+\.example\.org$`},
+					AllowCredentials:      true,
+				},
+			},
+			wantContain: []string{
+				"map $http_origin " + corsOriginVariable + " {",
+				`"https://app.example.com" $http_origin;`,
+				`"~^https://.+\.example\.org$" $http_origin;`,
+				`add_header Access-Control-Allow-Origin "` + corsOriginVariable + `" always;`,
+				`add_header Access-Control-Allow-Credentials "true" always;`,
+				`add_header Vary "Origin" always;`,
+			},
+			wantMissing: []string{`Access-Control-Allow-Origin "*"`},
+		},
+		{
+			name: "ETag and cache settings",
+			opts: ConfigOptions{
+				Endpoint:      DefaultEndpoint,
+				Discovery:     true,
+				Cache:         &v1alpha1.CacheSpec{MustRevalidate: true},
+				ContentDigest: "abc123",
+			},
+			wantContain: []string{
+				"map $http_if_none_match " + notModifiedVariable + " {",
+				`'~"abc123"' "` + deferredSuffix + `";`,
+				"root " + nginxConfigMountPath + ";\n        default_type application/json;\n" +
+					"        try_files /" + JWKSSnapshotFile("abc123") + corsPreflightVariable + notModifiedVariable + " " + deferredJWKS + ";",
+				"try_files /" + config.ConfigMapKeyDiscovery + corsPreflightVariable + " ",
+				`add_header ETag '"abc123"' always;`,
+				`add_header Cache-Control "public, max-age=300, must-revalidate" always;`,
+			},
+		},
+		{
+			name: "metrics exporter",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint, Metrics: true},
+			wantContain: []string{
+				"log_format jwks_metrics",
+				"access_log syslog:server=" + config.ExporterSyslogAddress + ",tag=nginx jwks_metrics;",
+			},
+		},
+		{
+			name: "https listener",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint, TLS: true},
+			wantContain: []string{
+				"listen 8080;\n    listen 8443 ssl;",
+				"ssl_certificate " + TLSCertificatePath + ";",
+				"ssl_certificate_key " + TLSKeyPath + ";",
+				"ssl_protocols TLSv1.2 TLSv1.3;",
+				"ssl_session_tickets off;",
+			},
+		},
+		{
+			name: "rate limit",
+			opts: ConfigOptions{
+				Endpoint:    DefaultEndpoint,
+				Discovery:   true,
+				RateLimit:   &v1alpha1.RateLimitSpec{RequestsPerSecond: 10, Burst: 20, Status: 503},
+				MaxBodySize: 64,
+			},
+			wantContain: []string{
+				"limit_req_zone $binary_remote_addr zone=" + rateLimitZone + ":" + rateLimitZoneSize + " rate=10r/s;",
+				"client_max_body_size 64;",
+				"limit_req_status 503;",
+				"# Rate limit\n        limit_req zone=" + rateLimitZone + " burst=20 nodelay;",
+			},
+			wantMissing: []string{"location = " + config.HealthCheckPath + " {\n        # Rate limit"},
+		},
+		{
+			name: "JSON access log",
+			opts: ConfigOptions{
+				Endpoint:  DefaultEndpoint,
+				Name:      "auth",
+				Namespace: "default",
+				AccessLog: &v1alpha1.AccessLogSpec{Format: config.AccessLogFormatJSON, ProbeSamplePercent: 10},
+				Metrics:   true,
+			},
+			wantContain: []string{
+				"log_format " + jsonLogFormat + " escape=json",
+				`"jwks":"auth","namespace":"default",`,
+				"split_clients $request_id " + probeSampleVariable,
+				"access_log " + accessLogPath + " " + jsonLogFormat + " if=" + accessLogVariable + ";",
+				metricsAccessLog(),
+			},
+			wantMissing: []string{"combined;"},
+		},
+		{
+			name: "snippets",
+			opts: ConfigOptions{
+				Endpoint:  DefaultEndpoint,
+				Discovery: true,
+				Template: ConfigTemplate{Snippets: Snippets{
+					HTTP:     "limit_req_zone $binary_remote_addr zone=jwks:1m rate=10r/s;",
+					Server:   "client_max_body_size 1k;",
+					Location: `add_header Strict-Transport-Security "max-age=31536000" always;`,
+				}},
+			},
+			wantContain: []string{
+				"limit_req_zone $binary_remote_addr zone=jwks:1m rate=10r/s;\n\nserver {",
+				"    # User snippet\n    client_max_body_size 1k;",
+				"        add_header Strict-Transport-Security \"max-age=31536000\" always;\n",
+			},
+		},
+		{
+			name: "custom template",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				Template: ConfigTemplate{
+					Text: "server {\n    listen {{ .Port }};\n{{ range .Blocks.JWKSLocations }}{{ . }}\n{{ end }}}",
+				},
+			},
+			wantContain: []string{"server {\n    listen 8080;\n    location = " + config.JWKSEndpointPath + " {"},
+			wantMissing: []string{"listen 8081;", "X-Frame-Options"},
+		},
+		{
+			name: "CORS disabled",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				CORS:     &v1alpha1.CORSSpec{Enabled: &disabled},
+			},
+			wantContain: []string{"try_files /" + config.ConfigMapKeyJWKS + " =404;"},
+			wantMissing: []string{"Access-Control-", "$request_method = OPTIONS", "@deferred"},
+		},
+		{
+			name: "rate limit before deferred responses",
+			opts: ConfigOptions{
+				Endpoint:      DefaultEndpoint,
+				ContentDigest: "abc123",
+				RateLimit:     &v1alpha1.RateLimitSpec{RequestsPerSecond: 10},
+			},
+			wantContain: []string{
+				"limit_req zone=" + rateLimitZone + ";",
+				"try_files /" + JWKSSnapshotFile("abc123") + corsPreflightVariable + notModifiedVariable + " " + deferredJWKS + ";",
+				"location " + deferredJWKS + " {\n        # CORS preflight",
+				"return 304;\n        }\n        \n        return 404;\n    }",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generated, err := newTestGenerator().GenerateConfig("jwks", tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateConfig() unexpected error: %v", err)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(generated, want) {
+					t.Errorf("generated config does not contain %q:\n%s", want, generated)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(generated, unwanted) {
+					t.Errorf("generated config contains %q:\n%s", unwanted, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ConfigOptions
+		wantErr string
+	}{
+		{
+			name:    "invalid endpoint",
+			opts:    ConfigOptions{Endpoint: "/jwks json"},
+			wantErr: "invalid endpoint",
+		},
+		{
+			name:    "invalid request limits",
+			opts:    ConfigOptions{Endpoint: DefaultEndpoint, RateLimit: &v1alpha1.RateLimitSpec{RequestsPerSecond: 1, Status: 200}},
+			wantErr: "invalid request limits",
+		},
+		{
+			name:    "invalid access log",
+			opts:    ConfigOptions{Endpoint: DefaultEndpoint, AccessLog: &v1alpha1.AccessLogSpec{Format: "main"}},
+			wantErr: "invalid access log",
+		},
+		{
+			name: "template syntax error",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				Template: ConfigTemplate{Text: "{{ .Port "},
+			},
+			wantErr: ErrInvalidTemplate.Error(),
+		},
+		{
+			name: "unknown template field",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				Template: ConfigTemplate{Text: "{{ .Upstream }}"},
+			},
+			wantErr: ErrInvalidTemplate.Error(),
+		},
+		{
+			name: "empty rendered config",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				Template: ConfigTemplate{Text: "{{ if false }}server {}{{ end }}"},
+			},
+			wantErr: "empty config",
+		},
+		{
+			name: "invalid CORS policy",
+			opts: ConfigOptions{
+				Endpoint: DefaultEndpoint,
+				CORS:     &v1alpha1.CORSSpec{AllowCredentials: true},
+			},
+			wantErr: "invalid CORS policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestGenerator().GenerateConfig("jwks", tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GenerateConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeferredLocationName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "@deferred_2f"},
+		{path: "/keys", want: "@deferred_2fkeys"},
+		{path: "/.well-known/jwks.json", want: "@deferred_2f_2ewell_2dknown_2fjwks_2ejson"},
+		{path: "/a-b", want: "@deferred_2fa_2db"},
+		{path: "/a_2db", want: "@deferred_2fa_5f2db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := deferredLocationName(tt.path); got != tt.want {
+				t.Errorf("deferredLocationName(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
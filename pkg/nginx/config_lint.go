@@ -0,0 +1,260 @@
+package nginx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidConfig is returned if the generated nginx config fails validation
+// The served config is kept, the failure is reported in the NginxConfigInvalid condition
+var ErrInvalidConfig = errors.New("invalid nginx config")
+
+// lintToken is a word or a control character ({, } or ;) of an nginx config
+type lintToken struct {
+	value  string
+	line   int
+	quoted bool
+}
+
+// isControl checks if the token is an unquoted control character
+func (t lintToken) isControl(value string) bool {
+	return !t.quoted && t.value == value
+}
+
+// knownDirectives are the nginx directives accepted by the lint, in addition to knownDirectivePrefixes
+// The list covers the core HTTP modules of the official nginx images
+var knownDirectives = map[string]bool{
+	"absolute_redirect": true, "access_log": true, "add_header": true, "add_trailer": true, "aio": true,
+	"alias": true, "allow": true, "auth_basic": true, "auth_basic_user_file": true, "auth_request": true,
+	"auth_request_set": true, "autoindex": true, "break": true, "charset": true, "charset_types": true,
+	"chunked_transfer_encoding": true, "default_type": true, "deny": true, "directio": true, "error_log": true,
+	"error_page": true, "etag": true, "expires": true, "geo": true, "hash": true, "if": true,
+	"if_modified_since": true, "ignore_invalid_headers": true, "include": true, "index": true, "internal": true,
+	"ip_hash": true, "keepalive": true, "keepalive_requests": true, "keepalive_time": true,
+	"keepalive_timeout": true, "large_client_header_buffers": true, "least_conn": true, "lingering_close": true,
+	"lingering_time": true, "lingering_timeout": true, "listen": true, "location": true, "log_format": true,
+	"log_not_found": true, "log_subrequest": true, "map": true, "map_hash_bucket_size": true,
+	"map_hash_max_size": true, "max_ranges": true, "merge_slashes": true, "mirror": true, "msie_padding": true,
+	"open_file_cache": true, "open_file_cache_errors": true, "open_file_cache_min_uses": true,
+	"open_file_cache_valid": true, "open_log_file_cache": true, "output_buffers": true, "port_in_redirect": true,
+	"postpone_output": true, "recursive_error_pages": true, "reset_timedout_connection": true, "resolver": true,
+	"resolver_timeout": true, "return": true, "rewrite": true, "rewrite_log": true, "root": true,
+	"satisfy": true, "send_timeout": true, "sendfile": true, "sendfile_max_chunk": true, "server": true,
+	"server_name": true, "server_name_in_redirect": true, "server_names_hash_bucket_size": true,
+	"server_names_hash_max_size": true, "server_tokens": true, "set": true, "set_real_ip_from": true,
+	"split_clients": true, "stub_status": true, "sub_filter": true, "sub_filter_last_modified": true,
+	"sub_filter_once": true, "sub_filter_types": true, "tcp_nodelay": true, "tcp_nopush": true, "try_files": true,
+	"types": true, "types_hash_bucket_size": true, "types_hash_max_size": true, "underscores_in_headers": true,
+	"upstream": true, "valid_referers": true, "variables_hash_bucket_size": true, "variables_hash_max_size": true,
+	"zone": true,
+}
+
+// knownDirectivePrefixes are directive families accepted by the lint
+// Modules of the official images used by templates (fastcgi, uwsgi, grpc, njs, headers-more) are included
+var knownDirectivePrefixes = []string{
+	"client_", "fastcgi_", "grpc_", "gzip", "http2", "js_", "limit_", "more_", "proxy_", "real_ip_", "scgi_",
+	"secure_link", "ssi", "ssl_", "userid", "uwsgi_",
+}
+
+// freeformBlocks are blocks whose entries are values, not directives
+var freeformBlocks = map[string]bool{
+	"geo": true, "map": true, "split_clients": true, "types": true,
+}
+
+// isKnownDirective checks if the lint accepts the directive name
+func isKnownDirective(name string) bool {
+	if knownDirectives[name] {
+		return true
+	}
+	for _, prefix := range knownDirectivePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeConfig splits an nginx config into tokens, comments are dropped
+func tokenizeConfig(text string) ([]lintToken, error) {
+	var tokens []lintToken
+	line := 1
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case c == '{' || c == '}' || c == ';':
+			tokens = append(tokens, lintToken{value: string(c), line: line})
+			i++
+		case c == '"' || c == '\'':
+			start := line
+			var value strings.Builder
+			i++
+			for ; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' && i+1 < len(text) {
+					i++
+				}
+				if text[i] == '\n' {
+					line++
+				}
+				value.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", start)
+			}
+			i++
+			tokens = append(tokens, lintToken{value: value.String(), line: start, quoted: true})
+		default:
+			start := i
+			for i < len(text) && !strings.ContainsRune(" \t\r\n;{}\"'", rune(text[i])) {
+				// ${name} is a variable, not a block
+				if text[i] == '$' && i+1 < len(text) && text[i+1] == '{' {
+					end := i + 2
+					for end < len(text) && (text[end] == '_' || isAlphanumeric(text[end])) {
+						end++
+					}
+					if end < len(text) && text[end] == '}' {
+						i = end + 1
+						continue
+					}
+				}
+				i++
+			}
+			tokens = append(tokens, lintToken{value: text[start:i], line: line})
+		}
+	}
+
+	return tokens, nil
+}
+
+// isAlphanumeric checks if the byte is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// lintBlock is an open block of the config during the lint
+type lintBlock struct {
+	name      string
+	line      int
+	locations map[string]int
+}
+
+// LintConfig checks the structure of an nginx config included in the http context
+// Blocks must be balanced, directives known and locations unique within a server block
+// It does not replace nginx -t, which also checks arguments and contexts
+func LintConfig(text string) error {
+	return lintConfig(text, true)
+}
+
+// LintConfigStructure checks the structure of an nginx config like LintConfig but accepts any directive name
+// It is used for configs with user-supplied templates or snippets, which may use directives of any module
+// nginx loads; unknown directives are left to nginx -t
+func LintConfigStructure(text string) error {
+	return lintConfig(text, false)
+}
+
+// lintConfig checks the structure of an nginx config, directive names are checked if checkDirectives is set
+func lintConfig(text string, checkDirectives bool) error {
+	tokens, err := tokenizeConfig(text)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	stack := []*lintBlock{{name: "http"}}
+	var statement []lintToken
+
+	for _, token := range tokens {
+		current := stack[len(stack)-1]
+		switch {
+		case token.isControl(";"):
+			if len(statement) == 0 {
+				return fmt.Errorf("%w: line %d: unexpected \";\"", ErrInvalidConfig, token.line)
+			}
+			if err := lintDirective(current, statement, checkDirectives); err != nil {
+				return err
+			}
+			statement = nil
+		case token.isControl("{"):
+			if len(statement) == 0 {
+				return fmt.Errorf("%w: line %d: unexpected \"{\"", ErrInvalidConfig, token.line)
+			}
+			if err := lintDirective(current, statement, checkDirectives); err != nil {
+				return err
+			}
+			if statement[0].value == "location" {
+				if err := checkDuplicateLocation(stack, statement); err != nil {
+					return err
+				}
+			}
+			stack = append(stack, &lintBlock{name: statement[0].value, line: statement[0].line})
+			statement = nil
+		case token.isControl("}"):
+			if len(statement) > 0 {
+				return fmt.Errorf("%w: line %d: directive %q is not terminated by \";\"",
+					ErrInvalidConfig, statement[0].line, statement[0].value)
+			}
+			if len(stack) == 1 {
+				return fmt.Errorf("%w: line %d: unexpected \"}\"", ErrInvalidConfig, token.line)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			statement = append(statement, token)
+		}
+	}
+
+	if len(statement) > 0 {
+		return fmt.Errorf("%w: line %d: directive %q is not terminated by \";\"",
+			ErrInvalidConfig, statement[0].line, statement[0].value)
+	}
+	if len(stack) > 1 {
+		open := stack[len(stack)-1]
+		return fmt.Errorf("%w: line %d: block %q is not closed", ErrInvalidConfig, open.line, open.name)
+	}
+
+	return nil
+}
+
+// lintDirective checks the directive name unless the enclosing block holds values
+// Without checkDirectives only quoted names are rejected
+func lintDirective(block *lintBlock, statement []lintToken, checkDirectives bool) error {
+	if freeformBlocks[block.name] {
+		return nil
+	}
+	name := statement[0]
+	if name.quoted || (checkDirectives && !isKnownDirective(name.value)) {
+		return fmt.Errorf("%w: line %d: unknown directive %q", ErrInvalidConfig, name.line, name.value)
+	}
+	return nil
+}
+
+// checkDuplicateLocation checks that the location is not already defined in the enclosing server or location
+func checkDuplicateLocation(stack []*lintBlock, statement []lintToken) error {
+	args := make([]string, 0, len(statement)-1)
+	for _, token := range statement[1:] {
+		args = append(args, token.value)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%w: line %d: location without a path", ErrInvalidConfig, statement[0].line)
+	}
+
+	parent := stack[len(stack)-1]
+	if parent.locations == nil {
+		parent.locations = make(map[string]int)
+	}
+	key := strings.Join(args, " ")
+	if line, ok := parent.locations[key]; ok {
+		return fmt.Errorf("%w: line %d: duplicate location %q, first defined on line %d",
+			ErrInvalidConfig, statement[0].line, key, line)
+	}
+	parent.locations[key] = statement[0].line
+	return nil
+}
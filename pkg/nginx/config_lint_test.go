@@ -0,0 +1,152 @@
+package nginx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+func TestLintConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		wantErr          string
+		wantStructureErr string
+	}{
+		{
+			name: "generated server block",
+			config: `map $http_origin $jwks_cors_origin {
+    default "";
+    "https://app.example.com" $http_origin;
+}
+
+server {
+    listen 8080;
+    location = /.well-known/jwks.json {
+        limit_req zone=jwks burst=10 nodelay;
+        try_files /jwks.json =404;
+    }
+    location @deferred_2f {
+        if ($request_method = OPTIONS) { return 204; }
+        return 404;
+    }
+}`,
+		},
+		{
+			name:   "directive families of other modules",
+			config: "server {\n    fastcgi_read_timeout 5s;\n    grpc_read_timeout 5s;\n    uwsgi_read_timeout 5s;\n    ssi on;\n    userid off;\n    secure_link_secret s;\n    js_import main.js;\n    more_set_headers \"X-Test: 1\";\n}",
+		},
+		{
+			name:    "unknown directive",
+			config:  "server {\n    listen 8080;\n    lisen 8081;\n}",
+			wantErr: `line 3: unknown directive "lisen"`,
+		},
+		{
+			name:             "quoted directive",
+			config:           "server {\n    \"listen\" 8080;\n}",
+			wantErr:          "unknown directive",
+			wantStructureErr: "unknown directive",
+		},
+		{
+			name:             "unclosed block",
+			config:           "server {\n    listen 8080;\n",
+			wantErr:          `line 1: block "server" is not closed`,
+			wantStructureErr: "is not closed",
+		},
+		{
+			name:             "unexpected closing brace",
+			config:           "server {\n}\n}",
+			wantErr:          `line 3: unexpected "}"`,
+			wantStructureErr: `unexpected "}"`,
+		},
+		{
+			name:             "missing semicolon",
+			config:           "server {\n    listen 8080\n}",
+			wantErr:          `directive "listen" is not terminated`,
+			wantStructureErr: "is not terminated",
+		},
+		{
+			name:             "unterminated string",
+			config:           "server {\n    add_header X-Test \"1;\n}",
+			wantErr:          "line 2: unterminated quoted string",
+			wantStructureErr: "unterminated quoted string",
+		},
+		{
+			name:             "duplicate location",
+			config:           "server {\n    location /a { return 200; }\n    location /a { return 404; }\n}",
+			wantErr:          `line 3: duplicate location "/a", first defined on line 2`,
+			wantStructureErr: "duplicate location",
+		},
+		{
+			name:   "same location in different servers",
+			config: "server {\n    location /a { return 200; }\n}\nserver {\n    location /a { return 200; }\n}",
+		},
+		{
+			name:   "variable in braces",
+			config: "server {\n    add_header X-Test ${host}-x;\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLintError(t, "LintConfig", LintConfig(tt.config), tt.wantErr)
+			assertLintError(t, "LintConfigStructure", LintConfigStructure(tt.config), tt.wantStructureErr)
+		})
+	}
+}
+
+func TestLintGeneratedConfig(t *testing.T) {
+	maxAge := int32(60)
+
+	tests := []struct {
+		name string
+		opts ConfigOptions
+	}{
+		{
+			name: "defaults",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint},
+		},
+		{
+			name: "every feature",
+			opts: ConfigOptions{
+				Endpoint:      DefaultEndpoint,
+				Paths:         []string{"/keys"},
+				PathPrefix:    "/auth",
+				Discovery:     true,
+				CORS:          &v1alpha1.CORSSpec{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: &maxAge},
+				Cache:         &v1alpha1.CacheSpec{MaxAge: &maxAge, MustRevalidate: true},
+				ContentDigest: "abc123",
+				Metrics:       true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generated, err := newTestGenerator().GenerateConfig("jwks", tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateConfig() unexpected error: %v", err)
+			}
+			if err := LintConfig(generated); err != nil {
+				t.Errorf("LintConfig() rejected the generated config: %v\n%s", err, generated)
+			}
+		})
+	}
+}
+
+// assertLintError checks that err is nil if want is empty and an ErrInvalidConfig containing want otherwise
+func assertLintError(t *testing.T, lint string, err error, want string) {
+	t.Helper()
+
+	if want == "" {
+		if err != nil {
+			t.Errorf("%s() unexpected error: %v", lint, err)
+		}
+		return
+	}
+	if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), want) {
+		t.Errorf("%s() error = %v, want %v containing %q", lint, err, ErrInvalidConfig, want)
+	}
+}
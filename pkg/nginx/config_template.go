@@ -0,0 +1,239 @@
+package nginx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// ErrInvalidTemplate is returned if a user-supplied config template or snippet fails to render
+var ErrInvalidTemplate = errors.New("invalid nginx config template")
+
+// DefaultConfigTemplate is the built-in nginx config template
+// Custom templates from spec.server.template replace it and can reuse the rendered blocks
+const DefaultConfigTemplate = `{{- range .Blocks.HTTP }}{{ . }}
+
+{{ end -}}
+{{- with .Snippets.HTTP }}{{ indent 0 . }}
+
+{{ end -}}
+server {
+    listen {{ .Port }};
+{{- with .Blocks.TLS }}
+{{ . }}
+{{- end }}
+    server_name _;
+
+    root {{ .Root }};
+
+    # Security headers
+    add_header X-Content-Type-Options "nosniff" always;
+    add_header X-Frame-Options "DENY" always;
+    add_header X-XSS-Protection "1; mode=block" always;
+{{- with .Snippets.Server }}
+
+    # User snippet
+{{ indent 4 . }}
+{{- end }}
+{{- range .Blocks.Server }}
+
+{{ . }}
+{{- end }}
+
+{{ .Blocks.Health }}
+{{- range .Blocks.JWKSLocations }}
+
+{{ . }}
+{{- end }}
+{{- with .Blocks.DiscoveryLocation }}
+
+{{ . }}
+{{- end }}
+
+{{ .Blocks.NotFound }}
+}
+
+{{ .Blocks.StatusServer }}`
+
+// ConfigTemplate is a user-supplied nginx config template and snippets
+type ConfigTemplate struct {
+	// Text replaces DefaultConfigTemplate if not empty
+	Text string
+	// Snippets are injected into the built-in blocks
+	Snippets Snippets
+	// Hash identifies the template ConfigMap content, see TemplateHash
+	Hash string
+}
+
+// Custom checks if the template or any snippet is set
+func (t ConfigTemplate) Custom() bool {
+	return t.Text != "" || t.Snippets != Snippets{}
+}
+
+// Snippets are user-supplied nginx config fragments
+type Snippets struct {
+	// HTTP is added to the http context, next to the maps and log formats
+	HTTP string
+	// Server is added to the JWKS server block after the security headers
+	Server string
+	// Location is added to every JWKS and discovery location
+	// nginx does not inherit add_header into locations with their own add_header, so headers such as
+	// Strict-Transport-Security must be set here
+	Location string
+}
+
+// TemplateData is the data model nginx config templates are rendered with
+type TemplateData struct {
+	// Name and Namespace identify the JWKS resource
+	Name      string
+	Namespace string
+	// Port is the container port the JWKS server block listens on
+	Port int
+	// StatusPort is the internal port of the stub_status server block
+	StatusPort int
+	// TLSPort is the container port of the https listener, 0 if spec.server.tls is not set
+	TLSPort int
+	// Root is the directory the JWKS ConfigMap is mounted at
+	Root string
+	// HealthPath is the path of the liveness probe
+	HealthPath string
+	// JWKSFile and DiscoveryFile are the file names of the JWKS and the discovery document in Root
+	JWKSFile      string
+	DiscoveryFile string
+	// JWKSPaths are the exact paths serving the JWKS, the primary endpoint first
+	JWKSPaths []string
+	// DiscoveryPath is the path of the discovery document, empty if discovery is disabled
+	DiscoveryPath string
+	// CacheControl is the Cache-Control header value
+	CacheControl string
+	// ETag is the strong ETag of the served JWKS, empty until the content digest is known
+	ETag string
+	// ConfigRoot is the directory the nginx ConfigMap is mounted at
+	// JWKSSnapshotFile is the copy of JWKSFile in ConfigRoot the ETag belongs to, both are empty with ETag
+	ConfigRoot       string
+	JWKSSnapshotFile string
+	// CORS is the resolved CORS policy from spec.cors
+	CORS CORSPolicy
+	// Metrics is true if access log records are sent to the metrics exporter sidecar
+	Metrics bool
+	// Snippets are the user-supplied fragments
+	Snippets Snippets
+	// Blocks are the rendered parts of the built-in config
+	Blocks TemplateBlocks
+}
+
+// TemplateBlocks are rendered parts of the built-in config, custom templates can include them as is
+type TemplateBlocks struct {
+	// TLS is the https listener with the certificate settings, empty if spec.server.tls is not set
+	TLS string
+	// HTTP are the http-level maps, log formats, probe sampling and the rate limit zone
+	HTTP []string
+	// Server are the server-level access logs, request limits and compression settings
+	Server []string
+	// Health is the health check location
+	Health string
+	// JWKSLocations are the JWKS locations in JWKSPaths order, including the location snippet
+	JWKSLocations []string
+	// DiscoveryLocation is the discovery document location, empty if discovery is disabled
+	DiscoveryLocation string
+	// NotFound is the catch-all location returning 404
+	NotFound string
+	// StatusServer is the internal stub_status server block
+	StatusServer string
+}
+
+// templateFuncs are the functions available in config templates
+var templateFuncs = template.FuncMap{
+	"indent": indent,
+	"join":   strings.Join,
+}
+
+// indent indents every non-empty line of text by n spaces and trims the trailing newline
+func indent(n int, text string) string {
+	prefix := strings.Repeat(" ", n)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTemplate renders the nginx config from the custom template or DefaultConfigTemplate
+func renderTemplate(text string, data *TemplateData) (string, error) {
+	if text == "" {
+		text = DefaultConfigTemplate
+	}
+
+	tmpl, err := template.New(config.ConfigMapKeyNginxTemplate).
+		Funcs(templateFuncs).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	if strings.TrimSpace(out.String()) == "" {
+		return "", fmt.Errorf("%w: template rendered an empty config", ErrInvalidTemplate)
+	}
+
+	return out.String(), nil
+}
+
+// LoadTemplate reads the config template and snippets from the ConfigMap in spec.server.template
+// Every key is optional, a missing ConfigMap is an error so the served config is not silently replaced
+func (m *Manager) LoadTemplate(ctx context.Context, namespace string, spec *v1alpha1.ServerTemplateSpec) (ConfigTemplate, error) {
+	if spec == nil {
+		return ConfigTemplate{}, nil
+	}
+	if spec.ConfigMapName == "" {
+		return ConfigTemplate{}, fmt.Errorf("%w: spec.server.template.configMapName is required", ErrInvalidTemplate)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: spec.ConfigMapName}
+	if err := m.client.Get(ctx, key, configMap); err != nil {
+		return ConfigTemplate{}, fmt.Errorf("failed to get nginx template ConfigMap %s: %w", spec.ConfigMapName, err)
+	}
+
+	return ConfigTemplate{
+		Text: configMap.Data[config.ConfigMapKeyNginxTemplate],
+		Snippets: Snippets{
+			HTTP:     configMap.Data[config.ConfigMapKeyHTTPSnippet],
+			Server:   configMap.Data[config.ConfigMapKeyServerSnippet],
+			Location: configMap.Data[config.ConfigMapKeyLocationSnippet],
+		},
+		Hash: TemplateHash(configMap),
+	}, nil
+}
+
+// TemplateHash returns the hash of the template and snippet keys of a spec.server.template ConfigMap
+// Other keys of the ConfigMap are not used and don't change the hash
+func TemplateHash(configMap *corev1.ConfigMap) string {
+	data := make(map[string]string)
+	for _, key := range []string{
+		config.ConfigMapKeyNginxTemplate,
+		config.ConfigMapKeyHTTPSnippet,
+		config.ConfigMapKeyServerSnippet,
+		config.ConfigMapKeyLocationSnippet,
+	} {
+		if value, ok := configMap.Data[key]; ok {
+			data[key] = value
+		}
+	}
+	return computeConfigMapHash(&corev1.ConfigMap{Data: data})
+}
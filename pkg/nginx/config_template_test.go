@@ -0,0 +1,158 @@
+package nginx
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestTemplateHash(t *testing.T) {
+	base := &corev1.ConfigMap{Data: map[string]string{
+		config.ConfigMapKeyLocationSnippet: `add_header X-Test "1" always;`,
+	}}
+	baseHash := TemplateHash(base)
+
+	tests := []struct {
+		name     string
+		data     map[string]string
+		wantSame bool
+	}{
+		{
+			name:     "same content",
+			data:     map[string]string{config.ConfigMapKeyLocationSnippet: `add_header X-Test "1" always;`},
+			wantSame: true,
+		},
+		{
+			name: "unused key",
+			data: map[string]string{
+				config.ConfigMapKeyLocationSnippet: `add_header X-Test "1" always;`,
+				"README":                           "not used",
+			},
+			wantSame: true,
+		},
+		{
+			name: "changed snippet",
+			data: map[string]string{config.ConfigMapKeyLocationSnippet: `add_header X-Test "2" always;`},
+		},
+		{
+			name: "snippet moved to another key",
+			data: map[string]string{config.ConfigMapKeyServerSnippet: `add_header X-Test "1" always;`},
+		},
+		{
+			name: "added template",
+			data: map[string]string{
+				config.ConfigMapKeyLocationSnippet: `add_header X-Test "1" always;`,
+				config.ConfigMapKeyNginxTemplate:   "{{ .Blocks.StatusServer }}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TemplateHash(&corev1.ConfigMap{Data: tt.data})
+			if (got == baseHash) != tt.wantSame {
+				t.Errorf("TemplateHash() = %s, base %s, want same: %v", got, baseHash, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestSyncTemplateHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		hash        string
+		wantChanged bool
+		wantHash    string
+	}{
+		{
+			name:        "built-in template",
+			hash:        "",
+			wantChanged: false,
+		},
+		{
+			name:        "new template",
+			hash:        "abc",
+			wantChanged: true,
+			wantHash:    "abc",
+		},
+		{
+			name:        "unchanged template",
+			annotations: map[string]string{config.AnnotationTemplateHash: "abc"},
+			hash:        "abc",
+			wantHash:    "abc",
+		},
+		{
+			name:        "changed template",
+			annotations: map[string]string{config.AnnotationTemplateHash: "abc"},
+			hash:        "def",
+			wantChanged: true,
+			wantHash:    "def",
+		},
+		{
+			name:        "template removed",
+			annotations: map[string]string{config.AnnotationTemplateHash: "abc"},
+			hash:        "",
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+
+			if changed := syncTemplateHash(configMap, tt.hash); changed != tt.wantChanged {
+				t.Errorf("syncTemplateHash() = %v, want %v", changed, tt.wantChanged)
+			}
+			if got := configMap.Annotations[config.AnnotationTemplateHash]; got != tt.wantHash {
+				t.Errorf("%s = %q, want %q", config.AnnotationTemplateHash, got, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestIndent(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		text string
+		want string
+	}{
+		{name: "single line", n: 4, text: "gzip on;", want: "    gzip on;"},
+		{name: "trailing newline", n: 2, text: "a;\nb;\n", want: "  a;\n  b;"},
+		{name: "empty lines stay empty", n: 4, text: "a;\n\nb;", want: "    a;\n\n    b;"},
+		{name: "no indent", n: 0, text: "a;\n", want: "a;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indent(tt.n, tt.text); got != tt.want {
+				t.Errorf("indent(%d, %q) = %q, want %q", tt.n, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigTemplateCustom(t *testing.T) {
+	tests := []struct {
+		name     string
+		template ConfigTemplate
+		want     bool
+	}{
+		{name: "built-in template"},
+		{name: "hash only", template: ConfigTemplate{Hash: "abc"}},
+		{name: "custom template", template: ConfigTemplate{Text: "server {}"}, want: true},
+		{name: "snippet", template: ConfigTemplate{Snippets: Snippets{Server: "client_max_body_size 1k;"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.template.Custom(); got != tt.want {
+				t.Errorf("Custom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package nginx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+const (
+	// corsOriginVariable is the nginx variable holding the allowed origin of the request
+	corsOriginVariable = "$jwks_cors_origin"
+	// corsPreflightVariable is the nginx variable set to a file suffix for OPTIONS requests
+	// The suffix makes try_files pass preflight requests to the deferred location
+	corsPreflightVariable = "$jwks_cors_preflight"
+)
+
+var (
+	// defaultCORSMethods are allowed methods if spec.cors.allowedMethods is not set
+	defaultCORSMethods = []string{"GET", "OPTIONS"}
+	// defaultCORSHeaders are allowed headers if spec.cors.allowedHeaders is not set
+	defaultCORSHeaders = []string{"Content-Type"}
+
+	corsOriginPattern = regexp.MustCompile(`^https?://[A-Za-z0-9.\-]+(:[0-9]+)?$`)
+	corsTokenPattern  = regexp.MustCompile(`^[A-Za-z0-9\-]+$`)
+)
+
+// CORSPolicy is the CORS policy resolved from spec.cors with defaults applied
+type CORSPolicy struct {
+	// Enabled is false if CORS headers are not sent
+	Enabled bool
+	// AnyOrigin allows every origin with "*"
+	AnyOrigin bool
+	// Origins are allowed by exact match
+	Origins []string
+	// OriginPatterns are allowed by regular expression
+	OriginPatterns []string
+	// Methods are sent in Access-Control-Allow-Methods
+	Methods []string
+	// Headers are sent in Access-Control-Allow-Headers
+	Headers []string
+	// MaxAge is sent in Access-Control-Max-Age if set
+	MaxAge *int32
+	// Credentials sends Access-Control-Allow-Credentials
+	Credentials bool
+}
+
+// ResolveCORS resolves the CORS policy from spec.cors
+func ResolveCORS(spec *v1alpha1.CORSSpec) CORSPolicy {
+	if spec == nil {
+		return CORSPolicy{
+			Enabled:   true,
+			AnyOrigin: true,
+			Methods:   defaultCORSMethods,
+			Headers:   defaultCORSHeaders,
+		}
+	}
+
+	policy := CORSPolicy{
+		Enabled:        spec.Enabled == nil || *spec.Enabled,
+		OriginPatterns: spec.AllowedOriginPatterns,
+		Methods:        spec.AllowedMethods,
+		Headers:        spec.AllowedHeaders,
+		MaxAge:         spec.MaxAge,
+		Credentials:    spec.AllowCredentials,
+	}
+
+	for _, origin := range spec.AllowedOrigins {
+		if origin == "*" {
+			policy.AnyOrigin = true
+			continue
+		}
+		policy.Origins = append(policy.Origins, origin)
+	}
+	if len(policy.Origins) == 0 && len(policy.OriginPatterns) == 0 {
+		policy.AnyOrigin = true
+	}
+
+	if len(policy.Methods) == 0 {
+		policy.Methods = defaultCORSMethods
+	}
+	if len(policy.Headers) == 0 {
+		policy.Headers = defaultCORSHeaders
+	}
+
+	return policy
+}
+
+// Validate checks the policy for values that cannot be put into nginx configuration
+func (p CORSPolicy) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+
+	if p.AnyOrigin && p.Credentials {
+		return fmt.Errorf("allowCredentials cannot be used with the \"*\" origin")
+	}
+
+	for _, origin := range p.Origins {
+		if !corsOriginPattern.MatchString(origin) {
+			return fmt.Errorf("invalid allowed origin %q, expected scheme://host[:port]", origin)
+		}
+	}
+
+	for _, pattern := range p.OriginPatterns {
+		if pattern == "" || strings.ContainsAny(pattern, "\"'\n\r\t ") {
+			return fmt.Errorf("invalid allowed origin pattern %q", pattern)
+		}
+	}
+
+	for _, method := range p.Methods {
+		if !corsTokenPattern.MatchString(method) {
+			return fmt.Errorf("invalid allowed method %q", method)
+		}
+	}
+
+	for _, header := range p.Headers {
+		if !corsTokenPattern.MatchString(header) {
+			return fmt.Errorf("invalid allowed header %q", header)
+		}
+	}
+
+	return nil
+}
+
+// AllowOrigin returns the Access-Control-Allow-Origin value nginx sends for the origin
+// Patterns are matched with Go regular expressions, which agree with PCRE for common patterns
+// Returns an empty string if the origin is not allowed
+func (p CORSPolicy) AllowOrigin(origin string) string {
+	if !p.Enabled {
+		return ""
+	}
+	if p.AnyOrigin {
+		return "*"
+	}
+
+	for _, allowed := range p.Origins {
+		if allowed == origin {
+			return origin
+		}
+	}
+
+	for _, pattern := range p.OriginPatterns {
+		re, err := regexp.Compile(pattern)
+		if err == nil && re.MatchString(origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// originValue returns the nginx value of Access-Control-Allow-Origin
+func (p CORSPolicy) originValue() string {
+	if p.AnyOrigin {
+		return "*"
+	}
+	return corsOriginVariable
+}
+
+// GenerateCORSMap generates the http-level map resolving allowed origins
+// Returns an empty string if every origin is allowed or CORS is disabled
+func (g *ConfigGenerator) GenerateCORSMap(policy CORSPolicy) string {
+	if !policy.Enabled || policy.AnyOrigin {
+		return ""
+	}
+
+	lines := []string{
+		fmt.Sprintf("map $http_origin %s {", corsOriginVariable),
+		`    default "";`,
+	}
+	for _, origin := range policy.Origins {
+		lines = append(lines, fmt.Sprintf(`    "%s" $http_origin;`, origin))
+	}
+	for _, pattern := range policy.OriginPatterns {
+		lines = append(lines, fmt.Sprintf(`    "~%s" $http_origin;`, pattern))
+	}
+	lines = append(lines, "}")
+
+	return strings.Join(lines, "\n")
+}
+
+// corsHeaderLines returns add_header directives for the CORS policy
+func corsHeaderLines(policy CORSPolicy, preflight bool) []string {
+	if !policy.Enabled {
+		return nil
+	}
+
+	lines := []string{
+		fmt.Sprintf(`add_header Access-Control-Allow-Origin "%s" always;`, policy.originValue()),
+	}
+	if preflight {
+		lines = append(lines,
+			fmt.Sprintf(`add_header Access-Control-Allow-Methods "%s" always;`, strings.Join(policy.Methods, ", ")),
+			fmt.Sprintf(`add_header Access-Control-Allow-Headers "%s" always;`, strings.Join(policy.Headers, ", ")),
+		)
+		if policy.MaxAge != nil {
+			lines = append(lines, fmt.Sprintf(`add_header Access-Control-Max-Age "%s" always;`,
+				strconv.Itoa(int(*policy.MaxAge))))
+		}
+	}
+	if policy.Credentials {
+		lines = append(lines, `add_header Access-Control-Allow-Credentials "true" always;`)
+	}
+	if !policy.AnyOrigin {
+		lines = append(lines, `add_header Vary "Origin" always;`)
+	}
+
+	return lines
+}
+
+// GenerateCORSPreflightMap generates the http-level map deferring OPTIONS requests to the preflight response
+// Returns an empty string if CORS is disabled
+func (g *ConfigGenerator) GenerateCORSPreflightMap(policy CORSPolicy) string {
+	if !policy.Enabled {
+		return ""
+	}
+
+	return fmt.Sprintf(`map $request_method %s {
+    default "";
+    OPTIONS "%s";
+}`, corsPreflightVariable, deferredSuffix)
+}
+
+// GenerateCORSBlock generates the CORS headers of a location block
+func (g *ConfigGenerator) GenerateCORSBlock(policy CORSPolicy) string {
+	if !policy.Enabled {
+		return ""
+	}
+
+	lines := []string{"        # CORS headers"}
+	for _, line := range corsHeaderLines(policy, false) {
+		lines = append(lines, "        "+line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GenerateCORSPreflightBlock generates the OPTIONS preflight response of a deferred location
+// Headers added inside "if" replace the location headers, so preflight responses only carry CORS headers
+func (g *ConfigGenerator) GenerateCORSPreflightBlock(policy CORSPolicy) string {
+	if !policy.Enabled {
+		return ""
+	}
+
+	lines := []string{"        # CORS preflight", "        if ($request_method = OPTIONS) {"}
+	for _, line := range corsHeaderLines(policy, true) {
+		lines = append(lines, "            "+line)
+	}
+	lines = append(lines, "            return 204;", "        }")
+
+	return strings.Join(lines, "\n")
+}
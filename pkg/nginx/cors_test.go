@@ -0,0 +1,161 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+func TestResolveCORS(t *testing.T) {
+	disabled := false
+	maxAge := int32(600)
+
+	tests := []struct {
+		name string
+		spec *v1alpha1.CORSSpec
+		want CORSPolicy
+	}{
+		{
+			name: "defaults",
+			want: CORSPolicy{
+				Enabled:   true,
+				AnyOrigin: true,
+				Methods:   defaultCORSMethods,
+				Headers:   defaultCORSHeaders,
+			},
+		},
+		{
+			name: "disabled",
+			spec: &v1alpha1.CORSSpec{Enabled: &disabled},
+			want: CORSPolicy{
+				AnyOrigin: true,
+				Methods:   defaultCORSMethods,
+				Headers:   defaultCORSHeaders,
+			},
+		},
+		{
+			name: "wildcard among origins",
+			spec: &v1alpha1.CORSSpec{AllowedOrigins: []string{"*", "https://app.example.com"}},
+			want: CORSPolicy{
+				Enabled:   true,
+				AnyOrigin: true,
+				Origins:   []string{"https://app.example.com"},
+				Methods:   defaultCORSMethods,
+				Headers:   defaultCORSHeaders,
+			},
+		},
+		{
+			name: "allow-list",
+			spec: &v1alpha1.CORSSpec{
+				AllowedOriginPatterns: []string{`^https://.+\.example\.com$`},
+				AllowedMethods:        []string{"GET"},
+				AllowedHeaders:        []string{"Authorization"},
+				MaxAge:                &maxAge,
+				AllowCredentials:      true,
+			},
+			want: CORSPolicy{
+				Enabled:        true,
+				OriginPatterns: []string{`^https://.+\.example\.com$`},
+				Methods:        []string{"GET"},
+				Headers:        []string{"Authorization"},
+				MaxAge:         &maxAge,
+				Credentials:    true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveCORS(tt.spec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveCORS() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *v1alpha1.CORSSpec
+		wantErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "credentials with explicit origin",
+			spec: &v1alpha1.CORSSpec{AllowedOrigins: []string{"https://app.example.com:8443"}, AllowCredentials: true},
+		},
+		{
+			name:    "credentials with any origin",
+			spec:    &v1alpha1.CORSSpec{AllowCredentials: true},
+			wantErr: "allowCredentials",
+		},
+		{
+			name:    "origin with path",
+			spec:    &v1alpha1.CORSSpec{AllowedOrigins: []string{"https://app.example.com/login"}},
+			wantErr: "invalid allowed origin",
+		},
+		{
+			name:    "pattern with quote",
+			spec:    &v1alpha1.CORSSpec{AllowedOriginPatterns: []string{`^https://"`}},
+			wantErr: "invalid allowed origin pattern",
+		},
+		{
+			name:    "method with space",
+			spec:    &v1alpha1.CORSSpec{AllowedMethods: []string{"GET POST"}},
+			wantErr: "invalid allowed method",
+		},
+		{
+			name:    "header with semicolon",
+			spec:    &v1alpha1.CORSSpec{AllowedHeaders: []string{"X-Test;"}},
+			wantErr: "invalid allowed header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResolveCORS(tt.spec).Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCORSPolicyAllowOrigin(t *testing.T) {
+	disabled := false
+	allowList := &v1alpha1.CORSSpec{
+		AllowedOrigins:        []string{"https://app.example.com"},
+		AllowedOriginPatterns: []string{`^https://[a-z]+\.example\.org$`},
+	}
+
+	tests := []struct {
+		name   string
+		spec   *v1alpha1.CORSSpec
+		origin string
+		want   string
+	}{
+		{name: "any origin", origin: "https://other.example.net", want: "*"},
+		{name: "disabled", spec: &v1alpha1.CORSSpec{Enabled: &disabled}, origin: "https://app.example.com"},
+		{name: "exact match", spec: allowList, origin: "https://app.example.com", want: "https://app.example.com"},
+		{name: "pattern match", spec: allowList, origin: "https://auth.example.org", want: "https://auth.example.org"},
+		{name: "not allowed", spec: allowList, origin: "https://app.example.net"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveCORS(tt.spec).AllowOrigin(tt.origin); got != tt.want {
+				t.Errorf("AllowOrigin(%q) = %q, want %q", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,14 +5,15 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
 )
 
@@ -39,6 +40,7 @@ func (m *DeploymentManager) EnsureDeployment(
 	jwksConfigMapName string,
 	endpoint string,
 	nginxResources *NginxResources,
+	server *v1alpha1.ServerSpec,
 ) error {
 	deploymentName := jwksConfigName
 
@@ -49,7 +51,7 @@ func (m *DeploymentManager) EnsureDeployment(
 	err := m.client.Get(ctx, key, deployment)
 	if err == nil {
 		// Deployment exists, check if it needs update
-		return m.updateDeploymentIfNeeded(ctx, deployment, nginxConfigMapName, jwksConfigMapName, nginxResources)
+		return m.updateDeploymentIfNeeded(ctx, deployment, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources, server)
 	}
 
 	if !apierrors.IsNotFound(err) {
@@ -69,48 +71,97 @@ func (m *DeploymentManager) EnsureDeployment(
 		return fmt.Errorf("failed to get JWKS ConfigMap: %w", err)
 	}
 
+	tlsHash, err := m.tlsSecretHash(ctx, namespace, ServerTLS(server))
+	if err != nil {
+		return err
+	}
+
 	// Create new Deployment
-	deployment = m.createDeployment(deploymentName, namespace, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources)
+	deployment = m.createDeployment(deploymentName, namespace, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources, server)
+	syncTLS(&deployment.Spec.Template, ServerTLS(server), tlsPort(m.config), tlsHash)
 
-	// Set initial ConfigMap hashes in annotations
-	if deployment.Spec.Template.Annotations == nil {
-		deployment.Spec.Template.Annotations = make(map[string]string)
-	}
-	deployment.Spec.Template.Annotations[config.AnnotationNginxConfigMapHash] = computeConfigMapHash(nginxConfigMap)
-	deployment.Spec.Template.Annotations[config.AnnotationJWKSConfigMapHash] = computeConfigMapHash(jwksConfigMap)
+	// Record hashes of the ConfigMaps handed to the pods
+	setConfigMapHashes(deployment, nginxConfigMap, jwksConfigMap)
 
 	return m.client.Create(ctx, deployment)
 }
 
 // computeConfigMapHash computes SHA256 hash of ConfigMap data
+// Keys are sorted, so the hash only changes with the content
 func computeConfigMapHash(configMap *corev1.ConfigMap) string {
 	if configMap == nil {
 		return ""
 	}
 
-	// Combine all data and binary data into a single string for hashing
-	var dataStr string
-	if configMap.Data != nil {
-		for k, v := range configMap.Data {
-			dataStr += k + "=" + v + "\n"
-		}
+	hash := sha256.New()
+
+	keys := make([]string, 0, len(configMap.Data))
+	for k := range configMap.Data {
+		keys = append(keys, k)
 	}
-	if configMap.BinaryData != nil {
-		for k, v := range configMap.BinaryData {
-			dataStr += k + "=" + string(v) + "\n"
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", k, configMap.Data[k])
+	}
+
+	binaryKeys := make([]string, 0, len(configMap.BinaryData))
+	for k := range configMap.BinaryData {
+		binaryKeys = append(binaryKeys, k)
+	}
+	sort.Strings(binaryKeys)
+	for _, k := range binaryKeys {
+		fmt.Fprintf(hash, "%s=%s\n", k, configMap.BinaryData[k])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// setConfigMapHashes records ConfigMap hashes on the Deployment
+// Returns true if a hash was changed
+func setConfigMapHashes(deployment *appsv1.Deployment, nginxConfigMap, jwksConfigMap *corev1.ConfigMap) bool {
+	if deployment.Annotations == nil {
+		deployment.Annotations = make(map[string]string)
+	}
+
+	changed := false
+	hashes := map[string]string{
+		config.AnnotationNginxConfigMapHash: computeConfigMapHash(nginxConfigMap),
+		config.AnnotationJWKSConfigMapHash:  computeConfigMapHash(jwksConfigMap),
+	}
+	for key, hash := range hashes {
+		if deployment.Annotations[key] != hash {
+			deployment.Annotations[key] = hash
+			changed = true
 		}
 	}
+	return changed
+}
 
-	hash := sha256.Sum256([]byte(dataStr))
-	return hex.EncodeToString(hash[:])
+// removeRestartAnnotations removes pod template annotations older operator versions changed
+// on every ConfigMap update to restart pods
+// Returns true if the Deployment was changed
+func removeRestartAnnotations(deployment *appsv1.Deployment) bool {
+	changed := false
+	for _, key := range []string{
+		config.AnnotationNginxConfigMapHash,
+		config.AnnotationJWKSConfigMapHash,
+		config.AnnotationRestartedAt,
+	} {
+		if _, ok := deployment.Spec.Template.Annotations[key]; ok {
+			delete(deployment.Spec.Template.Annotations, key)
+			changed = true
+		}
+	}
+	return changed
 }
 
-// updateDeploymentIfNeeded updates Deployment if ConfigMaps changed
+// updateDeploymentIfNeeded updates Deployment if its settings changed
 func (m *DeploymentManager) updateDeploymentIfNeeded(
 	ctx context.Context,
 	deployment *appsv1.Deployment,
-	nginxConfigMapName, jwksConfigMapName string,
+	nginxConfigMapName, jwksConfigMapName, endpoint string,
 	nginxResources *NginxResources,
+	server *v1alpha1.ServerSpec,
 ) error {
 	needsUpdate := false
 
@@ -127,26 +178,16 @@ func (m *DeploymentManager) updateDeploymentIfNeeded(
 		return fmt.Errorf("failed to get JWKS ConfigMap: %w", err)
 	}
 
-	// Compute current hashes
-	nginxHash := computeConfigMapHash(nginxConfigMap)
-	jwksHash := computeConfigMapHash(jwksConfigMap)
-
-	// Initialize annotations if needed
-	if deployment.Spec.Template.Annotations == nil {
-		deployment.Spec.Template.Annotations = make(map[string]string)
+	tlsHash, err := m.tlsSecretHash(ctx, deployment.Namespace, ServerTLS(server))
+	if err != nil {
+		return err
 	}
 
-	// Check if ConfigMap hashes changed
-	annotationNginxHash := config.AnnotationNginxConfigMapHash
-	annotationJWKSHash := config.AnnotationJWKSConfigMapHash
+	// ConfigMap volumes are refreshed by kubelet in place: nginx reads jwks.json on every request
+	// and the reloader sidecar reloads the config, so content changes don't restart pods
+	hashesChanged := setConfigMapHashes(deployment, nginxConfigMap, jwksConfigMap)
 
-	if deployment.Spec.Template.Annotations[annotationNginxHash] != nginxHash {
-		deployment.Spec.Template.Annotations[annotationNginxHash] = nginxHash
-		needsUpdate = true
-	}
-
-	if deployment.Spec.Template.Annotations[annotationJWKSHash] != jwksHash {
-		deployment.Spec.Template.Annotations[annotationJWKSHash] = jwksHash
+	if removeRestartAnnotations(deployment) {
 		needsUpdate = true
 	}
 
@@ -168,33 +209,144 @@ func (m *DeploymentManager) updateDeploymentIfNeeded(
 		}
 	}
 
-	// Check if resources need update
-	if nginxResources != nil {
-		container := &deployment.Spec.Template.Spec.Containers[0]
-		newResources := m.buildResourceRequirements(nginxResources)
-		if !resourcesEqual(container.Resources, newResources) {
-			container.Resources = newResources
+	// Migrate JWKS data from a single-file subPath mount to a directory mount
+	if migrateJWKSDataMount(deployment) {
+		needsUpdate = true
+	}
+
+	// Server containers are rebuilt if spec.server.type changed
+	if m.syncServerType(deployment, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources, server) {
+		needsUpdate = true
+	}
+
+	// Readiness probe follows the configured JWKS path
+	if syncReadinessPath(deployment, endpoint) {
+		needsUpdate = true
+	}
+
+	// Container port and probes follow the nginx listen port
+	if syncContainerPort(deployment, containerPort(m.config)) {
+		needsUpdate = true
+	}
+
+	// Security contexts and scratch volumes follow the security profile
+	if m.syncSecurityProfile(deployment, server) {
+		needsUpdate = true
+	}
+
+	// TLS Secret volume and https port follow spec.server.tls, a renewed certificate rolls the pods
+	if syncTLS(&deployment.Spec.Template, ServerTLS(server), tlsPort(m.config), tlsHash) {
+		needsUpdate = true
+	}
+
+	if BuiltinServer(server) {
+		// The built-in server listens on the port from its command line and exports metrics itself
+		if syncBuiltinArgs(deployment, containerPort(m.config), m.serverTLSPort(server)) {
 			needsUpdate = true
 		}
+		if syncPrometheusAnnotations(&deployment.Spec.Template, MetricsEnabled(server)) {
+			needsUpdate = true
+		}
+	} else if m.syncNginxSidecars(deployment, server) {
+		needsUpdate = true
 	}
 
-	if needsUpdate {
-		// Trigger rolling update by updating annotation
-		deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = metav1.Now().Format("2006-01-02T15:04:05Z")
+	// Check if image, replicas, resources or scheduling settings need update
+	if m.syncServerOverrides(deployment, nginxResources, server) {
+		needsUpdate = true
+	}
+
+	// Pod template changes roll out on their own, hash changes only update the Deployment metadata
+	if needsUpdate || hashesChanged {
 		return m.client.Update(ctx, deployment)
 	}
 
 	return nil
 }
 
-// DeleteDeployment deletes nginx Deployment
+// syncNginxSidecars updates the reloader and metrics exporter sidecars and the status port of nginx pods
+// Returns true if the Deployment was changed
+func (m *DeploymentManager) syncNginxSidecars(deployment *appsv1.Deployment, server *v1alpha1.ServerSpec) bool {
+	changed := false
+
+	// Reloader sidecar follows the image and the security profile
+	if m.syncConfigReloader(deployment, server) {
+		changed = true
+	}
+
+	// Internal status port and the metrics exporter sidecar follow the config and spec.server.metrics
+	if syncStatusPort(deployment, statusPort(m.config)) {
+		changed = true
+	}
+	if applyMetricsExporter(&deployment.Spec.Template, MetricsEnabled(server),
+		m.resolveExporterImage(server), m.resolveSecurityProfile(server)) {
+		changed = true
+	}
+
+	return changed
+}
+
+// migrateJWKSDataMount updates JWKS data volume and mount created by older operator versions
+// Returns true if the Deployment was changed
+func migrateJWKSDataMount(deployment *appsv1.Deployment) bool {
+	changed := false
+
+	for i := range deployment.Spec.Template.Spec.Volumes {
+		vol := &deployment.Spec.Template.Spec.Volumes[i]
+		if vol.Name == config.VolumeNameJWKSData && vol.ConfigMap != nil && len(vol.ConfigMap.Items) > 0 {
+			vol.ConfigMap.Items = nil
+			changed = true
+		}
+	}
+
+	for i := range deployment.Spec.Template.Spec.Containers {
+		container := &deployment.Spec.Template.Spec.Containers[i]
+		for j := range container.VolumeMounts {
+			mount := &container.VolumeMounts[j]
+			if mount.Name != config.VolumeNameJWKSData {
+				continue
+			}
+			if mount.MountPath != config.JWKSDataMountPath || mount.SubPath != "" {
+				mount.MountPath = config.JWKSDataMountPath
+				mount.SubPath = ""
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// syncReadinessPath sets the readiness probe path of the nginx container
+// Returns true if the Deployment was changed
+func syncReadinessPath(deployment *appsv1.Deployment, endpoint string) bool {
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 0 || endpoint == "" {
+		return false
+	}
+
+	probe := containers[0].ReadinessProbe
+	if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Path == endpoint {
+		return false
+	}
+
+	probe.HTTPGet.Path = endpoint
+	return true
+}
+
+// DeleteDeployment deletes nginx Deployment, a Deployment not created by the operator is kept
 func (m *DeploymentManager) DeleteDeployment(ctx context.Context, namespace, jwksConfigName string) error {
-	deploymentName := jwksConfigName
-	deployment := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      deploymentName,
-			Namespace: namespace,
-		},
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Namespace: namespace, Name: GetDeploymentName(jwksConfigName)}
+	if err := m.client.Get(ctx, key, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil // Already deleted, nothing to do
+		}
+		return fmt.Errorf("failed to get nginx Deployment: %w", err)
+	}
+
+	if !isOperatorManaged(deployment, jwksConfigName) {
+		return nil // Not created by the operator, keep it
 	}
 
 	err := m.client.Delete(ctx, deployment)
@@ -8,9 +8,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
 )
 
+// nginxConfigMountPath is the directory nginx includes server configuration from
+const nginxConfigMountPath = "/etc/nginx/conf.d"
+
 // safeIntToInt32 safely converts int to int32, ensuring no overflow
 // Port values are always in safe range (0-65535), so this is safe
 //
@@ -30,10 +34,11 @@ func (m *DeploymentManager) buildContainerSpec(
 	port int,
 	_ string, // nginxConfigMapName - not used in container spec
 	_ string, // jwksConfigMapName - not used in container spec
+	readinessPath string,
 	nginxResources *NginxResources,
 ) corev1.Container {
 	return corev1.Container{
-		Name:    "nginx",
+		Name:    nginxContainerName,
 		Image:   image,
 		Command: []string{"nginx", "-g", "daemon off;"},
 		Ports: []corev1.ContainerPort{
@@ -42,6 +47,11 @@ func (m *DeploymentManager) buildContainerSpec(
 				ContainerPort: safeIntToInt32(port),
 				Protocol:      corev1.ProtocolTCP,
 			},
+			{
+				Name:          "status",
+				ContainerPort: safeIntToInt32(statusPort(m.config)),
+				Protocol:      corev1.ProtocolTCP,
+			},
 		},
 		VolumeMounts: m.buildVolumeMounts(),
 		Resources:    m.buildResourceRequirements(nginxResources),
@@ -58,7 +68,7 @@ func (m *DeploymentManager) buildContainerSpec(
 		ReadinessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
-					Path: config.JWKSEndpointPath,
+					Path: readinessPath,
 					Port: intstr.FromInt(port),
 				},
 			},
@@ -77,9 +87,10 @@ func (m *DeploymentManager) buildVolumeMounts() []corev1.VolumeMount {
 			ReadOnly:  true,
 		},
 		{
+			// The whole ConfigMap is mounted as a directory so jwks.json and the discovery document
+			// are served from the same place
 			Name:      config.VolumeNameJWKSData,
-			MountPath: "/usr/share/nginx/html/jwks.json",
-			SubPath:   config.ConfigMapKeyJWKS,
+			MountPath: config.JWKSDataMountPath,
 			ReadOnly:  true,
 		},
 	}
@@ -105,12 +116,6 @@ func (m *DeploymentManager) buildVolumes(nginxConfigMapName, jwksConfigMapName s
 					LocalObjectReference: corev1.LocalObjectReference{
 						Name: jwksConfigMapName,
 					},
-					Items: []corev1.KeyToPath{
-						{
-							Key:  config.ConfigMapKeyJWKS,
-							Path: config.ConfigMapKeyJWKS,
-						},
-					},
 				},
 			},
 		},
@@ -134,34 +139,55 @@ func buildSelectorLabels(name string) map[string]string {
 	}
 }
 
+// SelectorLabels returns the labels selecting nginx pods of the JWKS
+func SelectorLabels(jwksName string) map[string]string {
+	return buildSelectorLabels(jwksName)
+}
+
 // createDeployment creates a new nginx Deployment spec
+// spec.server.type selects nginx or the built-in server container
+// Settings from spec.server are merged over the global nginx config
+// endpoint is the JWKS path checked by the readiness probe
 func (m *DeploymentManager) createDeployment(
-	name, namespace, nginxConfigMapName, jwksConfigMapName, _ string,
+	name, namespace, nginxConfigMapName, jwksConfigMapName, endpoint string,
 	nginxResources *NginxResources,
+	server *v1alpha1.ServerSpec,
 ) *appsv1.Deployment {
 	// Get configuration values with defaults
-	image := config.DefaultNginxImage
+	image := m.resolveImage(server)
 	port := config.DefaultNginxPort
-	replicas := int32(config.DefaultNginxReplicas)
-
-	if m.config != nil {
-		if m.config.Image != "" {
-			image = m.config.Image
-		}
-		if m.config.Port > 0 {
-			port = m.config.Port
-		}
-		if m.config.Replicas > 0 {
-			replicas = m.config.Replicas
-		}
+	replicas := m.resolveReplicas(server)
+
+	if m.config != nil && m.config.Port > 0 {
+		port = m.config.Port
 	}
 
-	container := m.buildContainerSpec(name, image, port, nginxConfigMapName, jwksConfigMapName, nginxResources)
+	builtin := BuiltinServer(server)
+	var container corev1.Container
+	if builtin {
+		container = buildBuiltinContainer(image, port, m.serverTLSPort(server), endpoint)
+	} else {
+		container = m.buildContainerSpec(name, image, port, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources)
+	}
+	container.Resources = m.resolveResources(nginxResources, server)
+	if server != nil && server.ImagePullPolicy != "" {
+		container.ImagePullPolicy = server.ImagePullPolicy
+	}
 	volumes := m.buildVolumes(nginxConfigMapName, jwksConfigMapName)
 	labels := buildLabels(name)
 	selectorLabels := buildSelectorLabels(name)
 
-	return &appsv1.Deployment{
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{container},
+		Volumes:    volumes,
+	}
+	applyPodOverrides(&podSpec, server, selectorLabels)
+	applySecurityProfile(&podSpec, m.resolveSecurityProfile(server), !builtin)
+	if !builtin {
+		applyConfigReloader(&podSpec, image, m.resolveSecurityProfile(server))
+	}
+
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -176,11 +202,17 @@ func (m *DeploymentManager) createDeployment(
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: selectorLabels,
 				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{container},
-					Volumes:    volumes,
-				},
+				Spec: podSpec,
 			},
 		},
 	}
+	if builtin {
+		// The built-in server exports metrics itself, only the scrape annotations are added
+		syncPrometheusAnnotations(&deployment.Spec.Template, MetricsEnabled(server))
+	} else {
+		applyMetricsExporter(&deployment.Spec.Template, MetricsEnabled(server),
+			m.resolveExporterImage(server), m.resolveSecurityProfile(server))
+	}
+
+	return deployment
 }
@@ -0,0 +1,176 @@
+package nginx
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// resolveImage returns the server image from spec.server or the global config
+// The built-in server has no default image, the result is empty unless nginx.builtinImage is set
+func (m *DeploymentManager) resolveImage(server *v1alpha1.ServerSpec) string {
+	if server != nil && server.Image != "" {
+		return server.Image
+	}
+	if BuiltinServer(server) {
+		if m.config != nil {
+			return m.config.BuiltinImage
+		}
+		return ""
+	}
+	if m.config != nil && m.config.Image != "" {
+		return m.config.Image
+	}
+	return config.DefaultNginxImage
+}
+
+// resolveReplicas returns the number of replicas from spec.server or the global config
+// With autoscaling the Deployment starts with the lower replica limit
+func (m *DeploymentManager) resolveReplicas(server *v1alpha1.ServerSpec) int32 {
+	if server != nil && server.Autoscaling != nil {
+		return autoscalingMinReplicas(server.Autoscaling)
+	}
+	if server != nil && server.Replicas != nil {
+		return *server.Replicas
+	}
+	if m.config != nil && m.config.Replicas > 0 {
+		return m.config.Replicas
+	}
+	return int32(config.DefaultNginxReplicas)
+}
+
+// resolveResources merges spec.server resources over the global resources per resource name
+// When the merged request exceeds the limit of the same resource, the inherited value follows the
+// explicit one: an explicit request raises the global limit and an explicit limit lowers the global
+// request. Values from the same source are capped at the limit, the API server would reject the
+// pod template otherwise
+func (m *DeploymentManager) resolveResources(nginxResources *NginxResources, server *v1alpha1.ServerSpec) corev1.ResourceRequirements {
+	resources := m.buildResourceRequirements(nginxResources)
+	if server == nil || server.Resources == nil {
+		return resources
+	}
+
+	for name, quantity := range server.Resources.Requests {
+		resources.Requests[name] = quantity
+	}
+	for name, quantity := range server.Resources.Limits {
+		resources.Limits[name] = quantity
+	}
+	for name, request := range resources.Requests {
+		limit, ok := resources.Limits[name]
+		if !ok || request.Cmp(limit) <= 0 {
+			continue
+		}
+		_, explicitRequest := server.Resources.Requests[name]
+		_, explicitLimit := server.Resources.Limits[name]
+		if explicitRequest && !explicitLimit {
+			resources.Limits[name] = request
+			continue
+		}
+		resources.Requests[name] = limit
+	}
+	return resources
+}
+
+// applyPodOverrides sets scheduling and image pull settings from spec.server on the pod spec
+// selectorLabels select the server pods in topology spread constraints without a label selector
+func applyPodOverrides(podSpec *corev1.PodSpec, server *v1alpha1.ServerSpec, selectorLabels map[string]string) {
+	if server == nil {
+		server = &v1alpha1.ServerSpec{}
+	}
+
+	podSpec.NodeSelector = server.NodeSelector
+	podSpec.Tolerations = server.Tolerations
+	podSpec.Affinity = server.Affinity
+	podSpec.PriorityClassName = server.PriorityClassName
+	podSpec.ImagePullSecrets = server.ImagePullSecrets
+	podSpec.TopologySpreadConstraints = buildTopologySpreadConstraints(server.TopologySpreadConstraints, selectorLabels)
+}
+
+// buildTopologySpreadConstraints copies constraints from spec.server and selects the server pods
+// in constraints without a label selector
+func buildTopologySpreadConstraints(
+	constraints []corev1.TopologySpreadConstraint,
+	selectorLabels map[string]string,
+) []corev1.TopologySpreadConstraint {
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	result := make([]corev1.TopologySpreadConstraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		constraint = *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			matchLabels := make(map[string]string, len(selectorLabels))
+			for key, value := range selectorLabels {
+				matchLabels[key] = value
+			}
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		}
+		result = append(result, constraint)
+	}
+	return result
+}
+
+// syncServerOverrides updates an existing Deployment to match spec.server and the global config
+// Replicas are only enforced if set in spec.server without autoscaling, so manual scaling
+// and the HorizontalPodAutoscaler keep working otherwise
+// Returns true if the Deployment was changed
+func (m *DeploymentManager) syncServerOverrides(
+	deployment *appsv1.Deployment,
+	nginxResources *NginxResources,
+	server *v1alpha1.ServerSpec,
+) bool {
+	changed := false
+	podSpec := &deployment.Spec.Template.Spec
+
+	if len(podSpec.Containers) > 0 {
+		container := &podSpec.Containers[0]
+
+		if image := m.resolveImage(server); container.Image != image {
+			container.Image = image
+			changed = true
+		}
+
+		if server != nil && server.ImagePullPolicy != "" && container.ImagePullPolicy != server.ImagePullPolicy {
+			container.ImagePullPolicy = server.ImagePullPolicy
+			changed = true
+		}
+
+		if resources := m.resolveResources(nginxResources, server); !equality.Semantic.DeepEqual(container.Resources, resources) {
+			container.Resources = resources
+			changed = true
+		}
+	}
+
+	if server != nil && server.Replicas != nil && server.Autoscaling == nil {
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != *server.Replicas {
+			replicas := *server.Replicas
+			deployment.Spec.Replicas = &replicas
+			changed = true
+		}
+	}
+
+	var selectorLabels map[string]string
+	if deployment.Spec.Selector != nil {
+		selectorLabels = deployment.Spec.Selector.MatchLabels
+	}
+
+	desired := podSpec.DeepCopy()
+	applyPodOverrides(desired, server, selectorLabels)
+	if !equality.Semantic.DeepEqual(podSpec.NodeSelector, desired.NodeSelector) ||
+		!equality.Semantic.DeepEqual(podSpec.Tolerations, desired.Tolerations) ||
+		!equality.Semantic.DeepEqual(podSpec.Affinity, desired.Affinity) ||
+		podSpec.PriorityClassName != desired.PriorityClassName ||
+		!equality.Semantic.DeepEqual(podSpec.ImagePullSecrets, desired.ImagePullSecrets) ||
+		!equality.Semantic.DeepEqual(podSpec.TopologySpreadConstraints, desired.TopologySpreadConstraints) {
+		applyPodOverrides(podSpec, server, selectorLabels)
+		changed = true
+	}
+
+	return changed
+}
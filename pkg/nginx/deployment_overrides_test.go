@@ -0,0 +1,214 @@
+package nginx
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestDeploymentManagerResolveImageAndReplicas(t *testing.T) {
+	replicas := int32(3)
+
+	tests := []struct {
+		name         string
+		config       *config.NginxConfig
+		server       *v1alpha1.ServerSpec
+		wantImage    string
+		wantReplicas int32
+	}{
+		{
+			name:         "defaults",
+			config:       &config.NginxConfig{},
+			wantImage:    config.DefaultNginxImage,
+			wantReplicas: int32(config.DefaultNginxReplicas),
+		},
+		{
+			name:         "global config",
+			config:       &config.NginxConfig{Image: "registry.test/nginx:1", Replicas: 2},
+			server:       &v1alpha1.ServerSpec{},
+			wantImage:    "registry.test/nginx:1",
+			wantReplicas: 2,
+		},
+		{
+			name:         "spec.server overrides",
+			config:       &config.NginxConfig{Image: "registry.test/nginx:1", Replicas: 2},
+			server:       &v1alpha1.ServerSpec{Image: "registry.test/nginx:2", Replicas: &replicas},
+			wantImage:    "registry.test/nginx:2",
+			wantReplicas: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewDeploymentManager(nil, tt.config)
+
+			if got := manager.resolveImage(tt.server); got != tt.wantImage {
+				t.Errorf("resolveImage() = %q, want %q", got, tt.wantImage)
+			}
+			if got := manager.resolveReplicas(tt.server); got != tt.wantReplicas {
+				t.Errorf("resolveReplicas() = %d, want %d", got, tt.wantReplicas)
+			}
+		})
+	}
+}
+
+func TestDeploymentManagerResolveResources(t *testing.T) {
+	manager := NewDeploymentManager(nil, &config.NginxConfig{})
+
+	tests := []struct {
+		name         string
+		resources    *corev1.ResourceRequirements
+		wantRequests map[corev1.ResourceName]string
+		wantLimits   map[corev1.ResourceName]string
+	}{
+		{
+			name:         "global resources",
+			wantRequests: map[corev1.ResourceName]string{corev1.ResourceCPU: "50m", corev1.ResourceMemory: "64Mi"},
+			wantLimits:   map[corev1.ResourceName]string{corev1.ResourceCPU: "200m", corev1.ResourceMemory: "128Mi"},
+		},
+		{
+			name: "merged per resource name",
+			resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			},
+			wantRequests: map[corev1.ResourceName]string{corev1.ResourceCPU: "100m", corev1.ResourceMemory: "64Mi"},
+			wantLimits:   map[corev1.ResourceName]string{corev1.ResourceCPU: "200m", corev1.ResourceMemory: "256Mi"},
+		},
+		{
+			name: "extended resource without limit",
+			resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+			},
+			wantRequests: map[corev1.ResourceName]string{
+				corev1.ResourceCPU: "50m", corev1.ResourceMemory: "64Mi", corev1.ResourceEphemeralStorage: "1Gi",
+			},
+			wantLimits: map[corev1.ResourceName]string{corev1.ResourceCPU: "200m", corev1.ResourceMemory: "128Mi"},
+		},
+		{
+			name: "limit below the global request",
+			resources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Mi")},
+			},
+			wantRequests: map[corev1.ResourceName]string{corev1.ResourceCPU: "50m", corev1.ResourceMemory: "32Mi"},
+			wantLimits:   map[corev1.ResourceName]string{corev1.ResourceCPU: "200m", corev1.ResourceMemory: "32Mi"},
+		},
+		{
+			name: "request above the global limit",
+			resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+			wantRequests: map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "64Mi"},
+			wantLimits:   map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "128Mi"},
+		},
+		{
+			name: "request above the limit in spec.server",
+			resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")},
+			},
+			wantRequests: map[corev1.ResourceName]string{corev1.ResourceCPU: "300m", corev1.ResourceMemory: "64Mi"},
+			wantLimits:   map[corev1.ResourceName]string{corev1.ResourceCPU: "300m", corev1.ResourceMemory: "128Mi"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &v1alpha1.ServerSpec{Resources: tt.resources}
+			resources := manager.resolveResources(nil, server)
+
+			assertResourceList(t, "requests", resources.Requests, tt.wantRequests)
+			assertResourceList(t, "limits", resources.Limits, tt.wantLimits)
+		})
+	}
+}
+
+func TestDeploymentManagerSyncServerOverrides(t *testing.T) {
+	manager := NewDeploymentManager(nil, &config.NginxConfig{})
+	replicas := int32(4)
+
+	// newDeployment returns a Deployment as created without spec.server
+	newDeployment := func() *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Replicas = new(int32)
+		*deployment.Spec.Replicas = 2
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:      "nginx",
+			Image:     manager.resolveImage(nil),
+			Resources: manager.resolveResources(nil, nil),
+		}}
+		return deployment
+	}
+
+	tests := []struct {
+		name         string
+		server       *v1alpha1.ServerSpec
+		wantChanged  bool
+		wantReplicas int32
+		wantImage    string
+	}{
+		{
+			name:         "up to date, manual scaling is kept",
+			server:       nil,
+			wantReplicas: 2,
+			wantImage:    config.DefaultNginxImage,
+		},
+		{
+			name:         "image and replicas",
+			server:       &v1alpha1.ServerSpec{Image: "registry.test/nginx:2", Replicas: &replicas},
+			wantChanged:  true,
+			wantReplicas: 4,
+			wantImage:    "registry.test/nginx:2",
+		},
+		{
+			name:         "scheduling",
+			server:       &v1alpha1.ServerSpec{NodeSelector: map[string]string{"pool": "edge"}, PriorityClassName: "high"},
+			wantChanged:  true,
+			wantReplicas: 2,
+			wantImage:    config.DefaultNginxImage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newDeployment()
+
+			if changed := manager.syncServerOverrides(deployment, nil, tt.server); changed != tt.wantChanged {
+				t.Errorf("syncServerOverrides() = %v, want %v", changed, tt.wantChanged)
+			}
+			if got := *deployment.Spec.Replicas; got != tt.wantReplicas {
+				t.Errorf("replicas = %d, want %d", got, tt.wantReplicas)
+			}
+			if got := deployment.Spec.Template.Spec.Containers[0].Image; got != tt.wantImage {
+				t.Errorf("image = %q, want %q", got, tt.wantImage)
+			}
+			if tt.server != nil && deployment.Spec.Template.Spec.PriorityClassName != tt.server.PriorityClassName {
+				t.Errorf("priorityClassName = %q, want %q", deployment.Spec.Template.Spec.PriorityClassName, tt.server.PriorityClassName)
+			}
+			if manager.syncServerOverrides(deployment, nil, tt.server) {
+				t.Error("second syncServerOverrides() reported a change")
+			}
+		})
+	}
+}
+
+// assertResourceList compares a resource list with the expected quantities
+func assertResourceList(t *testing.T, kind string, got corev1.ResourceList, want map[corev1.ResourceName]string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", kind, got, want)
+		return
+	}
+	for name, quantity := range want {
+		actual, ok := got[name]
+		if !ok || actual.Cmp(resource.MustParse(quantity)) != 0 {
+			t.Errorf("%s[%s] = %s, want %s", kind, name, actual.String(), quantity)
+		}
+	}
+}
@@ -56,11 +56,3 @@ func (m *DeploymentManager) buildResourceRequirements(nginxResources *NginxResou
 		},
 	}
 }
-
-// resourcesEqual checks if two ResourceRequirements are equal
-func resourcesEqual(a, b corev1.ResourceRequirements) bool {
-	return a.Requests.Cpu().Equal(*b.Requests.Cpu()) &&
-		a.Requests.Memory().Equal(*b.Requests.Memory()) &&
-		a.Limits.Cpu().Equal(*b.Limits.Cpu()) &&
-		a.Limits.Memory().Equal(*b.Limits.Memory())
-}
@@ -0,0 +1,173 @@
+package nginx
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// scratchMounts are writable paths nginx needs with a read-only root filesystem
+var scratchMounts = []corev1.VolumeMount{
+	{Name: config.VolumeNameNginxCache, MountPath: "/var/cache/nginx"},
+	{Name: config.VolumeNameNginxRun, MountPath: "/var/run"},
+	{Name: config.VolumeNameTmp, MountPath: "/tmp"},
+}
+
+// resolveSecurityProfile returns the security profile from spec.server or the global config
+func (m *DeploymentManager) resolveSecurityProfile(server *v1alpha1.ServerSpec) string {
+	if server != nil && server.SecurityProfile != "" {
+		return server.SecurityProfile
+	}
+	if m.config != nil && m.config.SecurityProfile != "" {
+		return m.config.SecurityProfile
+	}
+	return config.SecurityProfileRestricted
+}
+
+// restrictedPodSecurityContext returns the pod security context compliant with Pod Security "restricted"
+func restrictedPodSecurityContext() *corev1.PodSecurityContext {
+	runAsNonRoot := true
+	userID := int64(config.NginxUserID)
+	groupID := int64(config.NginxUserID)
+	fsGroup := int64(config.NginxUserID)
+
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: &runAsNonRoot,
+		RunAsUser:    &userID,
+		RunAsGroup:   &groupID,
+		FSGroup:      &fsGroup,
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// restrictedContainerSecurityContext returns the container security context compliant with Pod Security "restricted"
+func restrictedContainerSecurityContext() *corev1.SecurityContext {
+	allowPrivilegeEscalation := false
+	readOnlyRootFilesystem := true
+	runAsNonRoot := true
+
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+		RunAsNonRoot:             &runAsNonRoot,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
+
+// applySecurityProfile sets security contexts and scratch volumes of the server pod for the profile
+// scratch adds the writable volumes nginx needs, the built-in server does not write to disk
+// With the none profile, settings made by the operator are removed
+func applySecurityProfile(podSpec *corev1.PodSpec, profile string, scratch bool) {
+	restricted := profile != config.SecurityProfileNone
+
+	podSpec.SecurityContext = nil
+	if restricted {
+		podSpec.SecurityContext = restrictedPodSecurityContext()
+	}
+
+	volumes := make([]corev1.Volume, 0, len(podSpec.Volumes)+len(scratchMounts))
+	for _, vol := range podSpec.Volumes {
+		if !isScratchVolume(vol.Name) {
+			volumes = append(volumes, vol)
+		}
+	}
+	if restricted && scratch {
+		for _, mount := range scratchMounts {
+			volumes = append(volumes, corev1.Volume{
+				Name:         mount.Name,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+		}
+	}
+	podSpec.Volumes = volumes
+
+	if len(podSpec.Containers) == 0 {
+		return
+	}
+
+	container := &podSpec.Containers[0]
+	container.SecurityContext = nil
+	if restricted {
+		container.SecurityContext = restrictedContainerSecurityContext()
+	}
+
+	mounts := make([]corev1.VolumeMount, 0, len(container.VolumeMounts)+len(scratchMounts))
+	for _, mount := range container.VolumeMounts {
+		if !isScratchVolume(mount.Name) {
+			mounts = append(mounts, mount)
+		}
+	}
+	if restricted && scratch {
+		mounts = append(mounts, scratchMounts...)
+	}
+	container.VolumeMounts = mounts
+}
+
+// isScratchVolume checks if the volume is one of the writable volumes added for restricted pods
+func isScratchVolume(name string) bool {
+	for _, mount := range scratchMounts {
+		if mount.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// syncSecurityProfile updates an existing Deployment to match the security profile
+// Returns true if the Deployment was changed
+func (m *DeploymentManager) syncSecurityProfile(deployment *appsv1.Deployment, server *v1alpha1.ServerSpec) bool {
+	podSpec := &deployment.Spec.Template.Spec
+
+	desired := podSpec.DeepCopy()
+	applySecurityProfile(desired, m.resolveSecurityProfile(server), !BuiltinServer(server))
+
+	if equality.Semantic.DeepEqual(podSpec.SecurityContext, desired.SecurityContext) &&
+		equality.Semantic.DeepEqual(podSpec.Volumes, desired.Volumes) &&
+		equality.Semantic.DeepEqual(podSpec.Containers, desired.Containers) {
+		return false
+	}
+
+	podSpec.SecurityContext = desired.SecurityContext
+	podSpec.Volumes = desired.Volumes
+	podSpec.Containers = desired.Containers
+	return true
+}
+
+// syncContainerPort updates the container port and probes to the configured nginx port
+// Deployments created with an older default port follow the generated listen directive
+// Returns true if the Deployment was changed
+func syncContainerPort(deployment *appsv1.Deployment, port int) bool {
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return false
+	}
+
+	container := &containers[0]
+	changed := false
+	desiredPort := safeIntToInt32(port)
+
+	for i := range container.Ports {
+		if container.Ports[i].Name == "http" && container.Ports[i].ContainerPort != desiredPort {
+			container.Ports[i].ContainerPort = desiredPort
+			changed = true
+		}
+	}
+
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+		if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Port.IntValue() == port {
+			continue
+		}
+		probe.HTTPGet.Port = intstr.FromInt(port)
+		changed = true
+	}
+
+	return changed
+}
@@ -0,0 +1,159 @@
+package nginx
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// newSecurityTestDeployment returns an nginx Deployment with data volumes and an HTTP probe on the port
+func newSecurityTestDeployment(port int) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{Name: config.VolumeNameJWKSData}},
+					Containers: []corev1.Container{{
+						Name:         "nginx",
+						Ports:        []corev1.ContainerPort{{Name: "http", ContainerPort: safeIntToInt32(port)}},
+						VolumeMounts: []corev1.VolumeMount{{Name: config.VolumeNameJWKSData}},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{Path: config.HealthCheckPath, Port: intstr.FromInt(port)},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestDeploymentManagerResolveSecurityProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.NginxConfig
+		server *v1alpha1.ServerSpec
+		want   string
+	}{
+		{
+			name:   "default",
+			config: &config.NginxConfig{},
+			want:   config.SecurityProfileRestricted,
+		},
+		{
+			name:   "global config",
+			config: &config.NginxConfig{SecurityProfile: config.SecurityProfileNone},
+			want:   config.SecurityProfileNone,
+		},
+		{
+			name:   "spec.server override",
+			config: &config.NginxConfig{SecurityProfile: config.SecurityProfileNone},
+			server: &v1alpha1.ServerSpec{SecurityProfile: config.SecurityProfileRestricted},
+			want:   config.SecurityProfileRestricted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDeploymentManager(nil, tt.config).resolveSecurityProfile(tt.server); got != tt.want {
+				t.Errorf("resolveSecurityProfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeploymentManagerSyncSecurityProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		initial     string
+		wantChanged bool
+		wantVolumes int
+	}{
+		{
+			name:        "restricted from image defaults",
+			profile:     config.SecurityProfileRestricted,
+			initial:     config.SecurityProfileNone,
+			wantChanged: true,
+			wantVolumes: 1 + len(scratchMounts),
+		},
+		{
+			name:        "restricted is up to date",
+			profile:     config.SecurityProfileRestricted,
+			initial:     config.SecurityProfileRestricted,
+			wantVolumes: 1 + len(scratchMounts),
+		},
+		{
+			name:        "none removes operator settings",
+			profile:     config.SecurityProfileNone,
+			initial:     config.SecurityProfileRestricted,
+			wantChanged: true,
+			wantVolumes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newSecurityTestDeployment(8080)
+			applySecurityProfile(&deployment.Spec.Template.Spec, tt.initial, true)
+
+			manager := NewDeploymentManager(nil, &config.NginxConfig{SecurityProfile: tt.profile})
+			if changed := manager.syncSecurityProfile(deployment, nil); changed != tt.wantChanged {
+				t.Errorf("syncSecurityProfile() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			podSpec := deployment.Spec.Template.Spec
+			container := podSpec.Containers[0]
+			restricted := tt.profile == config.SecurityProfileRestricted
+
+			if (podSpec.SecurityContext != nil) != restricted {
+				t.Errorf("pod security context = %+v, restricted %v", podSpec.SecurityContext, restricted)
+			}
+			if (container.SecurityContext != nil) != restricted {
+				t.Errorf("container security context = %+v, restricted %v", container.SecurityContext, restricted)
+			}
+			if restricted && (container.SecurityContext.ReadOnlyRootFilesystem == nil || !*container.SecurityContext.ReadOnlyRootFilesystem) {
+				t.Error("restricted container must have a read-only root filesystem")
+			}
+			if len(podSpec.Volumes) != tt.wantVolumes || len(container.VolumeMounts) != tt.wantVolumes {
+				t.Errorf("volumes = %d, mounts = %d, want %d", len(podSpec.Volumes), len(container.VolumeMounts), tt.wantVolumes)
+			}
+		})
+	}
+}
+
+func TestSyncContainerPort(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     int
+		desired     int
+		wantChanged bool
+	}{
+		{name: "up to date", current: 8080, desired: 8080},
+		{name: "old default port", current: 80, desired: 8080, wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newSecurityTestDeployment(tt.current)
+
+			if changed := syncContainerPort(deployment, tt.desired); changed != tt.wantChanged {
+				t.Errorf("syncContainerPort() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			container := deployment.Spec.Template.Spec.Containers[0]
+			if got := container.Ports[0].ContainerPort; got != int32(tt.desired) {
+				t.Errorf("container port = %d, want %d", got, tt.desired)
+			}
+			if got := container.ReadinessProbe.HTTPGet.Port.IntValue(); got != tt.desired {
+				t.Errorf("probe port = %d, want %d", got, tt.desired)
+			}
+		})
+	}
+}
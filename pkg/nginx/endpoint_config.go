@@ -2,15 +2,24 @@ package nginx
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
 )
 
 const (
-	// DefaultEndpoint is the default JWKS endpoint path (kept for backward compatibility)
-	// JWKS is always available at both "/" and "/jwks.json" paths
-	DefaultEndpoint = "/jwks.json"
+	// DefaultEndpoint is the default JWKS endpoint path
+	DefaultEndpoint = config.JWKSEndpointPath
 )
 
+// DefaultPaths are additional paths serving JWKS if spec.paths is not set
+// The root path is kept for clients of older operator versions, which served JWKS for every path
+var DefaultPaths = []string{"/"}
+
+// pathPattern restricts served paths to characters that are safe to put into nginx configuration
+var pathPattern = regexp.MustCompile(`^/[A-Za-z0-9._~!&*+,=:@%/-]*$`)
+
 // ValidateEndpoint validates an endpoint path
 func ValidateEndpoint(endpoint string) error {
 	if endpoint == "" {
@@ -21,6 +30,10 @@ func ValidateEndpoint(endpoint string) error {
 		return fmt.Errorf("endpoint must start with '/'")
 	}
 
+	if !pathPattern.MatchString(endpoint) {
+		return fmt.Errorf("endpoint %q contains unsupported characters", endpoint)
+	}
+
 	return nil
 }
 
@@ -37,3 +50,80 @@ func NormalizeEndpoint(endpoint string) string {
 
 	return endpoint
 }
+
+// NormalizePathPrefix normalizes a path prefix, "/" and empty prefix mean no prefix
+func NormalizePathPrefix(prefix string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return NormalizeEndpoint(prefix)
+}
+
+// withPrefix mounts the path under the prefix
+func withPrefix(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "/" {
+		return prefix + "/"
+	}
+	return prefix + path
+}
+
+// JWKSPath returns the primary JWKS path including the prefix
+// It is used by the readiness probe, verification and the public URL
+func (o ConfigOptions) JWKSPath() string {
+	return withPrefix(NormalizePathPrefix(o.PathPrefix), NormalizeEndpoint(o.Endpoint))
+}
+
+// DiscoveryPath returns the OpenID Connect discovery document path including the prefix
+func (o ConfigOptions) DiscoveryPath() string {
+	return withPrefix(NormalizePathPrefix(o.PathPrefix), config.DiscoveryEndpointPath)
+}
+
+// JWKSPaths returns all paths serving JWKS including the prefix, the primary path first
+func (o ConfigOptions) JWKSPaths() []string {
+	prefix := NormalizePathPrefix(o.PathPrefix)
+
+	paths := o.Paths
+	if paths == nil {
+		paths = DefaultPaths
+	}
+
+	result := []string{o.JWKSPath()}
+	seen := map[string]bool{result[0]: true}
+	for _, path := range paths {
+		path = withPrefix(prefix, NormalizeEndpoint(path))
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		result = append(result, path)
+	}
+
+	return result
+}
+
+// Validate checks served paths for unsupported characters and collisions with reserved paths
+func (o ConfigOptions) Validate() error {
+	if o.PathPrefix != "" && o.PathPrefix != "/" {
+		if err := ValidateEndpoint(NormalizePathPrefix(o.PathPrefix)); err != nil {
+			return fmt.Errorf("invalid pathPrefix: %w", err)
+		}
+	}
+
+	for _, path := range o.JWKSPaths() {
+		if err := ValidateEndpoint(path); err != nil {
+			return err
+		}
+		if path == config.HealthCheckPath {
+			return fmt.Errorf("path %s is reserved for the health check", path)
+		}
+		if o.Discovery && path == o.DiscoveryPath() {
+			return fmt.Errorf("path %s is reserved for the discovery document", path)
+		}
+	}
+
+	return nil
+}
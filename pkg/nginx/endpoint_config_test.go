@@ -0,0 +1,125 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestConfigOptionsPaths(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          ConfigOptions
+		wantJWKSPath  string
+		wantPaths     []string
+		wantDiscovery string
+	}{
+		{
+			name:          "defaults",
+			opts:          ConfigOptions{Endpoint: DefaultEndpoint},
+			wantJWKSPath:  config.JWKSEndpointPath,
+			wantPaths:     []string{config.JWKSEndpointPath, "/"},
+			wantDiscovery: config.DiscoveryEndpointPath,
+		},
+		{
+			name:          "only the endpoint",
+			opts:          ConfigOptions{Endpoint: "keys", Paths: []string{}},
+			wantJWKSPath:  "/keys",
+			wantPaths:     []string{"/keys"},
+			wantDiscovery: config.DiscoveryEndpointPath,
+		},
+		{
+			name:          "duplicate paths",
+			opts:          ConfigOptions{Endpoint: "/keys", Paths: []string{"/keys", "/v1/keys", "v1/keys"}},
+			wantJWKSPath:  "/keys",
+			wantPaths:     []string{"/keys", "/v1/keys"},
+			wantDiscovery: config.DiscoveryEndpointPath,
+		},
+		{
+			name:          "prefix",
+			opts:          ConfigOptions{Endpoint: DefaultEndpoint, PathPrefix: "auth/"},
+			wantJWKSPath:  "/auth" + config.JWKSEndpointPath,
+			wantPaths:     []string{"/auth" + config.JWKSEndpointPath, "/auth/"},
+			wantDiscovery: "/auth" + config.DiscoveryEndpointPath,
+		},
+		{
+			name:          "root prefix",
+			opts:          ConfigOptions{Endpoint: DefaultEndpoint, PathPrefix: "/"},
+			wantJWKSPath:  config.JWKSEndpointPath,
+			wantPaths:     []string{config.JWKSEndpointPath, "/"},
+			wantDiscovery: config.DiscoveryEndpointPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.JWKSPath(); got != tt.wantJWKSPath {
+				t.Errorf("JWKSPath() = %q, want %q", got, tt.wantJWKSPath)
+			}
+			if got := tt.opts.JWKSPaths(); !reflect.DeepEqual(got, tt.wantPaths) {
+				t.Errorf("JWKSPaths() = %v, want %v", got, tt.wantPaths)
+			}
+			if got := tt.opts.DiscoveryPath(); got != tt.wantDiscovery {
+				t.Errorf("DiscoveryPath() = %q, want %q", got, tt.wantDiscovery)
+			}
+		})
+	}
+}
+
+func TestConfigOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ConfigOptions
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint},
+		},
+		{
+			name: "prefix and discovery",
+			opts: ConfigOptions{Endpoint: DefaultEndpoint, PathPrefix: "/auth", Discovery: true},
+		},
+		{
+			name:    "unsupported characters",
+			opts:    ConfigOptions{Endpoint: "/jwks.json; return 200"},
+			wantErr: "unsupported characters",
+		},
+		{
+			name:    "invalid prefix",
+			opts:    ConfigOptions{Endpoint: DefaultEndpoint, PathPrefix: "/a b"},
+			wantErr: "invalid pathPrefix",
+		},
+		{
+			name:    "health check path",
+			opts:    ConfigOptions{Endpoint: DefaultEndpoint, Paths: []string{config.HealthCheckPath}},
+			wantErr: "reserved for the health check",
+		},
+		{
+			name:    "discovery path",
+			opts:    ConfigOptions{Endpoint: config.DiscoveryEndpointPath, Discovery: true},
+			wantErr: "reserved for the discovery document",
+		},
+		{
+			name: "discovery path without discovery",
+			opts: ConfigOptions{Endpoint: config.DiscoveryEndpointPath},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
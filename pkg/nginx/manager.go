@@ -3,6 +3,7 @@ package nginx
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -10,7 +11,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwksserver"
 )
 
 // NginxResources is an alias for config.NginxResources for convenience
@@ -18,12 +21,16 @@ import (
 //nolint:revive // Naming stutter is acceptable for type aliases
 type NginxResources = config.NginxResources
 
-// Manager manages nginx ConfigMap, Deployment and Service resources
+// Manager manages nginx ConfigMap, Deployment, Service, PodDisruptionBudget, HorizontalPodAutoscaler
+// and NetworkPolicy resources
 type Manager struct {
 	client            client.Client
 	generator         *ConfigGenerator
 	deploymentManager *DeploymentManager
 	serviceManager    *ServiceManager
+	availability      *AvailabilityManager
+	networkPolicy     *NetworkPolicyManager
+	configTester      *ConfigTester
 	nginxConfig       *config.NginxConfig
 }
 
@@ -34,17 +41,27 @@ func NewManager(client client.Client, nginxConfig *config.NginxConfig) *Manager
 		cacheMaxAge = nginxConfig.CacheMaxAge
 	}
 
+	deploymentManager := NewDeploymentManager(client, nginxConfig)
 	return &Manager{
 		client:            client,
-		generator:         NewConfigGenerator(cacheMaxAge),
-		deploymentManager: NewDeploymentManager(client, nginxConfig),
-		serviceManager:    NewServiceManager(client),
+		generator:         NewConfigGenerator(cacheMaxAge, containerPort(nginxConfig), statusPort(nginxConfig), tlsPort(nginxConfig)),
+		deploymentManager: deploymentManager,
+		serviceManager:    NewServiceManager(client, nginxConfig),
+		availability:      NewAvailabilityManager(client),
+		networkPolicy:     NewNetworkPolicyManager(client, nginxConfig),
+		configTester:      NewConfigTester(client, deploymentManager),
 		nginxConfig:       nginxConfig,
 	}
 }
 
 // UpdateConfig updates nginx ConfigMap with configuration
-func (m *Manager) UpdateConfig(ctx context.Context, namespace, configMapName string, jwksConfigMapName string, endpoint string) error {
+// A changed config is only written after it passed validation, see validateConfig
+func (m *Manager) UpdateConfig(
+	ctx context.Context,
+	namespace, jwksName, configMapName, jwksConfigMapName string,
+	opts ConfigOptions,
+	server *v1alpha1.ServerSpec,
+) error {
 	if configMapName == "" {
 		return fmt.Errorf("nginx ConfigMap name cannot be empty")
 	}
@@ -52,10 +69,9 @@ func (m *Manager) UpdateConfig(ctx context.Context, namespace, configMapName str
 		return fmt.Errorf("JWKS ConfigMap name cannot be empty")
 	}
 
-	// Generate nginx configuration
-	nginxConfigContent, err := m.generator.GenerateConfig(jwksConfigMapName, endpoint)
+	desiredData, err := m.generateConfigData(jwksConfigMapName, opts)
 	if err != nil {
-		return fmt.Errorf("failed to generate nginx config: %w", err)
+		return err
 	}
 
 	// Get or create ConfigMap
@@ -64,16 +80,19 @@ func (m *Manager) UpdateConfig(ctx context.Context, namespace, configMapName str
 
 	err = m.client.Get(ctx, key, nginxConfigMap)
 	if apierrors.IsNotFound(err) {
+		if err := m.validateConfig(ctx, namespace, jwksName, desiredData, opts, server); err != nil {
+			return err
+		}
+
 		// Create new ConfigMap
 		nginxConfigMap = &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      configMapName,
 				Namespace: namespace,
 			},
-			Data: map[string]string{
-				config.ConfigMapKeyNginxConfig: nginxConfigContent,
-			},
+			Data: desiredData,
 		}
+		syncTemplateHash(nginxConfigMap, opts.Template.Hash)
 		return m.client.Create(ctx, nginxConfigMap)
 	}
 	if err != nil {
@@ -88,21 +107,191 @@ func (m *Manager) UpdateConfig(ctx context.Context, namespace, configMapName str
 	// Always update ConfigMap to ensure it matches the current generator logic
 	// This ensures that after operator restart/update, ConfigMap will be updated
 	// even if the generated config looks similar but has different formatting or structure
-	configKey := config.ConfigMapKeyNginxConfig
-	oldConfig := nginxConfigMap.Data[configKey]
-	if oldConfig != nginxConfigContent {
-		nginxConfigMap.Data[configKey] = nginxConfigContent
-		if err := m.client.Update(ctx, nginxConfigMap); err != nil {
-			return fmt.Errorf("failed to update nginx ConfigMap: %w", err)
+	// Snapshots are synced first, the served one is found in the stored nginx config
+	changed := !opts.Builtin && syncJWKSSnapshots(nginxConfigMap.Data, desiredData)
+	for _, configKey := range managedConfigKeys(opts) {
+		desired, ok := desiredData[configKey]
+		current, exists := nginxConfigMap.Data[configKey]
+		switch {
+		case ok && current != desired:
+			nginxConfigMap.Data[configKey] = desired
+			changed = true
+		case !ok && exists:
+			delete(nginxConfigMap.Data, configKey)
+			changed = true
+		}
+	}
+
+	if !changed {
+		if syncTemplateHash(nginxConfigMap, opts.Template.Hash) {
+			// The template changed without changing the rendered config
+			if err := m.client.Update(ctx, nginxConfigMap); err != nil {
+				return fmt.Errorf("failed to update nginx ConfigMap: %w", err)
+			}
+		}
+		if m.ConfigTestEnabled() && !opts.Builtin {
+			// The served config is current, a test of an abandoned change is not needed anymore
+			return m.configTester.Cleanup(ctx, namespace, jwksName)
 		}
-		// ConfigMap updated, return to trigger Deployment update
 		return nil
 	}
 
-	// ConfigMap content matches, no update needed
+	if err := m.validateConfig(ctx, namespace, jwksName, desiredData, opts, server); err != nil {
+		return err
+	}
+	syncTemplateHash(nginxConfigMap, opts.Template.Hash)
+	if err := m.client.Update(ctx, nginxConfigMap); err != nil {
+		return fmt.Errorf("failed to update nginx ConfigMap: %w", err)
+	}
+
 	return nil
 }
 
+// syncTemplateHash records the hash of the template the config was rendered from on the nginx ConfigMap
+// The annotation is removed for configs rendered from the built-in template
+// Returns true if the ConfigMap was changed
+func syncTemplateHash(configMap *corev1.ConfigMap, hash string) bool {
+	current, exists := configMap.Annotations[config.AnnotationTemplateHash]
+	if hash == "" {
+		if !exists {
+			return false
+		}
+		delete(configMap.Annotations, config.AnnotationTemplateHash)
+		return true
+	}
+	if current == hash {
+		return false
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	configMap.Annotations[config.AnnotationTemplateHash] = hash
+	return true
+}
+
+// validateConfig checks a changed server config before it is written
+// The nginx config is linted and, with nginx.configTest enabled, tested with nginx -t in a Job
+// Directive names are only linted in configs without a custom template or snippets
+// The built-in server config is parsed like the server does on reload
+func (m *Manager) validateConfig(
+	ctx context.Context,
+	namespace, jwksName string,
+	data map[string]string,
+	opts ConfigOptions,
+	server *v1alpha1.ServerSpec,
+) error {
+	if opts.Builtin {
+		if _, err := jwksserver.ParseConfig([]byte(data[config.ConfigMapKeyBuiltinConfig])); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		return nil
+	}
+
+	lint := LintConfig
+	if opts.Template.Custom() {
+		lint = LintConfigStructure // Directives of user content are checked by nginx -t
+	}
+	if err := lint(data[config.ConfigMapKeyNginxConfig]); err != nil {
+		return err
+	}
+	if !m.ConfigTestEnabled() {
+		return nil
+	}
+	return m.configTester.Test(ctx, namespace, jwksName, data, server)
+}
+
+// ConfigTestEnabled checks if changed nginx configs are tested with nginx -t before they are written
+func (m *Manager) ConfigTestEnabled() bool {
+	return m.nginxConfig != nil && m.nginxConfig.ConfigTest
+}
+
+// DeleteConfigTest deletes the nginx -t Job and the candidate ConfigMap for JWKS
+func (m *Manager) DeleteConfigTest(ctx context.Context, namespace, jwksName string) error {
+	return m.configTester.Cleanup(ctx, namespace, jwksName)
+}
+
+// managedConfigKeys returns the server ConfigMap keys the operator writes or removes
+// nginx keys are kept for the built-in server: nginx pods keep serving with their config
+// while the Deployment rolls out the built-in server
+func managedConfigKeys(opts ConfigOptions) []string {
+	if opts.Builtin {
+		return []string{config.ConfigMapKeyBuiltinConfig}
+	}
+	return []string{config.ConfigMapKeyNginxConfig, config.ConfigMapKeyExporterConfig, config.ConfigMapKeyBuiltinConfig}
+}
+
+// generateConfigData generates the server ConfigMap data: the nginx config with the optional exporter config
+// or the built-in server config
+func (m *Manager) generateConfigData(jwksConfigMapName string, opts ConfigOptions) (map[string]string, error) {
+	if opts.Builtin {
+		builtinConfig, err := m.generator.GenerateBuiltinConfig(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate built-in server config: %w", err)
+		}
+		return map[string]string{config.ConfigMapKeyBuiltinConfig: builtinConfig}, nil
+	}
+
+	nginxConfigContent, err := m.generator.GenerateConfig(jwksConfigMapName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nginx config: %w", err)
+	}
+
+	// The exporter config and the JWKS snapshot are stored next to the nginx config, nginx only includes *.conf files
+	data := map[string]string{
+		config.ConfigMapKeyNginxConfig: nginxConfigContent,
+	}
+	if opts.Metrics {
+		data[config.ConfigMapKeyExporterConfig] = m.generator.GenerateExporterConfig()
+	}
+	if opts.ContentDigest != "" {
+		data[JWKSSnapshotFile(opts.ContentDigest)] = opts.Content
+	}
+	return data, nil
+}
+
+// isJWKSSnapshotKey checks if the nginx ConfigMap key holds a JWKS snapshot, see JWKSSnapshotFile
+func isJWKSSnapshotKey(key string) bool {
+	return strings.HasPrefix(key, config.ConfigMapKeyJWKSSnapshotPrefix) &&
+		strings.HasSuffix(key, config.ConfigMapKeyJWKSSnapshotSuffix)
+}
+
+// syncJWKSSnapshots writes the desired JWKS snapshot to the stored nginx ConfigMap data
+// When the snapshot changes, the one the stored config serves is kept until the next change: pods keep
+// serving it with their loaded config until the reloader picked up the new config. Older snapshots are removed
+// Returns true if the data was changed
+func syncJWKSSnapshots(current, desired map[string]string) bool {
+	desiredKey := ""
+	for key := range desired {
+		if isJWKSSnapshotKey(key) {
+			desiredKey = key
+		}
+	}
+	if desiredKey == "" {
+		return false // Digest unknown, the config serves jwks.json from the JWKS ConfigMap
+	}
+
+	changed := false
+	if _, exists := current[desiredKey]; !exists {
+		servedConfig := current[config.ConfigMapKeyNginxConfig]
+		for key := range current {
+			if isJWKSSnapshotKey(key) && !strings.Contains(servedConfig, key) {
+				delete(current, key)
+				changed = true
+			}
+		}
+	}
+	if value, exists := current[desiredKey]; !exists || value != desired[desiredKey] {
+		current[desiredKey] = desired[desiredKey]
+		changed = true
+	}
+	return changed
+}
+
+// CacheControl returns the Cache-Control header value the generated config sends for spec.cache
+func (m *Manager) CacheControl(spec *v1alpha1.CacheSpec) string {
+	return m.generator.CacheControl(spec)
+}
+
 // GetConfig retrieves nginx configuration from ConfigMap
 func (m *Manager) GetConfig(ctx context.Context, namespace, configMapName string) (string, error) {
 	configMap := &corev1.ConfigMap{}
@@ -144,13 +333,19 @@ func (m *Manager) CreateConfigMap(ctx context.Context, namespace, configMapName
 	return m.client.Create(ctx, configMap)
 }
 
+// ValidateImage checks that the server image of spec.server is known, see DeploymentManager.ValidateImage
+func (m *Manager) ValidateImage(server *v1alpha1.ServerSpec) error {
+	return m.deploymentManager.ValidateImage(server)
+}
+
 // EnsureDeployment ensures nginx Deployment exists for JWKS
 func (m *Manager) EnsureDeployment(
 	ctx context.Context,
 	namespace, jwksName, nginxConfigMapName, jwksConfigMapName, endpoint string,
 	nginxResources *NginxResources,
+	server *v1alpha1.ServerSpec,
 ) error {
-	return m.deploymentManager.EnsureDeployment(ctx, namespace, jwksName, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources)
+	return m.deploymentManager.EnsureDeployment(ctx, namespace, jwksName, nginxConfigMapName, jwksConfigMapName, endpoint, nginxResources, server)
 }
 
 // DeleteDeployment deletes nginx Deployment for JWKS
@@ -159,14 +354,43 @@ func (m *Manager) DeleteDeployment(ctx context.Context, namespace, jwksName stri
 }
 
 // EnsureService ensures nginx Service exists for JWKS
+// tls adds the https port
 func (m *Manager) EnsureService(
 	ctx context.Context,
 	namespace, jwksName string,
+	settings *v1alpha1.ServiceSpec,
+	tls *v1alpha1.ServerTLSSpec,
 ) error {
-	return m.serviceManager.EnsureService(ctx, namespace, jwksName)
+	return m.serviceManager.EnsureService(ctx, namespace, jwksName, settings, tls)
 }
 
 // DeleteService deletes nginx Service for JWKS
 func (m *Manager) DeleteService(ctx context.Context, namespace, jwksName string) error {
 	return m.serviceManager.DeleteService(ctx, namespace, jwksName)
 }
+
+// EnsurePodDisruptionBudget ensures the nginx PodDisruptionBudget matches spec.server.podDisruptionBudget
+func (m *Manager) EnsurePodDisruptionBudget(ctx context.Context, namespace, jwksName string, spec *v1alpha1.PodDisruptionBudgetSpec) error {
+	return m.availability.EnsurePodDisruptionBudget(ctx, namespace, jwksName, spec)
+}
+
+// EnsureHorizontalPodAutoscaler ensures the nginx HorizontalPodAutoscaler matches spec.server.autoscaling
+func (m *Manager) EnsureHorizontalPodAutoscaler(ctx context.Context, namespace, jwksName string, spec *v1alpha1.AutoscalingSpec) error {
+	return m.availability.EnsureHorizontalPodAutoscaler(ctx, namespace, jwksName, spec)
+}
+
+// GetAvailability returns the availability of nginx pods reported by the PodDisruptionBudget
+func (m *Manager) GetAvailability(ctx context.Context, namespace, jwksName string) (Availability, error) {
+	return m.availability.GetAvailability(ctx, namespace, jwksName)
+}
+
+// EnsureNetworkPolicy ensures the nginx NetworkPolicy matches spec.networkPolicy
+// spec.server.tls allows the https port and spec.server.metrics.monitor the metrics port as well
+func (m *Manager) EnsureNetworkPolicy(
+	ctx context.Context,
+	namespace, jwksName, operatorNamespace string,
+	spec *v1alpha1.NetworkPolicySpec,
+	server *v1alpha1.ServerSpec,
+) error {
+	return m.networkPolicy.EnsureNetworkPolicy(ctx, namespace, jwksName, operatorNamespace, spec, server)
+}
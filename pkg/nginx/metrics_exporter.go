@@ -0,0 +1,197 @@
+package nginx
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// metricsLogFormat is the access log format parsed by the exporter
+const metricsLogFormat = `"$request" $status $body_bytes_sent $request_time`
+
+// prometheusAnnotations are pod annotations for Prometheus annotation-based discovery
+var prometheusAnnotations = map[string]string{
+	"prometheus.io/scrape": "true",
+	"prometheus.io/port":   strconv.Itoa(config.ExporterPort),
+	"prometheus.io/path":   config.ExporterMetricsPath,
+}
+
+// MetricsEnabled checks if the metrics exporter sidecar is enabled in spec.server
+func MetricsEnabled(server *v1alpha1.ServerSpec) bool {
+	return server != nil && server.Metrics != nil && server.Metrics.Enabled
+}
+
+// MetricsExported checks if the server pods serve metrics on the metrics port
+// The built-in server always exports metrics, nginx only with the exporter sidecar
+func MetricsExported(server *v1alpha1.ServerSpec) bool {
+	return MetricsEnabled(server) || BuiltinServer(server)
+}
+
+// GenerateMetricsLogFormat generates the http-level log format of access log records sent to the exporter
+func (g *ConfigGenerator) GenerateMetricsLogFormat() string {
+	return fmt.Sprintf("log_format jwks_metrics '%s';", metricsLogFormat)
+}
+
+// GenerateMetricsAccessLogBlock generates server-level access logs for the exporter
+// A server-level access_log replaces the inherited one, so the stdout log is declared again
+func (g *ConfigGenerator) GenerateMetricsAccessLogBlock() string {
+	return fmt.Sprintf(`    # Access log records for the metrics exporter
+    access_log %s combined;
+    %s`, accessLogPath, metricsAccessLog())
+}
+
+// metricsAccessLog returns the access_log directive sending records to the exporter
+func metricsAccessLog() string {
+	return fmt.Sprintf("access_log syslog:server=%s,tag=nginx jwks_metrics;", config.ExporterSyslogAddress)
+}
+
+// GenerateExporterConfig generates the exporter config stored next to the nginx config
+// The exporter receives access log records over syslog, so no log file has to be shared
+func (g *ConfigGenerator) GenerateExporterConfig() string {
+	return fmt.Sprintf(`listen:
+  port: %d
+  address: "0.0.0.0"
+  metrics_endpoint: "%s"
+
+namespaces:
+  - name: %s
+    format: %s
+    source:
+      syslog:
+        listen_address: "udp://%s"
+        format: "rfc3164"
+        tags:
+          - "nginx"
+`, config.ExporterPort, config.ExporterMetricsPath, config.ExporterMetricsNamespace,
+		strconv.Quote(metricsLogFormat), config.ExporterSyslogAddress)
+}
+
+// resolveExporterImage returns the exporter image from spec.server.metrics or the global config
+func (m *DeploymentManager) resolveExporterImage(server *v1alpha1.ServerSpec) string {
+	if server != nil && server.Metrics != nil && server.Metrics.Image != "" {
+		return server.Metrics.Image
+	}
+	if m.config != nil && m.config.ExporterImage != "" {
+		return m.config.ExporterImage
+	}
+	return config.DefaultExporterImage
+}
+
+// buildExporterContainer builds the metrics exporter sidecar
+func buildExporterContainer(image string, restricted bool) corev1.Container {
+	container := corev1.Container{
+		Name:  config.ExporterContainerName,
+		Image: image,
+		Args:  []string{"-config-file", nginxConfigMountPath + "/" + config.ConfigMapKeyExporterConfig},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          config.MetricsPortName,
+				ContainerPort: config.ExporterPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      config.VolumeNameNginxConfig,
+				MountPath: nginxConfigMountPath,
+				ReadOnly:  true,
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(config.DefaultExporterCPURequest),
+				corev1.ResourceMemory: resource.MustParse(config.DefaultExporterMemoryRequest),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(config.DefaultExporterMemoryLimit),
+			},
+		},
+	}
+
+	if restricted {
+		container.SecurityContext = restrictedContainerSecurityContext()
+	}
+
+	return container
+}
+
+// applyMetricsExporter adds, updates or removes the exporter sidecar and Prometheus annotations
+// Returns true if the pod template was changed
+func applyMetricsExporter(template *corev1.PodTemplateSpec, enabled bool, image, profile string) bool {
+	changed := false
+	podSpec := &template.Spec
+
+	index := -1
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == config.ExporterContainerName {
+			index = i
+			break
+		}
+	}
+
+	if !enabled {
+		if index >= 0 {
+			podSpec.Containers = append(podSpec.Containers[:index], podSpec.Containers[index+1:]...)
+			changed = true
+		}
+		return syncPrometheusAnnotations(template, false) || changed
+	}
+
+	desired := buildExporterContainer(image, profile != config.SecurityProfileNone)
+	if index < 0 {
+		podSpec.Containers = append(podSpec.Containers, desired)
+		changed = true
+	} else {
+		container := &podSpec.Containers[index]
+		if container.Image != desired.Image ||
+			!equality.Semantic.DeepEqual(container.Args, desired.Args) ||
+			!equality.Semantic.DeepEqual(container.Ports, desired.Ports) ||
+			!equality.Semantic.DeepEqual(container.VolumeMounts, desired.VolumeMounts) ||
+			!equality.Semantic.DeepEqual(container.Resources, desired.Resources) ||
+			!equality.Semantic.DeepEqual(container.SecurityContext, desired.SecurityContext) {
+			container.Image = desired.Image
+			container.Args = desired.Args
+			container.Ports = desired.Ports
+			container.VolumeMounts = desired.VolumeMounts
+			container.Resources = desired.Resources
+			container.SecurityContext = desired.SecurityContext
+			changed = true
+		}
+	}
+
+	return syncPrometheusAnnotations(template, true) || changed
+}
+
+// syncPrometheusAnnotations adds or removes the pod annotations for Prometheus annotation-based discovery
+// Returns true if the pod template was changed
+func syncPrometheusAnnotations(template *corev1.PodTemplateSpec, enabled bool) bool {
+	changed := false
+
+	if !enabled {
+		for key := range prometheusAnnotations {
+			if _, ok := template.Annotations[key]; ok {
+				delete(template.Annotations, key)
+				changed = true
+			}
+		}
+		return changed
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	for key, value := range prometheusAnnotations {
+		if template.Annotations[key] != value {
+			template.Annotations[key] = value
+			changed = true
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,150 @@
+package nginx
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestDeploymentManagerResolveExporterImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.NginxConfig
+		server *v1alpha1.ServerSpec
+		want   string
+	}{
+		{
+			name:   "default",
+			config: &config.NginxConfig{},
+			want:   config.DefaultExporterImage,
+		},
+		{
+			name:   "global config",
+			config: &config.NginxConfig{ExporterImage: "registry.test/exporter:1"},
+			server: &v1alpha1.ServerSpec{Metrics: &v1alpha1.ServerMetricsSpec{Enabled: true}},
+			want:   "registry.test/exporter:1",
+		},
+		{
+			name:   "spec.server.metrics override",
+			config: &config.NginxConfig{ExporterImage: "registry.test/exporter:1"},
+			server: &v1alpha1.ServerSpec{Metrics: &v1alpha1.ServerMetricsSpec{Enabled: true, Image: "registry.test/exporter:2"}},
+			want:   "registry.test/exporter:2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDeploymentManager(nil, tt.config).resolveExporterImage(tt.server); got != tt.want {
+				t.Errorf("resolveExporterImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMetricsExporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		initial     bool
+		enabled     bool
+		wantChanged bool
+	}{
+		{name: "disabled", enabled: false},
+		{name: "enable", enabled: true, wantChanged: true},
+		{name: "up to date", initial: true, enabled: true},
+		{name: "disable", initial: true, enabled: false, wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}},
+			}
+			if tt.initial {
+				applyMetricsExporter(template, true, "exporter:1", config.SecurityProfileRestricted)
+			}
+
+			changed := applyMetricsExporter(template, tt.enabled, "exporter:1", config.SecurityProfileRestricted)
+			if changed != tt.wantChanged {
+				t.Errorf("applyMetricsExporter() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			wantContainers, wantScrape := 1, ""
+			if tt.enabled {
+				wantContainers, wantScrape = 2, "true"
+			}
+			if len(template.Spec.Containers) != wantContainers {
+				t.Fatalf("containers = %d, want %d", len(template.Spec.Containers), wantContainers)
+			}
+			if tt.enabled && template.Spec.Containers[1].Name != config.ExporterContainerName {
+				t.Errorf("sidecar = %q, want %q", template.Spec.Containers[1].Name, config.ExporterContainerName)
+			}
+			if got := template.Annotations["prometheus.io/scrape"]; got != wantScrape {
+				t.Errorf("prometheus.io/scrape = %q, want %q", got, wantScrape)
+			}
+		})
+	}
+}
+
+func TestSyncStatusPort(t *testing.T) {
+	tests := []struct {
+		name        string
+		ports       []corev1.ContainerPort
+		wantChanged bool
+	}{
+		{
+			name:        "adds the port",
+			ports:       []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			wantChanged: true,
+		},
+		{
+			name:        "updates the port",
+			ports:       []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}, {Name: "status", ContainerPort: 9000}},
+			wantChanged: true,
+		},
+		{
+			name:  "up to date",
+			ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}, {Name: "status", ContainerPort: 8081}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{}
+			deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "nginx", Ports: tt.ports}}
+
+			if changed := syncStatusPort(deployment, 8081); changed != tt.wantChanged {
+				t.Errorf("syncStatusPort() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			ports := deployment.Spec.Template.Spec.Containers[0].Ports
+			if len(ports) != 2 || ports[1].Name != "status" || ports[1].ContainerPort != 8081 {
+				t.Errorf("ports = %+v, want http and status 8081", ports)
+			}
+		})
+	}
+}
+
+func TestMetricsExported(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *v1alpha1.ServerSpec
+		want   bool
+	}{
+		{name: "without server spec"},
+		{name: "nginx without exporter", server: &v1alpha1.ServerSpec{}},
+		{name: "nginx with exporter", server: &v1alpha1.ServerSpec{Metrics: &v1alpha1.ServerMetricsSpec{Enabled: true}}, want: true},
+		{name: "built-in server", server: &v1alpha1.ServerSpec{Type: config.ServerTypeBuiltin}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MetricsExported(tt.server); got != tt.want {
+				t.Errorf("MetricsExported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
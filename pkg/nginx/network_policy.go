@@ -0,0 +1,188 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// NetworkPolicyManager manages the NetworkPolicy of the nginx pods
+type NetworkPolicyManager struct {
+	client      client.Client
+	nginxConfig *config.NginxConfig
+}
+
+// NewNetworkPolicyManager creates a new NetworkPolicy manager
+func NewNetworkPolicyManager(client client.Client, nginxConfig *config.NginxConfig) *NetworkPolicyManager {
+	return &NetworkPolicyManager{
+		client:      client,
+		nginxConfig: nginxConfig,
+	}
+}
+
+// ValidateNetworkPolicy checks spec.networkPolicy settings that cannot be expressed in the CRD schema
+func ValidateNetworkPolicy(spec *v1alpha1.NetworkPolicySpec) error {
+	if spec == nil {
+		return nil
+	}
+
+	for i, peer := range spec.From {
+		if peer.NamespaceSelector == nil && peer.PodSelector == nil {
+			return fmt.Errorf("from[%d]: namespaceSelector or podSelector must be set", i)
+		}
+	}
+
+	if peer := spec.IngressController; peer != nil && peer.NamespaceSelector == nil && peer.PodSelector == nil {
+		return fmt.Errorf("ingressController: namespaceSelector or podSelector must be set")
+	}
+
+	return nil
+}
+
+// buildNetworkPolicyPeer converts a peer from spec.networkPolicy
+func buildNetworkPolicyPeer(peer v1alpha1.NetworkPolicyPeer) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: peer.NamespaceSelector,
+		PodSelector:       peer.PodSelector,
+	}
+}
+
+// buildNetworkPolicyPorts converts container ports to TCP NetworkPolicy ports
+func buildNetworkPolicyPorts(ports []int) []networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	policyPorts := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		policyPort := intstr.FromInt(port)
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &policyPort,
+		})
+	}
+	return policyPorts
+}
+
+// buildNetworkPolicySpec builds the NetworkPolicy spec selecting the server pods
+// Ingress is allowed on the server ports only: from the operator namespace, the listed peers and the ingress controller
+// metricsPorts are allowed from every namespace, the location of Prometheus is not known to the operator
+func buildNetworkPolicySpec(
+	name, operatorNamespace string,
+	ports, metricsPorts []int,
+	spec *v1alpha1.NetworkPolicySpec,
+) networkingv1.NetworkPolicySpec {
+	peers := []networkingv1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{corev1.LabelMetadataName: operatorNamespace},
+			},
+		},
+	}
+	for _, peer := range spec.From {
+		peers = append(peers, buildNetworkPolicyPeer(peer))
+	}
+	if spec.IngressController != nil {
+		peers = append(peers, buildNetworkPolicyPeer(*spec.IngressController))
+	}
+
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: buildNetworkPolicyPorts(ports),
+			From:  peers,
+		},
+	}
+	if len(metricsPorts) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: buildNetworkPolicyPorts(metricsPorts),
+			From: []networkingv1.NetworkPolicyPeer{
+				{NamespaceSelector: &metav1.LabelSelector{}},
+			},
+		})
+	}
+
+	return networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: buildSelectorLabels(name),
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		Ingress:     ingress,
+	}
+}
+
+// serverPorts returns the container ports clients connect to, the https port is added if spec.server.tls is set
+func (m *NetworkPolicyManager) serverPorts(server *v1alpha1.ServerSpec) []int {
+	ports := []int{containerPort(m.nginxConfig)}
+	if ServerTLS(server) != nil {
+		ports = append(ports, tlsPort(m.nginxConfig))
+	}
+	return ports
+}
+
+// metricsPorts returns the metrics port if spec.server.metrics.monitor scrapes the server pods
+func metricsPorts(server *v1alpha1.ServerSpec) []int {
+	if server == nil || server.Metrics == nil || server.Metrics.Monitor == nil || !MetricsExported(server) {
+		return nil
+	}
+	return []int{config.ExporterPort}
+}
+
+// EnsureNetworkPolicy creates, updates or deletes the NetworkPolicy of the server pods
+// A NetworkPolicy not created by the operator is never changed
+func (m *NetworkPolicyManager) EnsureNetworkPolicy(
+	ctx context.Context,
+	namespace, name, operatorNamespace string,
+	spec *v1alpha1.NetworkPolicySpec,
+	server *v1alpha1.ServerSpec,
+) error {
+	policy := &networkingv1.NetworkPolicy{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	err := m.client.Get(ctx, key, policy)
+	if apierrors.IsNotFound(err) {
+		if spec == nil {
+			return nil // Not configured, nothing to clean up
+		}
+		policy = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    buildLabels(name),
+			},
+			Spec: buildNetworkPolicySpec(name, operatorNamespace, m.serverPorts(server), metricsPorts(server), spec),
+		}
+		return m.client.Create(ctx, policy)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get NetworkPolicy: %w", err)
+	}
+
+	if !isOperatorManaged(policy, name) {
+		if spec == nil {
+			return nil
+		}
+		return fmt.Errorf("NetworkPolicy %s exists and is not managed by the operator", name)
+	}
+
+	if spec == nil {
+		if err := m.client.Delete(ctx, policy); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete NetworkPolicy: %w", err)
+		}
+		return nil
+	}
+
+	desiredSpec := buildNetworkPolicySpec(name, operatorNamespace, m.serverPorts(server), metricsPorts(server), spec)
+	if equality.Semantic.DeepEqual(policy.Spec, desiredSpec) {
+		return nil
+	}
+	policy.Spec = desiredSpec
+	return m.client.Update(ctx, policy)
+}
@@ -0,0 +1,237 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "gateway"}}
+
+	tests := []struct {
+		name    string
+		spec    *v1alpha1.NetworkPolicySpec
+		wantErr string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "valid peers",
+			spec: &v1alpha1.NetworkPolicySpec{
+				From:              []v1alpha1.NetworkPolicyPeer{{PodSelector: selector}},
+				IngressController: &v1alpha1.NetworkPolicyPeer{NamespaceSelector: selector},
+			},
+		},
+		{
+			name:    "peer without selectors",
+			spec:    &v1alpha1.NetworkPolicySpec{From: []v1alpha1.NetworkPolicyPeer{{PodSelector: selector}, {}}},
+			wantErr: "from[1]",
+		},
+		{
+			name:    "ingress controller without selectors",
+			spec:    &v1alpha1.NetworkPolicySpec{IngressController: &v1alpha1.NetworkPolicyPeer{}},
+			wantErr: "ingressController",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNetworkPolicy(tt.spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateNetworkPolicy() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateNetworkPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildNetworkPolicySpec(t *testing.T) {
+	gateway := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "gateway"}}
+	ingress := &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "ingress-nginx"}}
+
+	tests := []struct {
+		name      string
+		spec      *v1alpha1.NetworkPolicySpec
+		ports     []int
+		wantPeers int
+	}{
+		{
+			name:      "operator namespace only",
+			spec:      &v1alpha1.NetworkPolicySpec{},
+			ports:     []int{8080},
+			wantPeers: 1,
+		},
+		{
+			name: "listed peers and ingress controller",
+			spec: &v1alpha1.NetworkPolicySpec{
+				From:              []v1alpha1.NetworkPolicyPeer{{PodSelector: gateway}},
+				IngressController: &v1alpha1.NetworkPolicyPeer{NamespaceSelector: ingress},
+			},
+			ports:     []int{8080},
+			wantPeers: 3,
+		},
+		{
+			name:      "https port",
+			spec:      &v1alpha1.NetworkPolicySpec{},
+			ports:     []int{8080, 8443},
+			wantPeers: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := buildNetworkPolicySpec("jwks", "operator", tt.ports, nil, tt.spec)
+
+			if len(spec.PodSelector.MatchLabels) == 0 {
+				t.Error("pod selector must select the server pods")
+			}
+			if len(spec.PolicyTypes) != 1 || spec.PolicyTypes[0] != networkingv1.PolicyTypeIngress {
+				t.Errorf("policyTypes = %v, want Ingress", spec.PolicyTypes)
+			}
+			if len(spec.Ingress) != 1 {
+				t.Fatalf("got %d ingress rules, want 1", len(spec.Ingress))
+			}
+
+			rule := spec.Ingress[0]
+			assertPorts(t, "server", rule.Ports, tt.ports)
+			if len(rule.From) != tt.wantPeers {
+				t.Fatalf("got %d peers, want %d", len(rule.From), tt.wantPeers)
+			}
+			if rule.From[0].NamespaceSelector.MatchLabels[corev1.LabelMetadataName] != "operator" {
+				t.Errorf("first peer = %+v, want the operator namespace", rule.From[0])
+			}
+		})
+	}
+}
+
+func TestNetworkPolicyManagerServerPorts(t *testing.T) {
+	tests := []struct {
+		name        string
+		nginxConfig *config.NginxConfig
+		server      *v1alpha1.ServerSpec
+		want        []int
+	}{
+		{name: "defaults", want: []int{config.DefaultNginxPort}},
+		{name: "https", server: &v1alpha1.ServerSpec{TLS: &v1alpha1.ServerTLSSpec{SecretName: "auth-tls"}}, want: []int{config.DefaultNginxPort, config.DefaultNginxTLSPort}},
+		{
+			name:        "configured ports",
+			nginxConfig: &config.NginxConfig{Port: 9090, TLSPort: 9443},
+			server:      &v1alpha1.ServerSpec{TLS: &v1alpha1.ServerTLSSpec{SecretName: "auth-tls"}},
+			want:        []int{9090, 9443},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewNetworkPolicyManager(nil, tt.nginxConfig)
+			if got := manager.serverPorts(tt.server); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serverPorts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildNetworkPolicySpecPorts(t *testing.T) {
+	manager := NewNetworkPolicyManager(nil, &config.NginxConfig{Port: 8080, TLSPort: 8443})
+	monitor := &v1alpha1.MonitorSpec{Kind: config.ServiceMonitorKind}
+
+	tests := []struct {
+		name             string
+		server           *v1alpha1.ServerSpec
+		wantServerPorts  []int
+		wantMetricsPorts []int
+	}{
+		{
+			name:            "default server",
+			server:          nil,
+			wantServerPorts: []int{8080},
+		},
+		{
+			name:            "https listener",
+			server:          &v1alpha1.ServerSpec{TLS: &v1alpha1.ServerTLSSpec{SecretName: "tls"}},
+			wantServerPorts: []int{8080, 8443},
+		},
+		{
+			name:            "metrics without monitor",
+			server:          &v1alpha1.ServerSpec{Metrics: &v1alpha1.ServerMetricsSpec{Enabled: true}},
+			wantServerPorts: []int{8080},
+		},
+		{
+			name:             "monitored nginx metrics",
+			server:           &v1alpha1.ServerSpec{Metrics: &v1alpha1.ServerMetricsSpec{Enabled: true, Monitor: monitor}},
+			wantServerPorts:  []int{8080},
+			wantMetricsPorts: []int{config.ExporterPort},
+		},
+		{
+			name:             "monitored built-in server",
+			server:           &v1alpha1.ServerSpec{Type: config.ServerTypeBuiltin, Metrics: &v1alpha1.ServerMetricsSpec{Monitor: monitor}},
+			wantServerPorts:  []int{8080},
+			wantMetricsPorts: []int{config.ExporterPort},
+		},
+		{
+			name:            "monitor without exported metrics",
+			server:          &v1alpha1.ServerSpec{Metrics: &v1alpha1.ServerMetricsSpec{Monitor: monitor}},
+			wantServerPorts: []int{8080},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := buildNetworkPolicySpec("jwks", "operator", manager.serverPorts(tt.server), metricsPorts(tt.server),
+				&v1alpha1.NetworkPolicySpec{})
+
+			wantRules := 1
+			if len(tt.wantMetricsPorts) > 0 {
+				wantRules = 2
+			}
+			if len(spec.Ingress) != wantRules {
+				t.Fatalf("got %d ingress rules, want %d", len(spec.Ingress), wantRules)
+			}
+
+			assertPorts(t, "server", spec.Ingress[0].Ports, tt.wantServerPorts)
+			if len(spec.Ingress[0].From) != 1 ||
+				spec.Ingress[0].From[0].NamespaceSelector.MatchLabels[corev1.LabelMetadataName] != "operator" {
+				t.Errorf("server ports must be allowed from the operator namespace, got %+v", spec.Ingress[0].From)
+			}
+
+			if wantRules == 2 {
+				assertPorts(t, "metrics", spec.Ingress[1].Ports, tt.wantMetricsPorts)
+				from := spec.Ingress[1].From
+				if len(from) != 1 || from[0].NamespaceSelector == nil || len(from[0].NamespaceSelector.MatchLabels) != 0 {
+					t.Errorf("metrics ports must be allowed from every namespace, got %+v", from)
+				}
+			}
+		})
+	}
+}
+
+// assertPorts checks the TCP ports of a NetworkPolicy rule
+func assertPorts(t *testing.T, rule string, ports []networkingv1.NetworkPolicyPort, want []int) {
+	t.Helper()
+
+	if len(ports) != len(want) {
+		t.Fatalf("%s rule has %d ports, want %v", rule, len(ports), want)
+	}
+	for i, port := range ports {
+		if port.Protocol == nil || *port.Protocol != corev1.ProtocolTCP {
+			t.Errorf("%s rule port %d protocol = %v, want TCP", rule, i, port.Protocol)
+		}
+		if port.Port == nil || port.Port.IntValue() != want[i] {
+			t.Errorf("%s rule port %d = %v, want %d", rule, i, port.Port, want[i])
+		}
+	}
+}
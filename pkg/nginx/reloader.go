@@ -0,0 +1,116 @@
+package nginx
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// reloaderScript watches the mounted nginx config and sends SIGHUP to the nginx master after a change
+// The config embeds the ETag of the JWKS and serves the JWKS snapshot stored with it, so both change together
+// Files are read by nginx on every request and need no reload
+var reloaderScript = fmt.Sprintf(`conf=%s
+last=""
+
+master_pid() {
+  for dir in /proc/[0-9]*; do
+    case "$(tr '\000' ' ' < "$dir/cmdline" 2>/dev/null)" in
+      "nginx: master process"*) echo "${dir#/proc/}"; return ;;
+    esac
+  done
+}
+
+while true; do
+  current=$(cat "$conf"/* 2>/dev/null | md5sum)
+  if [ "$current" != "$last" ]; then
+    pid=$(master_pid)
+    if [ -n "$last" ] && [ -n "$pid" ]; then
+      kill -HUP "$pid" && echo "nginx configuration reloaded"
+    fi
+    last=$current
+  fi
+  sleep %d
+done
+`, nginxConfigMountPath, config.DefaultReloaderInterval)
+
+// buildReloaderContainer builds the sidecar reloading nginx after config changes
+// It uses the nginx image, so no additional image has to be pulled
+func buildReloaderContainer(image string, restricted bool) corev1.Container {
+	container := corev1.Container{
+		Name:    config.ReloaderContainerName,
+		Image:   image,
+		Command: []string{"/bin/sh", "-c", reloaderScript},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      config.VolumeNameNginxConfig,
+				MountPath: nginxConfigMountPath,
+				ReadOnly:  true,
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(config.DefaultReloaderCPURequest),
+				corev1.ResourceMemory: resource.MustParse(config.DefaultReloaderMemoryRequest),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(config.DefaultReloaderMemoryLimit),
+			},
+		},
+	}
+
+	if restricted {
+		container.SecurityContext = restrictedContainerSecurityContext()
+	}
+
+	return container
+}
+
+// applyConfigReloader adds the reloader sidecar to the pod spec or updates the fields set by the operator
+// The pod shares its process namespace, so the sidecar can signal the nginx master
+// Returns true if the pod spec was changed
+func applyConfigReloader(podSpec *corev1.PodSpec, image, profile string) bool {
+	changed := false
+
+	if podSpec.ShareProcessNamespace == nil || !*podSpec.ShareProcessNamespace {
+		shareProcessNamespace := true
+		podSpec.ShareProcessNamespace = &shareProcessNamespace
+		changed = true
+	}
+
+	desired := buildReloaderContainer(image, profile != config.SecurityProfileNone)
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != config.ReloaderContainerName {
+			continue
+		}
+
+		if container.Image != desired.Image ||
+			!equality.Semantic.DeepEqual(container.Command, desired.Command) ||
+			!equality.Semantic.DeepEqual(container.VolumeMounts, desired.VolumeMounts) ||
+			!equality.Semantic.DeepEqual(container.Resources, desired.Resources) ||
+			!equality.Semantic.DeepEqual(container.SecurityContext, desired.SecurityContext) {
+			container.Image = desired.Image
+			container.Command = desired.Command
+			container.VolumeMounts = desired.VolumeMounts
+			container.Resources = desired.Resources
+			container.SecurityContext = desired.SecurityContext
+			changed = true
+		}
+		return changed
+	}
+
+	podSpec.Containers = append(podSpec.Containers, desired)
+	return true
+}
+
+// syncConfigReloader updates an existing Deployment to run the reloader sidecar
+// Returns true if the Deployment was changed
+func (m *DeploymentManager) syncConfigReloader(deployment *appsv1.Deployment, server *v1alpha1.ServerSpec) bool {
+	return applyConfigReloader(&deployment.Spec.Template.Spec, m.resolveImage(server), m.resolveSecurityProfile(server))
+}
@@ -0,0 +1,73 @@
+package nginx
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestApplyConfigReloader(t *testing.T) {
+	tests := []struct {
+		name        string
+		podSpec     func() *corev1.PodSpec
+		image       string
+		profile     string
+		wantChanged bool
+	}{
+		{
+			name:        "adds the sidecar",
+			podSpec:     func() *corev1.PodSpec { return &corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}} },
+			image:       "nginx:1",
+			profile:     config.SecurityProfileRestricted,
+			wantChanged: true,
+		},
+		{
+			name: "up to date",
+			podSpec: func() *corev1.PodSpec {
+				podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}}
+				applyConfigReloader(podSpec, "nginx:1", config.SecurityProfileRestricted)
+				return podSpec
+			},
+			image:   "nginx:1",
+			profile: config.SecurityProfileRestricted,
+		},
+		{
+			name: "follows the image and security profile",
+			podSpec: func() *corev1.PodSpec {
+				podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}}
+				applyConfigReloader(podSpec, "nginx:1", config.SecurityProfileRestricted)
+				return podSpec
+			},
+			image:       "nginx:2",
+			profile:     config.SecurityProfileNone,
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := tt.podSpec()
+
+			if changed := applyConfigReloader(podSpec, tt.image, tt.profile); changed != tt.wantChanged {
+				t.Errorf("applyConfigReloader() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			if podSpec.ShareProcessNamespace == nil || !*podSpec.ShareProcessNamespace {
+				t.Error("pod must share its process namespace with the reloader")
+			}
+			if len(podSpec.Containers) != 2 {
+				t.Fatalf("containers = %d, want nginx and the reloader", len(podSpec.Containers))
+			}
+
+			reloader := podSpec.Containers[1]
+			if reloader.Name != config.ReloaderContainerName || reloader.Image != tt.image {
+				t.Errorf("reloader = %s %s, want %s %s", reloader.Name, reloader.Image, config.ReloaderContainerName, tt.image)
+			}
+			if restricted := tt.profile != config.SecurityProfileNone; (reloader.SecurityContext != nil) != restricted {
+				t.Errorf("reloader security context = %+v, restricted %v", reloader.SecurityContext, restricted)
+			}
+		})
+	}
+}
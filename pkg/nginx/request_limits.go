@@ -0,0 +1,128 @@
+package nginx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+const (
+	// rateLimitZone is the shared memory zone counting requests per client IP
+	rateLimitZone = "jwks_rate_limit"
+	// rateLimitZoneSize holds the state of about 160 thousand client IPs
+	rateLimitZoneSize = "10m"
+)
+
+// allowedMethods are the only request methods served, others are rejected with 405
+var allowedMethods = []string{"GET", "HEAD", "OPTIONS"}
+
+// RequestLimits are the request limits resolved from spec.server with defaults applied
+type RequestLimits struct {
+	// RequestsPerSecond is the rate per client IP, 0 disables rate limiting
+	RequestsPerSecond int
+	// Burst is the number of requests a client IP may send over the rate
+	Burst int
+	// Status is the response status of rejected requests
+	Status int
+	// MaxBodySize is the largest accepted request body in bytes
+	MaxBodySize int64
+}
+
+// ResolveRequestLimits resolves the request limits from spec.server.rateLimit and spec.server.maxBodySize
+func ResolveRequestLimits(rateLimit *v1alpha1.RateLimitSpec, maxBodySize int64) RequestLimits {
+	limits := RequestLimits{
+		Status:      config.DefaultRateLimitStatus,
+		MaxBodySize: maxBodySize,
+	}
+	if limits.MaxBodySize <= 0 {
+		limits.MaxBodySize = config.DefaultMaxBodySize
+	}
+	if rateLimit == nil {
+		return limits
+	}
+
+	limits.RequestsPerSecond = int(rateLimit.RequestsPerSecond)
+	limits.Burst = int(rateLimit.Burst)
+	if rateLimit.Status != 0 {
+		limits.Status = int(rateLimit.Status)
+	}
+	return limits
+}
+
+// RateLimit returns spec.server.rateLimit, nil if requests are not rate limited
+func RateLimit(server *v1alpha1.ServerSpec) *v1alpha1.RateLimitSpec {
+	if server == nil {
+		return nil
+	}
+	return server.RateLimit
+}
+
+// MaxBodySize returns spec.server.maxBodySize in bytes, 0 if not set
+func MaxBodySize(server *v1alpha1.ServerSpec) int64 {
+	if server == nil || server.MaxBodySize == nil {
+		return 0
+	}
+	return server.MaxBodySize.Value()
+}
+
+// Enabled checks if requests are rate limited
+func (l RequestLimits) Enabled() bool {
+	return l.RequestsPerSecond > 0
+}
+
+// Validate checks the limits for values nginx does not accept
+func (l RequestLimits) Validate() error {
+	if l.RequestsPerSecond < 0 || l.Burst < 0 {
+		return fmt.Errorf("requestsPerSecond and burst cannot be negative")
+	}
+	if l.Enabled() && (l.Status < 400 || l.Status > 599) {
+		return fmt.Errorf("rate limit status %d must be between 400 and 599", l.Status)
+	}
+	return nil
+}
+
+// GenerateRateLimitZone generates the http-level zone counting requests per client IP
+// Returns an empty string if rate limiting is disabled
+func (g *ConfigGenerator) GenerateRateLimitZone(limits RequestLimits) string {
+	if !limits.Enabled() {
+		return ""
+	}
+	return fmt.Sprintf("limit_req_zone $binary_remote_addr zone=%s:%s rate=%dr/s;",
+		rateLimitZone, rateLimitZoneSize, limits.RequestsPerSecond)
+}
+
+// GenerateRequestLimitsBlock generates server-level body size and method limits and the rate limit response
+// Methods are checked before locations, so the health check and 404 responses are covered as well
+func (g *ConfigGenerator) GenerateRequestLimitsBlock(limits RequestLimits) string {
+	lines := []string{
+		"    # Request limits",
+		fmt.Sprintf("    client_max_body_size %d;", limits.MaxBodySize),
+		fmt.Sprintf("    if ($request_method !~ ^(%s)$) {", strings.Join(allowedMethods, "|")),
+		"        return 405;",
+		"    }",
+	}
+	if limits.Enabled() {
+		lines = append(lines,
+			fmt.Sprintf("    limit_req_status %d;", limits.Status),
+			"    limit_req_log_level warn;",
+		)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GenerateRateLimitBlock generates the rate limit of a JWKS or discovery location
+// Probes of the health check are not limited, CORS preflights and 304 responses pass the limit in the deferred location
+// Returns an empty string if rate limiting is disabled
+func (g *ConfigGenerator) GenerateRateLimitBlock(limits RequestLimits) string {
+	if !limits.Enabled() {
+		return ""
+	}
+	if limits.Burst > 0 {
+		return fmt.Sprintf(`        # Rate limit
+        limit_req zone=%s burst=%d nodelay;`, rateLimitZone, limits.Burst)
+	}
+	return fmt.Sprintf(`        # Rate limit
+        limit_req zone=%s;`, rateLimitZone)
+}
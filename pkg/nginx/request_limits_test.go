@@ -0,0 +1,94 @@
+package nginx
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestResolveRequestLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		rateLimit   *v1alpha1.RateLimitSpec
+		maxBodySize int64
+		want        RequestLimits
+	}{
+		{
+			name: "defaults",
+			want: RequestLimits{Status: config.DefaultRateLimitStatus, MaxBodySize: config.DefaultMaxBodySize},
+		},
+		{
+			name:        "rate limit with default status",
+			rateLimit:   &v1alpha1.RateLimitSpec{RequestsPerSecond: 10, Burst: 5},
+			maxBodySize: 64,
+			want:        RequestLimits{RequestsPerSecond: 10, Burst: 5, Status: config.DefaultRateLimitStatus, MaxBodySize: 64},
+		},
+		{
+			name:      "custom status",
+			rateLimit: &v1alpha1.RateLimitSpec{RequestsPerSecond: 1, Status: 503},
+			want:      RequestLimits{RequestsPerSecond: 1, Status: 503, MaxBodySize: config.DefaultMaxBodySize},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveRequestLimits(tt.rateLimit, tt.maxBodySize); got != tt.want {
+				t.Errorf("ResolveRequestLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLimitsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  RequestLimits
+		wantErr string
+	}{
+		{name: "disabled", limits: RequestLimits{Status: 200}},
+		{name: "enabled", limits: RequestLimits{RequestsPerSecond: 10, Status: 429}},
+		{name: "negative burst", limits: RequestLimits{RequestsPerSecond: 10, Burst: -1, Status: 429}, wantErr: "cannot be negative"},
+		{name: "status out of range", limits: RequestLimits{RequestsPerSecond: 10, Status: 302}, wantErr: "must be between 400 and 599"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	size := resource.MustParse("2Ki")
+
+	tests := []struct {
+		name   string
+		server *v1alpha1.ServerSpec
+		want   int64
+	}{
+		{name: "without server spec"},
+		{name: "not set", server: &v1alpha1.ServerSpec{}},
+		{name: "quantity", server: &v1alpha1.ServerSpec{MaxBodySize: &size}, want: 2048},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxBodySize(tt.server); got != tt.want {
+				t.Errorf("MaxBodySize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,21 +9,23 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
 )
 
 // ServiceManager manages nginx Service resources
 type ServiceManager struct {
 	client client.Client
+	config *config.NginxConfig
 }
 
 // NewServiceManager creates a new nginx Service manager
-func NewServiceManager(client client.Client) *ServiceManager {
+func NewServiceManager(client client.Client, nginxConfig *config.NginxConfig) *ServiceManager {
 	return &ServiceManager{
 		client: client,
+		config: nginxConfig,
 	}
 }
 
@@ -32,6 +34,8 @@ func (m *ServiceManager) EnsureService(
 	ctx context.Context,
 	namespace string,
 	jwksConfigName string,
+	settings *v1alpha1.ServiceSpec,
+	tls *v1alpha1.ServerTLSSpec,
 ) error {
 	serviceName := jwksConfigName
 
@@ -41,51 +45,15 @@ func (m *ServiceManager) EnsureService(
 
 	err := m.client.Get(ctx, key, service)
 	if err == nil {
-		// Service exists, check if selector matches Deployment PodTemplate labels
-		// Get Deployment to check actual PodTemplate labels
-		deploymentName := jwksConfigName
-		deployment := &appsv1.Deployment{}
-		deploymentKey := types.NamespacedName{Namespace: namespace, Name: deploymentName}
-
-		// Try to get Deployment with exact name first
-		deploymentErr := m.client.Get(ctx, deploymentKey, deployment)
-		if deploymentErr != nil {
-			// Try with nginx- prefix (for backward compatibility)
-			deploymentKey.Name = "nginx-" + jwksConfigName
-			deploymentErr = m.client.Get(ctx, deploymentKey, deployment)
+		// Service exists, reconcile selector and settings from spec.service
+		changed := m.syncSelector(ctx, service, namespace, jwksConfigName)
+		if applyServiceSettings(service, settings, containerPort(m.config), tls, tlsPort(m.config)) {
+			changed = true
 		}
 
-		if deploymentErr == nil {
-			// Get actual labels from Deployment PodTemplate (not Deployment labels)
-			podLabels := deployment.Spec.Template.Labels
-			actualApp := podLabels[config.LabelApp]
-
-			// Build expected selector - simplified to use only app label
-			expectedSelector := map[string]string{
-				config.LabelApp: jwksConfigName, // Use JWKS resource name as app label value
-			}
-
-			// If Deployment PodTemplate has app label, use it (for compatibility)
-			if actualApp != "" {
-				expectedSelector[config.LabelApp] = actualApp
-			}
-
-			// Update Service selector if it doesn't match
-			if !selectorsEqual(service.Spec.Selector, expectedSelector) {
-				service.Spec.Selector = expectedSelector
-				return m.client.Update(ctx, service)
-			}
-		} else {
-			// Deployment not found - update selector to simplified version
-			expectedSelector := map[string]string{
-				config.LabelApp: jwksConfigName,
-			}
-			if !selectorsEqual(service.Spec.Selector, expectedSelector) {
-				service.Spec.Selector = expectedSelector
-				return m.client.Update(ctx, service)
-			}
+		if changed {
+			return m.client.Update(ctx, service)
 		}
-
 		return nil
 	}
 
@@ -94,10 +62,47 @@ func (m *ServiceManager) EnsureService(
 	}
 
 	// Create new Service with simplified selector (only app label)
-	service = m.createService(serviceName, namespace, jwksConfigName)
+	service = m.createService(serviceName, namespace, jwksConfigName, settings, tls)
 	return m.client.Create(ctx, service)
 }
 
+// syncSelector updates Service selector to match Deployment PodTemplate labels
+// Returns true if the selector was changed
+func (m *ServiceManager) syncSelector(ctx context.Context, service *corev1.Service, namespace, jwksConfigName string) bool {
+	// Get Deployment to check actual PodTemplate labels
+	deploymentName := jwksConfigName
+	deployment := &appsv1.Deployment{}
+	deploymentKey := types.NamespacedName{Namespace: namespace, Name: deploymentName}
+
+	// Try to get Deployment with exact name first
+	deploymentErr := m.client.Get(ctx, deploymentKey, deployment)
+	if deploymentErr != nil {
+		// Try with nginx- prefix (for backward compatibility)
+		deploymentKey.Name = "nginx-" + jwksConfigName
+		deploymentErr = m.client.Get(ctx, deploymentKey, deployment)
+	}
+
+	// Build expected selector - simplified to use only app label
+	expectedSelector := map[string]string{
+		config.LabelApp: jwksConfigName, // Use JWKS resource name as app label value
+	}
+
+	if deploymentErr == nil {
+		// Get actual labels from Deployment PodTemplate (not Deployment labels)
+		// If Deployment PodTemplate has app label, use it (for compatibility)
+		if actualApp := deployment.Spec.Template.Labels[config.LabelApp]; actualApp != "" {
+			expectedSelector[config.LabelApp] = actualApp
+		}
+	}
+
+	// Update Service selector if it doesn't match
+	if selectorsEqual(service.Spec.Selector, expectedSelector) {
+		return false
+	}
+	service.Spec.Selector = expectedSelector
+	return true
+}
+
 // selectorsEqual checks if two selectors are equal
 func selectorsEqual(s1, s2 map[string]string) bool {
 	if len(s1) != len(s2) {
@@ -114,8 +119,10 @@ func selectorsEqual(s1, s2 map[string]string) bool {
 // createService creates a new nginx Service spec
 func (m *ServiceManager) createService(
 	name, namespace, jwksConfigName string,
+	settings *v1alpha1.ServiceSpec,
+	tls *v1alpha1.ServerTLSSpec,
 ) *corev1.Service {
-	return &corev1.Service{
+	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -126,30 +133,29 @@ func (m *ServiceManager) createService(
 			},
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeClusterIP,
 			Selector: map[string]string{
 				config.LabelApp: jwksConfigName, // Simplified selector - only app label
 			},
-			Ports: []corev1.ServicePort{
-				{
-					Name:       "http",
-					Port:       80,
-					TargetPort: intstr.FromInt(80),
-					Protocol:   corev1.ProtocolTCP,
-				},
-			},
 		},
 	}
+
+	applyServiceSettings(service, settings, containerPort(m.config), tls, tlsPort(m.config))
+	return service
 }
 
-// DeleteService deletes nginx Service
+// DeleteService deletes nginx Service, a Service not created by the operator is kept
 func (m *ServiceManager) DeleteService(ctx context.Context, namespace, jwksConfigName string) error {
-	serviceName := jwksConfigName
-	service := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceName,
-			Namespace: namespace,
-		},
+	service := &corev1.Service{}
+	key := types.NamespacedName{Namespace: namespace, Name: jwksConfigName}
+	if err := m.client.Get(ctx, key, service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil // Already deleted, nothing to do
+		}
+		return fmt.Errorf("failed to get nginx Service: %w", err)
+	}
+
+	if !isOperatorManaged(service, jwksConfigName) {
+		return nil // Not created by the operator, keep it
 	}
 
 	err := m.client.Delete(ctx, service)
@@ -0,0 +1,135 @@
+package nginx
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/utils"
+)
+
+// ServicePort returns the Service port from spec.service or the default
+func ServicePort(settings *v1alpha1.ServiceSpec) int32 {
+	if settings != nil && settings.Port > 0 {
+		return settings.Port
+	}
+	return config.DefaultServicePort
+}
+
+// serviceType returns the Service type from spec.service or ClusterIP
+func serviceType(settings *v1alpha1.ServiceSpec) corev1.ServiceType {
+	if settings != nil && settings.Type != "" {
+		return settings.Type
+	}
+	return corev1.ServiceTypeClusterIP
+}
+
+// containerPort returns the server container port from the global nginx config
+func containerPort(nginxConfig *config.NginxConfig) int {
+	if nginxConfig != nil && nginxConfig.Port > 0 {
+		return nginxConfig.Port
+	}
+	return config.DefaultNginxPort
+}
+
+// applyServiceSettings sets type, ports, annotations and traffic settings from spec.service
+// The https port is added if spec.server.tls is set, tlsTargetPort is the container port of the https listener
+// Node ports allocated by Kubernetes are kept. Returns true if the Service was changed
+func applyServiceSettings(
+	service *corev1.Service,
+	settings *v1alpha1.ServiceSpec,
+	targetPort int,
+	tls *v1alpha1.ServerTLSSpec,
+	tlsTargetPort int,
+) bool {
+	changed := false
+	desiredType := serviceType(settings)
+
+	if service.Spec.Type != desiredType {
+		service.Spec.Type = desiredType
+		changed = true
+	}
+
+	desiredPorts := []corev1.ServicePort{
+		{
+			Name:       "http",
+			Port:       ServicePort(settings),
+			TargetPort: intstr.FromInt(targetPort),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+	if tls != nil {
+		desiredPorts = append(desiredPorts, corev1.ServicePort{
+			Name:       "https",
+			Port:       TLSServicePort(tls),
+			TargetPort: intstr.FromInt(tlsTargetPort),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+	if desiredType != corev1.ServiceTypeClusterIP {
+		keepNodePorts(service.Spec.Ports, desiredPorts)
+	}
+	if !equality.Semantic.DeepEqual(service.Spec.Ports, desiredPorts) {
+		service.Spec.Ports = desiredPorts
+		changed = true
+	}
+
+	var sourceRanges []string
+	if settings != nil && desiredType == corev1.ServiceTypeLoadBalancer {
+		sourceRanges = settings.LoadBalancerSourceRanges
+	}
+	if !equality.Semantic.DeepEqual(service.Spec.LoadBalancerSourceRanges, sourceRanges) {
+		service.Spec.LoadBalancerSourceRanges = sourceRanges
+		changed = true
+	}
+
+	if applyExternalTrafficPolicy(service, settings, desiredType) {
+		changed = true
+	}
+
+	var annotations map[string]string
+	if settings != nil {
+		annotations = settings.Annotations
+	}
+	if utils.ApplyManagedAnnotations(service, annotations) {
+		changed = true
+	}
+
+	return changed
+}
+
+// keepNodePorts copies node ports allocated by Kubernetes to the desired ports with the same name
+func keepNodePorts(current, desired []corev1.ServicePort) {
+	for i := range desired {
+		for _, port := range current {
+			if port.Name == desired[i].Name {
+				desired[i].NodePort = port.NodePort
+			}
+		}
+	}
+}
+
+// applyExternalTrafficPolicy sets external traffic policy, which is only allowed for NodePort and LoadBalancer
+// An empty policy in spec.service keeps the value defaulted by Kubernetes
+func applyExternalTrafficPolicy(service *corev1.Service, settings *v1alpha1.ServiceSpec, desiredType corev1.ServiceType) bool {
+	if desiredType == corev1.ServiceTypeClusterIP {
+		if service.Spec.ExternalTrafficPolicy == "" && service.Spec.HealthCheckNodePort == 0 {
+			return false
+		}
+		service.Spec.ExternalTrafficPolicy = ""
+		service.Spec.HealthCheckNodePort = 0
+		return true
+	}
+
+	if settings == nil || settings.ExternalTrafficPolicy == "" || service.Spec.ExternalTrafficPolicy == settings.ExternalTrafficPolicy {
+		return false
+	}
+
+	service.Spec.ExternalTrafficPolicy = settings.ExternalTrafficPolicy
+	if settings.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyLocal {
+		service.Spec.HealthCheckNodePort = 0
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package nginx
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestApplyServiceSettings(t *testing.T) {
+	// newService returns a Service as created with default settings
+	newService := func() *corev1.Service {
+		service := &corev1.Service{}
+		applyServiceSettings(service, nil, 8080, nil, 8443)
+		return service
+	}
+
+	tests := []struct {
+		name            string
+		service         func() *corev1.Service
+		settings        *v1alpha1.ServiceSpec
+		tls             *v1alpha1.ServerTLSSpec
+		wantChanged     bool
+		wantType        corev1.ServiceType
+		wantPort        int32
+		wantNodePort    int32
+		wantHTTPSPort   int32
+		wantPolicy      corev1.ServiceExternalTrafficPolicy
+		wantAnnotations map[string]string
+	}{
+		{
+			name:            "defaults are up to date",
+			service:         newService,
+			wantType:        corev1.ServiceTypeClusterIP,
+			wantPort:        config.DefaultServicePort,
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:    "load balancer with annotations",
+			service: newService,
+			settings: &v1alpha1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				Port:                  443,
+				Annotations:           map[string]string{"lb/internal": "true"},
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+			},
+			wantChanged: true,
+			wantType:    corev1.ServiceTypeLoadBalancer,
+			wantPort:    443,
+			wantPolicy:  corev1.ServiceExternalTrafficPolicyLocal,
+			wantAnnotations: map[string]string{
+				"lb/internal":                       "true",
+				config.AnnotationManagedAnnotations: "lb/internal",
+			},
+		},
+		{
+			name: "allocated node port is kept",
+			service: func() *corev1.Service {
+				service := &corev1.Service{}
+				applyServiceSettings(service, &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort}, 8080, nil, 8443)
+				service.Spec.Ports[0].NodePort = 30080
+				return service
+			},
+			settings:        &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+			wantType:        corev1.ServiceTypeNodePort,
+			wantPort:        config.DefaultServicePort,
+			wantNodePort:    30080,
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name: "removed annotation, user annotation is kept",
+			service: func() *corev1.Service {
+				service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"team": "auth"}}}
+				applyServiceSettings(service, &v1alpha1.ServiceSpec{Annotations: map[string]string{"lb/internal": "true"}}, 8080, nil, 8443)
+				return service
+			},
+			settings:        nil,
+			wantChanged:     true,
+			wantType:        corev1.ServiceTypeClusterIP,
+			wantPort:        config.DefaultServicePort,
+			wantAnnotations: map[string]string{"team": "auth"},
+		},
+		{
+			name: "back to ClusterIP drops traffic policy",
+			service: func() *corev1.Service {
+				service := &corev1.Service{}
+				applyServiceSettings(service, &v1alpha1.ServiceSpec{
+					Type:                  corev1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+				}, 8080, nil, 8443)
+				service.Spec.HealthCheckNodePort = 31000
+				return service
+			},
+			settings:        &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+			wantChanged:     true,
+			wantType:        corev1.ServiceTypeClusterIP,
+			wantPort:        config.DefaultServicePort,
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:            "https port added",
+			service:         newService,
+			tls:             &v1alpha1.ServerTLSSpec{SecretName: "auth-tls"},
+			wantChanged:     true,
+			wantType:        corev1.ServiceTypeClusterIP,
+			wantPort:        config.DefaultServicePort,
+			wantHTTPSPort:   config.DefaultTLSServicePort,
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name: "https node ports are kept",
+			service: func() *corev1.Service {
+				service := &corev1.Service{}
+				applyServiceSettings(service, &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort}, 8080,
+					&v1alpha1.ServerTLSSpec{SecretName: "auth-tls", Port: 9443}, 8443)
+				service.Spec.Ports[0].NodePort = 30080
+				service.Spec.Ports[1].NodePort = 30443
+				return service
+			},
+			settings:        &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+			tls:             &v1alpha1.ServerTLSSpec{SecretName: "auth-tls", Port: 9443},
+			wantType:        corev1.ServiceTypeNodePort,
+			wantPort:        config.DefaultServicePort,
+			wantNodePort:    30080,
+			wantHTTPSPort:   9443,
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name: "https port removed",
+			service: func() *corev1.Service {
+				service := &corev1.Service{}
+				applyServiceSettings(service, nil, 8080, &v1alpha1.ServerTLSSpec{SecretName: "auth-tls"}, 8443)
+				return service
+			},
+			wantChanged:     true,
+			wantType:        corev1.ServiceTypeClusterIP,
+			wantPort:        config.DefaultServicePort,
+			wantAnnotations: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.service()
+
+			if changed := applyServiceSettings(service, tt.settings, 8080, tt.tls, 8443); changed != tt.wantChanged {
+				t.Errorf("applyServiceSettings() = %v, want %v", changed, tt.wantChanged)
+			}
+			if service.Spec.Type != tt.wantType {
+				t.Errorf("type = %s, want %s", service.Spec.Type, tt.wantType)
+			}
+			wantPorts := 1
+			if tt.wantHTTPSPort != 0 {
+				wantPorts = 2
+			}
+			if len(service.Spec.Ports) != wantPorts {
+				t.Fatalf("got %d ports, want %d", len(service.Spec.Ports), wantPorts)
+			}
+			port := service.Spec.Ports[0]
+			if port.Port != tt.wantPort || port.TargetPort != intstr.FromInt(8080) || port.NodePort != tt.wantNodePort {
+				t.Errorf("port = %+v, want port %d, target 8080, node port %d", port, tt.wantPort, tt.wantNodePort)
+			}
+			if tt.wantHTTPSPort != 0 {
+				https := service.Spec.Ports[1]
+				if https.Name != "https" || https.Port != tt.wantHTTPSPort || https.TargetPort != intstr.FromInt(8443) {
+					t.Errorf("https port = %+v, want port %d, target 8443", https, tt.wantHTTPSPort)
+				}
+				if tt.wantNodePort != 0 && https.NodePort == 0 {
+					t.Error("allocated https node port must be kept")
+				}
+			}
+			if service.Spec.ExternalTrafficPolicy != tt.wantPolicy || (tt.wantPolicy == "" && service.Spec.HealthCheckNodePort != 0) {
+				t.Errorf("externalTrafficPolicy = %q, healthCheckNodePort = %d, want %q",
+					service.Spec.ExternalTrafficPolicy, service.Spec.HealthCheckNodePort, tt.wantPolicy)
+			}
+			if !reflect.DeepEqual(service.Annotations, tt.wantAnnotations) {
+				t.Errorf("annotations = %v, want %v", service.Annotations, tt.wantAnnotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package nginx
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// StubStatusPath is the path of the nginx stub_status page on the status port
+const StubStatusPath = "/stub_status"
+
+// statusPort returns the internal status port from the global nginx config
+func statusPort(nginxConfig *config.NginxConfig) int {
+	if nginxConfig != nil && nginxConfig.StatusPort > 0 {
+		return nginxConfig.StatusPort
+	}
+	return config.DefaultNginxStatusPort
+}
+
+// GenerateStatusServerBlock generates the internal server block with stub_status and the health location
+// stub_status is only answered for requests from inside the pod (sidecars, kubectl port-forward)
+// The Service does not expose the status port
+func (g *ConfigGenerator) GenerateStatusServerBlock() string {
+	return fmt.Sprintf(`server {
+    listen %d;
+    server_name _;
+    access_log off;
+
+    location = %s {
+        stub_status;
+        allow 127.0.0.1;
+        allow ::1;
+        deny all;
+    }
+
+%s
+
+%s
+}`, g.statusPort, StubStatusPath, g.GenerateHealthLocationBlock(), g.GenerateNotFoundLocationBlock())
+}
+
+// syncStatusPort adds or updates the status container port of the nginx container
+// Returns true if the Deployment was changed
+func syncStatusPort(deployment *appsv1.Deployment, port int) bool {
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return false
+	}
+
+	container := &containers[0]
+	desiredPort := safeIntToInt32(port)
+	for i := range container.Ports {
+		if container.Ports[i].Name != "status" {
+			continue
+		}
+		if container.Ports[i].ContainerPort == desiredPort {
+			return false
+		}
+		container.Ports[i].ContainerPort = desiredPort
+		return true
+	}
+
+	container.Ports = append(container.Ports, corev1.ContainerPort{
+		Name:          "status",
+		ContainerPort: desiredPort,
+		Protocol:      corev1.ProtocolTCP,
+	})
+	return true
+}
@@ -0,0 +1,229 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// tlsCiphers are the TLS 1.2 cipher suites of the https listener (Mozilla "intermediate")
+// TLS 1.3 suites are not configurable in nginx and are all secure
+const tlsCiphers = "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:" +
+	"ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:" +
+	"ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305"
+
+// ServerTLS returns spec.server.tls, nil if the server does not serve https
+func ServerTLS(server *v1alpha1.ServerSpec) *v1alpha1.ServerTLSSpec {
+	if server == nil || server.TLS == nil || server.TLS.SecretName == "" {
+		return nil
+	}
+	return server.TLS
+}
+
+// TLSServicePort returns the https Service port from spec.server.tls or the default
+func TLSServicePort(tls *v1alpha1.ServerTLSSpec) int32 {
+	if tls != nil && tls.Port > 0 {
+		return tls.Port
+	}
+	return config.DefaultTLSServicePort
+}
+
+// tlsPort returns the container port of the https listener from the global nginx config
+func tlsPort(nginxConfig *config.NginxConfig) int {
+	if nginxConfig != nil && nginxConfig.TLSPort > 0 {
+		return nginxConfig.TLSPort
+	}
+	return config.DefaultNginxTLSPort
+}
+
+// serverTLSPort returns the container port of the https listener, 0 if spec.server.tls is not set
+func (m *DeploymentManager) serverTLSPort(server *v1alpha1.ServerSpec) int {
+	if ServerTLS(server) == nil {
+		return 0
+	}
+	return tlsPort(m.config)
+}
+
+// TLSCertificatePath and TLSKeyPath are the paths of the mounted certificate and key in server pods
+var (
+	TLSCertificatePath = filepath.Join(config.TLSMountPath, config.SecretKeyTLSCert)
+	TLSKeyPath         = filepath.Join(config.TLSMountPath, config.SecretKeyTLSKey)
+)
+
+// GenerateTLSListenBlock generates the https listener of the JWKS server block
+// Session tickets are disabled, their keys would not be rotated without restarts
+func (g *ConfigGenerator) GenerateTLSListenBlock() string {
+	return fmt.Sprintf(`    listen %d ssl;
+    ssl_certificate %s;
+    ssl_certificate_key %s;
+    ssl_protocols TLSv1.2 TLSv1.3;
+    ssl_ciphers %s;
+    ssl_prefer_server_ciphers off;
+    ssl_session_cache shared:jwks_tls:1m;
+    ssl_session_timeout 1d;
+    ssl_session_tickets off;`, g.tlsPort, TLSCertificatePath, TLSKeyPath, tlsCiphers)
+}
+
+// tlsSecretHash returns the hash of the TLS Secret from spec.server.tls, empty if https is not enabled
+// The hash is recorded on the pod template, so a renewed certificate rolls the server pods
+func (m *DeploymentManager) tlsSecretHash(ctx context.Context, namespace string, tls *v1alpha1.ServerTLSSpec) (string, error) {
+	if tls == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: tls.SecretName}
+	if err := m.client.Get(ctx, key, secret); err != nil {
+		return "", fmt.Errorf("failed to get TLS Secret %s: %w", tls.SecretName, err)
+	}
+	for _, dataKey := range []string{config.SecretKeyTLSCert, config.SecretKeyTLSKey} {
+		if len(secret.Data[dataKey]) == 0 {
+			return "", fmt.Errorf("TLS Secret %s has no %s", tls.SecretName, dataKey)
+		}
+	}
+
+	return TLSSecretHash(secret), nil
+}
+
+// TLSSecretHash computes the hash of the certificate and key of a TLS Secret
+func TLSSecretHash(secret *corev1.Secret) string {
+	return computeConfigMapHash(&corev1.ConfigMap{
+		BinaryData: map[string][]byte{
+			config.SecretKeyTLSCert: secret.Data[config.SecretKeyTLSCert],
+			config.SecretKeyTLSKey:  secret.Data[config.SecretKeyTLSKey],
+		},
+	})
+}
+
+// tlsVolume returns the volume with the TLS Secret
+// The mode is set to the API server default, so the volume compares equal after defaulting
+func tlsVolume(tls *v1alpha1.ServerTLSSpec) corev1.Volume {
+	defaultMode := corev1.SecretVolumeSourceDefaultMode
+	return corev1.Volume{
+		Name: config.VolumeNameTLS,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  tls.SecretName,
+				DefaultMode: &defaultMode,
+				Items: []corev1.KeyToPath{
+					{Key: config.SecretKeyTLSCert, Path: config.SecretKeyTLSCert},
+					{Key: config.SecretKeyTLSKey, Path: config.SecretKeyTLSKey},
+				},
+			},
+		},
+	}
+}
+
+// tlsVolumeMount returns the mount of the TLS Secret
+func tlsVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      config.VolumeNameTLS,
+		MountPath: config.TLSMountPath,
+		ReadOnly:  true,
+	}
+}
+
+// syncTLS adds or removes the TLS Secret volume, the https container port and the Secret hash of the server pods
+// The volume and mount are kept in front of the scratch volumes, which applySecurityProfile appends last
+// Returns true if the pod template was changed
+func syncTLS(template *corev1.PodTemplateSpec, tls *v1alpha1.ServerTLSSpec, port int, secretHash string) bool {
+	podSpec := &template.Spec
+	if len(podSpec.Containers) == 0 {
+		return false
+	}
+	container := &podSpec.Containers[0]
+	changed := false
+
+	volumes := make([]corev1.Volume, 0, len(podSpec.Volumes)+1)
+	for _, vol := range podSpec.Volumes {
+		if vol.Name != config.VolumeNameTLS {
+			volumes = append(volumes, vol)
+		}
+	}
+	mounts := make([]corev1.VolumeMount, 0, len(container.VolumeMounts)+1)
+	for _, mount := range container.VolumeMounts {
+		if mount.Name != config.VolumeNameTLS {
+			mounts = append(mounts, mount)
+		}
+	}
+	ports := make([]corev1.ContainerPort, 0, len(container.Ports)+1)
+	for _, containerPort := range container.Ports {
+		if containerPort.Name != "https" {
+			ports = append(ports, containerPort)
+		}
+	}
+
+	if tls != nil {
+		volumes = insertBeforeScratchVolumes(volumes, tlsVolume(tls))
+		mounts = insertBeforeScratchMounts(mounts, tlsVolumeMount())
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "https",
+			ContainerPort: safeIntToInt32(port),
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+
+	if !equality.Semantic.DeepEqual(podSpec.Volumes, volumes) {
+		podSpec.Volumes = volumes
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(container.VolumeMounts, mounts) {
+		container.VolumeMounts = mounts
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(container.Ports, ports) {
+		container.Ports = ports
+		changed = true
+	}
+
+	if template.Annotations[config.AnnotationTLSSecretHash] != secretHash {
+		if secretHash == "" {
+			delete(template.Annotations, config.AnnotationTLSSecretHash)
+		} else {
+			if template.Annotations == nil {
+				template.Annotations = make(map[string]string)
+			}
+			template.Annotations[config.AnnotationTLSSecretHash] = secretHash
+		}
+		changed = true
+	}
+
+	return changed
+}
+
+// insertBeforeScratchVolumes inserts the volume in front of the first scratch volume
+func insertBeforeScratchVolumes(volumes []corev1.Volume, volume corev1.Volume) []corev1.Volume {
+	index := len(volumes)
+	for i, vol := range volumes {
+		if isScratchVolume(vol.Name) {
+			index = i
+			break
+		}
+	}
+	volumes = append(volumes, corev1.Volume{})
+	copy(volumes[index+1:], volumes[index:])
+	volumes[index] = volume
+	return volumes
+}
+
+// insertBeforeScratchMounts inserts the mount in front of the first scratch mount
+func insertBeforeScratchMounts(mounts []corev1.VolumeMount, mount corev1.VolumeMount) []corev1.VolumeMount {
+	index := len(mounts)
+	for i, m := range mounts {
+		if isScratchVolume(m.Name) {
+			index = i
+			break
+		}
+	}
+	mounts = append(mounts, corev1.VolumeMount{})
+	copy(mounts[index+1:], mounts[index:])
+	mounts[index] = mount
+	return mounts
+}
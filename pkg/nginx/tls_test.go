@@ -0,0 +1,129 @@
+package nginx
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestSyncTLS(t *testing.T) {
+	tls := &v1alpha1.ServerTLSSpec{SecretName: "auth-tls"}
+
+	// newTemplate returns a restricted server pod template, with https enabled if tls is set
+	newTemplate := func(tls *v1alpha1.ServerTLSSpec, secretHash string) *corev1.PodTemplateSpec {
+		template := &corev1.PodTemplateSpec{}
+		template.Spec.Volumes = []corev1.Volume{{Name: config.VolumeNameJWKSData}, {Name: config.VolumeNameTmp}}
+		template.Spec.Containers = []corev1.Container{{
+			Ports:        []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			VolumeMounts: []corev1.VolumeMount{{Name: config.VolumeNameJWKSData}, {Name: config.VolumeNameTmp}},
+		}}
+		syncTLS(template, tls, 8443, secretHash)
+		return template
+	}
+
+	tests := []struct {
+		name        string
+		template    *corev1.PodTemplateSpec
+		tls         *v1alpha1.ServerTLSSpec
+		secretHash  string
+		wantChanged bool
+		wantVolumes []string
+	}{
+		{
+			name:        "without TLS",
+			template:    newTemplate(nil, ""),
+			wantVolumes: []string{config.VolumeNameJWKSData, config.VolumeNameTmp},
+		},
+		{
+			name:        "TLS enabled",
+			template:    newTemplate(nil, ""),
+			tls:         tls,
+			secretHash:  "v1",
+			wantChanged: true,
+			wantVolumes: []string{config.VolumeNameJWKSData, config.VolumeNameTLS, config.VolumeNameTmp},
+		},
+		{
+			name:        "up to date",
+			template:    newTemplate(tls, "v1"),
+			tls:         tls,
+			secretHash:  "v1",
+			wantVolumes: []string{config.VolumeNameJWKSData, config.VolumeNameTLS, config.VolumeNameTmp},
+		},
+		{
+			name:        "renewed certificate",
+			template:    newTemplate(tls, "v1"),
+			tls:         tls,
+			secretHash:  "v2",
+			wantChanged: true,
+			wantVolumes: []string{config.VolumeNameJWKSData, config.VolumeNameTLS, config.VolumeNameTmp},
+		},
+		{
+			name:        "TLS disabled",
+			template:    newTemplate(tls, "v1"),
+			wantChanged: true,
+			wantVolumes: []string{config.VolumeNameJWKSData, config.VolumeNameTmp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := syncTLS(tt.template, tt.tls, 8443, tt.secretHash); changed != tt.wantChanged {
+				t.Errorf("syncTLS() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			podSpec := tt.template.Spec
+			container := podSpec.Containers[0]
+			if len(podSpec.Volumes) != len(tt.wantVolumes) || len(container.VolumeMounts) != len(tt.wantVolumes) {
+				t.Fatalf("volumes = %+v, mounts = %+v, want %v", podSpec.Volumes, container.VolumeMounts, tt.wantVolumes)
+			}
+			for i, name := range tt.wantVolumes {
+				if podSpec.Volumes[i].Name != name || container.VolumeMounts[i].Name != name {
+					t.Errorf("volume %d = %s, mount = %s, want %s", i, podSpec.Volumes[i].Name, container.VolumeMounts[i].Name, name)
+				}
+			}
+
+			wantPorts := 1
+			if tt.tls != nil {
+				wantPorts = 2
+			}
+			if len(container.Ports) != wantPorts {
+				t.Errorf("ports = %+v, want %d ports", container.Ports, wantPorts)
+			}
+			if got := tt.template.Annotations[config.AnnotationTLSSecretHash]; got != tt.secretHash {
+				t.Errorf("secret hash annotation = %q, want %q", got, tt.secretHash)
+			}
+		})
+	}
+}
+
+func TestTLSSecretHash(t *testing.T) {
+	newSecret := func(cert, key string) *corev1.Secret {
+		return &corev1.Secret{Data: map[string][]byte{
+			config.SecretKeyTLSCert: []byte(cert),
+			config.SecretKeyTLSKey:  []byte(key),
+			config.SecretKeyCACert:  []byte("ca"),
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		secret    *corev1.Secret
+		wantEqual bool
+	}{
+		{name: "same certificate", secret: newSecret("cert", "key"), wantEqual: true},
+		{name: "renewed certificate", secret: newSecret("cert2", "key2")},
+		{name: "rotated key", secret: newSecret("cert", "key2")},
+	}
+
+	base := TLSSecretHash(newSecret("cert", "key"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TLSSecretHash(tt.secret); (got == base) != tt.wantEqual {
+				t.Errorf("TLSSecretHash() = %s, base %s, want equal %v", got, base, tt.wantEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// ConfigMapSink writes JWKS files into a ConfigMap
+type ConfigMapSink struct {
+	client   client.Client
+	name     string
+	encoding string
+	labels   map[string]string
+}
+
+// NewConfigMapSink creates a new ConfigMap sink
+func NewConfigMapSink(client client.Client, name, encoding string, labels map[string]string) *ConfigMapSink {
+	return &ConfigMapSink{
+		client:   client,
+		name:     name,
+		encoding: encoding,
+		labels:   labels,
+	}
+}
+
+// String returns a description of the sink
+func (s *ConfigMapSink) String() string {
+	return "ConfigMap/" + s.name
+}
+
+// Write creates or updates the ConfigMap with the given files
+func (s *ConfigMapSink) Write(ctx context.Context, namespace string, files map[string][]byte) error {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: namespace, Name: s.name}
+
+	err := s.client.Get(ctx, key, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: namespace,
+				Labels:    s.labels,
+			},
+		}
+		s.apply(configMap, files)
+		return s.client.Create(ctx, configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ConfigMap: %w", err)
+	}
+	if !isManaged(configMap, s.labels) {
+		return fmt.Errorf("ConfigMap %s exists and is not managed by the operator", s.name)
+	}
+
+	if !s.apply(configMap, files) {
+		return nil // Content is up to date
+	}
+
+	return s.client.Update(ctx, configMap)
+}
+
+// apply writes files into the ConfigMap and reports whether anything changed
+// A key is removed from the opposite map so that it is never present in both Data and BinaryData
+// Keys written before and no longer rendered are removed
+func (s *ConfigMapSink) apply(configMap *corev1.ConfigMap, files map[string][]byte) bool {
+	changed := false
+
+	for _, key := range staleOutputKeys(configMap, files) {
+		if _, ok := configMap.Data[key]; ok {
+			delete(configMap.Data, key)
+			changed = true
+		}
+		if _, ok := configMap.BinaryData[key]; ok {
+			delete(configMap.BinaryData, key)
+			changed = true
+		}
+	}
+	if setOutputKeys(configMap, files) {
+		changed = true
+	}
+
+	for key, content := range files {
+		if s.encoding == config.OutputEncodingString {
+			if configMap.Data == nil {
+				configMap.Data = make(map[string]string)
+			}
+			if current, ok := configMap.Data[key]; !ok || current != string(content) {
+				configMap.Data[key] = string(content)
+				changed = true
+			}
+			if _, ok := configMap.BinaryData[key]; ok {
+				delete(configMap.BinaryData, key)
+				changed = true
+			}
+			continue
+		}
+
+		if configMap.BinaryData == nil {
+			configMap.BinaryData = make(map[string][]byte)
+		}
+		if current, ok := configMap.BinaryData[key]; !ok || !bytes.Equal(current, content) {
+			configMap.BinaryData[key] = content
+			changed = true
+		}
+		if _, ok := configMap.Data[key]; ok {
+			delete(configMap.Data, key)
+			changed = true
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,187 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestConfigMapSinkApply(t *testing.T) {
+	tests := []struct {
+		name           string
+		encoding       string
+		existing       *corev1.ConfigMap
+		files          map[string][]byte
+		wantChanged    bool
+		wantData       map[string]string
+		wantBinaryData map[string][]byte
+		wantKeys       string
+	}{
+		{
+			name:           "new binary keys",
+			encoding:       config.OutputEncodingBinary,
+			existing:       &corev1.ConfigMap{},
+			files:          map[string][]byte{"b.pem": []byte("b"), "a.pem": []byte("a")},
+			wantChanged:    true,
+			wantBinaryData: map[string][]byte{"a.pem": []byte("a"), "b.pem": []byte("b")},
+			wantKeys:       "a.pem,b.pem",
+		},
+		{
+			name:        "new string keys",
+			encoding:    config.OutputEncodingString,
+			existing:    &corev1.ConfigMap{},
+			files:       map[string][]byte{"jwks.json": []byte("{}")},
+			wantChanged: true,
+			wantData:    map[string]string{"jwks.json": "{}"},
+			wantKeys:    "jwks.json",
+		},
+		{
+			name:     "up to date",
+			encoding: config.OutputEncodingString,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "jwks.json"}},
+				Data:       map[string]string{"jwks.json": "{}"},
+			},
+			files:    map[string][]byte{"jwks.json": []byte("{}")},
+			wantData: map[string]string{"jwks.json": "{}"},
+			wantKeys: "jwks.json",
+		},
+		{
+			name:     "removed kid",
+			encoding: config.OutputEncodingBinary,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "a.pem,b.pem"}},
+				BinaryData: map[string][]byte{"a.pem": []byte("a"), "b.pem": []byte("b")},
+			},
+			files:          map[string][]byte{"a.pem": []byte("a")},
+			wantChanged:    true,
+			wantBinaryData: map[string][]byte{"a.pem": []byte("a")},
+			wantKeys:       "a.pem",
+		},
+		{
+			name:     "keys not written by outputs are kept",
+			encoding: config.OutputEncodingBinary,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "a.pem"}},
+				Data:       map[string]string{"user.txt": "kept"},
+				BinaryData: map[string][]byte{"a.pem": []byte("a"), "user.pem": []byte("kept")},
+			},
+			files:          map[string][]byte{"c.pem": []byte("c")},
+			wantChanged:    true,
+			wantData:       map[string]string{"user.txt": "kept"},
+			wantBinaryData: map[string][]byte{"c.pem": []byte("c"), "user.pem": []byte("kept")},
+			wantKeys:       "c.pem",
+		},
+		{
+			name:     "encoding change moves the key",
+			encoding: config.OutputEncodingString,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "jwks.json"}},
+				BinaryData: map[string][]byte{"jwks.json": []byte("{}")},
+			},
+			files:          map[string][]byte{"jwks.json": []byte("{}")},
+			wantChanged:    true,
+			wantData:       map[string]string{"jwks.json": "{}"},
+			wantBinaryData: map[string][]byte{},
+			wantKeys:       "jwks.json",
+		},
+		{
+			name:     "removed output",
+			encoding: config.OutputEncodingBinary,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "a.pem"}},
+				Data:       map[string]string{"user.txt": "kept"},
+				BinaryData: map[string][]byte{"a.pem": []byte("a")},
+			},
+			files:       nil,
+			wantChanged: true,
+			wantData:    map[string]string{"user.txt": "kept"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewConfigMapSink(nil, "out", tt.encoding, nil)
+			configMap := tt.existing.DeepCopy()
+
+			if changed := sink.apply(configMap, tt.files); changed != tt.wantChanged {
+				t.Errorf("apply() = %v, want %v", changed, tt.wantChanged)
+			}
+			if len(configMap.Data) != 0 || len(tt.wantData) != 0 {
+				if !reflect.DeepEqual(configMap.Data, tt.wantData) {
+					t.Errorf("Data = %v, want %v", configMap.Data, tt.wantData)
+				}
+			}
+			if len(configMap.BinaryData) != 0 || len(tt.wantBinaryData) != 0 {
+				if !reflect.DeepEqual(configMap.BinaryData, tt.wantBinaryData) {
+					t.Errorf("BinaryData = %v, want %v", configMap.BinaryData, tt.wantBinaryData)
+				}
+			}
+			if got := configMap.Annotations[config.AnnotationOutputKeys]; got != tt.wantKeys {
+				t.Errorf("%s = %q, want %q", config.AnnotationOutputKeys, got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestSecretSinkApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    *corev1.Secret
+		files       map[string][]byte
+		wantChanged bool
+		wantData    map[string][]byte
+		wantKeys    string
+	}{
+		{
+			name:        "new Secret",
+			existing:    &corev1.Secret{},
+			files:       map[string][]byte{"a.pem": []byte("a")},
+			wantChanged: true,
+			wantData:    map[string][]byte{"a.pem": []byte("a")},
+			wantKeys:    "a.pem",
+		},
+		{
+			name: "removed kid",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "a.pem,b.pem"}},
+				Data:       map[string][]byte{"a.pem": []byte("a"), "b.pem": []byte("b"), "user": []byte("kept")},
+			},
+			files:       map[string][]byte{"b.pem": []byte("b2")},
+			wantChanged: true,
+			wantData:    map[string][]byte{"b.pem": []byte("b2"), "user": []byte("kept")},
+			wantKeys:    "b.pem",
+		},
+		{
+			name: "up to date",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{config.AnnotationOutputKeys: "a.pem"}},
+				Data:       map[string][]byte{"a.pem": []byte("a")},
+			},
+			files:    map[string][]byte{"a.pem": []byte("a")},
+			wantData: map[string][]byte{"a.pem": []byte("a")},
+			wantKeys: "a.pem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewSecretSink(nil, "out", nil)
+			secret := tt.existing.DeepCopy()
+
+			if changed := sink.apply(secret, tt.files); changed != tt.wantChanged {
+				t.Errorf("apply() = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(secret.Data, tt.wantData) {
+				t.Errorf("Data = %v, want %v", secret.Data, tt.wantData)
+			}
+			if got := secret.Annotations[config.AnnotationOutputKeys]; got != tt.wantKeys {
+				t.Errorf("%s = %q, want %q", config.AnnotationOutputKeys, got, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package output
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// Publisher writes rendered JWKS into all outputs configured for a JWKS resource
+type Publisher struct {
+	client client.Client
+}
+
+// NewPublisher creates a new output publisher
+func NewPublisher(client client.Client) *Publisher {
+	return &Publisher{
+		client: client,
+	}
+}
+
+// target is a resource written by one or more outputs with the files rendered for it
+type target struct {
+	output v1alpha1.OutputSpec
+	files  map[string][]byte
+}
+
+// Publish renders the key set in every requested format and writes it into configured outputs
+// All outputs are validated and rendered before anything is written, so an invalid entry
+// never leaves the outputs partially updated
+// Outputs into the same resource are written together, so keys no longer rendered can be removed
+// Keys of outputs removed from the spec are removed as well, see cleanup
+func (p *Publisher) Publish(ctx context.Context, jwksObj *v1alpha1.JWKS, jwksData *jwks.JWKS) error {
+	if len(jwksObj.Spec.Outputs) == 0 {
+		return p.cleanup(ctx, jwksObj, nil)
+	}
+	if jwksData == nil {
+		return fmt.Errorf("JWKS data is nil")
+	}
+
+	if err := ValidateOutputs(jwksObj); err != nil {
+		return fmt.Errorf("invalid outputs: %w", err)
+	}
+
+	// Render all outputs up front from the same key set
+	var targets []*target
+	byID := make(map[string]*target)
+	rendered := make(map[string]map[string][]byte)
+	for _, raw := range jwksObj.Spec.Outputs {
+		output := NormalizeOutput(raw)
+
+		renderKey := output.Format + "/" + output.Key
+		files, ok := rendered[renderKey]
+		if !ok {
+			var err error
+			files, err = jwks.Render(output.Format, jwksData, output.Key)
+			if err != nil {
+				return fmt.Errorf("failed to render %s output: %w", output.Format, err)
+			}
+			rendered[renderKey] = files
+		}
+
+		id := targetID(output)
+		t, ok := byID[id]
+		if !ok {
+			t = &target{output: output, files: make(map[string][]byte, len(files))}
+			byID[id] = t
+			targets = append(targets, t)
+		}
+		for key, content := range files {
+			// per-kid-pem keys are only known after rendering
+			if _, exists := t.files[key]; exists {
+				return fmt.Errorf("invalid outputs: key %s of %s is written by more than one output", key, id)
+			}
+			t.files[key] = content
+		}
+	}
+
+	for _, t := range targets {
+		sink, err := NewSink(p.client, t.output, targetLabels(jwksObj, t.output))
+		if err != nil {
+			return err
+		}
+
+		if err := sink.Write(ctx, jwksObj.Namespace, t.files); err != nil {
+			return fmt.Errorf("failed to write output %s: %w", sink, err)
+		}
+	}
+
+	return p.cleanup(ctx, jwksObj, byID)
+}
+
+// outputLabels returns labels of resources created for outputs
+func outputLabels(jwksObj *v1alpha1.JWKS) map[string]string {
+	return map[string]string{
+		config.LabelJWKSConfig: jwksObj.Name,
+		config.LabelManagedBy:  config.LabelManagedByValue,
+	}
+}
+
+// targetLabels returns labels the sink of an output sets and requires on an existing resource
+// The primary JWKS ConfigMap is created without them by the operator itself
+func targetLabels(jwksObj *v1alpha1.JWKS, output v1alpha1.OutputSpec) map[string]string {
+	if output.Kind == config.OutputKindConfigMap && output.Name == jwksObj.Spec.ConfigMapName {
+		return nil
+	}
+	return outputLabels(jwksObj)
+}
+
+// cleanup removes keys written by outputs that are no longer configured
+// Resources created for such outputs are deleted once no other data remains in them
+func (p *Publisher) cleanup(ctx context.Context, jwksObj *v1alpha1.JWKS, current map[string]*target) error {
+	labels := outputLabels(jwksObj)
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := p.client.List(ctx, configMaps, client.InNamespace(jwksObj.Namespace), client.MatchingLabels(labels)); err != nil {
+		return fmt.Errorf("failed to list output ConfigMaps: %w", err)
+	}
+
+	primary := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: jwksObj.Namespace, Name: jwksObj.Spec.ConfigMapName}
+	if err := p.client.Get(ctx, key, primary); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get ConfigMap %s: %w", key.Name, err)
+		}
+	} else if !isManaged(primary, labels) {
+		configMaps.Items = append(configMaps.Items, *primary)
+	}
+
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if _, ok := current[config.OutputKindConfigMap+"/"+configMap.Name]; ok {
+			continue
+		}
+		if _, ok := configMap.Annotations[config.AnnotationOutputKeys]; !ok {
+			continue // Not written by outputs
+		}
+
+		sink := NewConfigMapSink(p.client, configMap.Name, "", labels)
+		sink.apply(configMap, nil)
+		if isManaged(configMap, labels) && len(configMap.Data) == 0 && len(configMap.BinaryData) == 0 {
+			if err := p.client.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete output %s: %w", sink, err)
+			}
+			continue
+		}
+		if err := p.client.Update(ctx, configMap); err != nil {
+			return fmt.Errorf("failed to clean up output %s: %w", sink, err)
+		}
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := p.client.List(ctx, secrets, client.InNamespace(jwksObj.Namespace), client.MatchingLabels(labels)); err != nil {
+		return fmt.Errorf("failed to list output Secrets: %w", err)
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if _, ok := current[config.OutputKindSecret+"/"+secret.Name]; ok {
+			continue
+		}
+		if _, ok := secret.Annotations[config.AnnotationOutputKeys]; !ok {
+			continue // Not written by outputs
+		}
+
+		sink := NewSecretSink(p.client, secret.Name, labels)
+		sink.apply(secret, nil)
+		if len(secret.Data) == 0 {
+			if err := p.client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete output %s: %w", sink, err)
+			}
+			continue
+		}
+		if err := p.client.Update(ctx, secret); err != nil {
+			return fmt.Errorf("failed to clean up output %s: %w", sink, err)
+		}
+	}
+
+	return nil
+}
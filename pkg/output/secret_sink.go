@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecretSink writes JWKS files into an Opaque Secret
+type SecretSink struct {
+	client client.Client
+	name   string
+	labels map[string]string
+}
+
+// NewSecretSink creates a new Secret sink
+func NewSecretSink(client client.Client, name string, labels map[string]string) *SecretSink {
+	return &SecretSink{
+		client: client,
+		name:   name,
+		labels: labels,
+	}
+}
+
+// String returns a description of the sink
+func (s *SecretSink) String() string {
+	return "Secret/" + s.name
+}
+
+// Write creates or updates the Secret with the given files
+func (s *SecretSink) Write(ctx context.Context, namespace string, files map[string][]byte) error {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: s.name}
+
+	err := s.client.Get(ctx, key, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: namespace,
+				Labels:    s.labels,
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+		s.apply(secret, files)
+		return s.client.Create(ctx, secret)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Secret: %w", err)
+	}
+	if !isManaged(secret, s.labels) {
+		return fmt.Errorf("Secret %s exists and is not managed by the operator", s.name)
+	}
+
+	if !s.apply(secret, files) {
+		return nil // Content is up to date
+	}
+
+	return s.client.Update(ctx, secret)
+}
+
+// apply writes files into the Secret and reports whether anything changed
+// Keys written before and no longer rendered are removed
+func (s *SecretSink) apply(secret *corev1.Secret, files map[string][]byte) bool {
+	changed := false
+
+	for _, dataKey := range staleOutputKeys(secret, files) {
+		if _, ok := secret.Data[dataKey]; ok {
+			delete(secret.Data, dataKey)
+			changed = true
+		}
+	}
+	if setOutputKeys(secret, files) {
+		changed = true
+	}
+
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte, len(files))
+	}
+	for dataKey, content := range files {
+		if current, ok := secret.Data[dataKey]; !ok || !bytes.Equal(current, content) {
+			secret.Data[dataKey] = content
+			changed = true
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,187 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// Sink writes rendered JWKS files into a Kubernetes resource
+type Sink interface {
+	// Write creates or updates the target resource with the given files (data key -> content)
+	// Keys written by a previous Write and missing from files are removed
+	Write(ctx context.Context, namespace string, files map[string][]byte) error
+	// String returns a human-readable description of the sink for logs and errors
+	String() string
+}
+
+// NewSink creates a sink for the given output spec
+// The spec is expected to be normalized with NormalizeOutput
+func NewSink(client client.Client, output v1alpha1.OutputSpec, labels map[string]string) (Sink, error) {
+	switch output.Kind {
+	case config.OutputKindConfigMap:
+		return NewConfigMapSink(client, output.Name, output.Encoding, labels), nil
+	case config.OutputKindSecret:
+		return NewSecretSink(client, output.Name, labels), nil
+	default:
+		return nil, fmt.Errorf("unsupported output kind: %s", output.Kind)
+	}
+}
+
+// NormalizeOutput returns a copy of the output spec with defaults applied
+func NormalizeOutput(output v1alpha1.OutputSpec) v1alpha1.OutputSpec {
+	if output.Kind == "" {
+		output.Kind = config.OutputKindConfigMap
+	}
+	if output.Format == "" {
+		output.Format = jwks.FormatJWKS
+	}
+	if format, ok := jwks.LookupFormat(output.Format); ok {
+		if format.DefaultKey == "" {
+			output.Key = "" // Format names files itself
+		} else if output.Key == "" {
+			output.Key = format.DefaultKey
+		}
+	}
+	if output.Encoding == "" {
+		output.Encoding = config.OutputEncodingBinary
+	}
+	return output
+}
+
+// targetID identifies the resource an output writes into
+func targetID(output v1alpha1.OutputSpec) string {
+	return output.Kind + "/" + output.Name
+}
+
+// ValidateOutputs validates output specs of a JWKS resource
+// Outputs must not overwrite the primary JWKS data, the nginx configuration or the certificate Secret
+// Outputs into the same ConfigMap must use the same encoding, their files are written together
+func ValidateOutputs(jwksObj *v1alpha1.JWKS) error {
+	seen := make(map[string]bool)
+	encodings := make(map[string]string)
+	for i, raw := range jwksObj.Spec.Outputs {
+		output := NormalizeOutput(raw)
+
+		if output.Name == "" {
+			return fmt.Errorf("outputs[%d]: name cannot be empty", i)
+		}
+
+		switch output.Kind {
+		case config.OutputKindConfigMap, config.OutputKindSecret:
+		default:
+			return fmt.Errorf("outputs[%d]: unsupported kind %q", i, output.Kind)
+		}
+
+		if _, ok := jwks.LookupFormat(output.Format); !ok {
+			return fmt.Errorf("outputs[%d]: unsupported format %q, supported: %v", i, output.Format, jwks.SupportedFormats())
+		}
+
+		switch output.Encoding {
+		case config.OutputEncodingBinary, config.OutputEncodingString:
+		default:
+			return fmt.Errorf("outputs[%d]: unsupported encoding %q", i, output.Encoding)
+		}
+
+		if output.Kind == config.OutputKindSecret && output.Name == jwksObj.Spec.CertificateSecret {
+			return fmt.Errorf("outputs[%d]: Secret %s is the certificate Secret of the JWKS resource", i, output.Name)
+		}
+
+		if output.Kind == config.OutputKindConfigMap {
+			if output.Name == jwksObj.Spec.ConfigMapName &&
+				(output.Key == config.ConfigMapKeyJWKS || output.Key == config.ConfigMapKeyDiscovery) {
+				return fmt.Errorf("outputs[%d]: %s/%s is already written as primary JWKS data", i, output.Name, output.Key)
+			}
+			if output.Name == jwksObj.Spec.ConfigMapName && output.Format == jwks.FormatPerKidPEM {
+				return fmt.Errorf("outputs[%d]: %s writes one key per kid and cannot target the primary JWKS ConfigMap %s",
+					i, output.Format, output.Name)
+			}
+			if output.Name == jwksObj.Spec.NginxConfigMapName {
+				return fmt.Errorf("outputs[%d]: ConfigMap %s is reserved for nginx configuration", i, output.Name)
+			}
+
+			target := targetID(output)
+			if encoding, ok := encodings[target]; ok && encoding != output.Encoding {
+				return fmt.Errorf("outputs[%d]: %s is written with encoding %q and %q", i, target, encoding, output.Encoding)
+			}
+			encodings[target] = output.Encoding
+		}
+
+		// Outputs writing the same data key would overwrite each other, whatever their format
+		id := targetID(output) + "/" + output.Key
+		if seen[id] {
+			return fmt.Errorf("outputs[%d]: duplicate output %s", i, id)
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// staleOutputKeys returns the keys listed in AnnotationOutputKeys of obj that are missing from files
+func staleOutputKeys(obj metav1.Object, files map[string][]byte) []string {
+	previous := obj.GetAnnotations()[config.AnnotationOutputKeys]
+	if previous == "" {
+		return nil
+	}
+
+	var stale []string
+	for _, key := range strings.Split(previous, ",") {
+		if _, ok := files[key]; !ok {
+			stale = append(stale, key)
+		}
+	}
+	return stale
+}
+
+// setOutputKeys stores the keys of files in AnnotationOutputKeys of obj
+// The annotation is removed when files is empty
+// Returns true if obj was changed
+func setOutputKeys(obj metav1.Object, files map[string][]byte) bool {
+	annotations := obj.GetAnnotations()
+	if len(files) == 0 {
+		if _, ok := annotations[config.AnnotationOutputKeys]; !ok {
+			return false
+		}
+		delete(annotations, config.AnnotationOutputKeys)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	written := strings.Join(keys, ",")
+
+	if annotations[config.AnnotationOutputKeys] == written {
+		return false
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[config.AnnotationOutputKeys] = written
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// isManaged reports whether obj carries all labels set by the sink
+// Existing resources without them were not created by the operator and are never adopted or overwritten
+func isManaged(obj metav1.Object, labels map[string]string) bool {
+	current := obj.GetLabels()
+	for key, value := range labels {
+		if current[key] != value {
+			return false
+		}
+	}
+	return true
+}
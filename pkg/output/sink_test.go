@@ -0,0 +1,234 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// newTestJWKS returns a JWKS resource with the given outputs
+func newTestJWKS(outputs ...v1alpha1.OutputSpec) *v1alpha1.JWKS {
+	return &v1alpha1.JWKS{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+		Spec: v1alpha1.JWKSSpec{
+			CertificateSecret:  "auth-cert",
+			ConfigMapName:      "auth-jwks",
+			NginxConfigMapName: "auth-nginx",
+			Outputs:            outputs,
+		},
+	}
+}
+
+func TestNormalizeOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output v1alpha1.OutputSpec
+		want   v1alpha1.OutputSpec
+	}{
+		{
+			name:   "defaults",
+			output: v1alpha1.OutputSpec{Name: "out"},
+			want: v1alpha1.OutputSpec{Kind: config.OutputKindConfigMap, Name: "out", Format: jwks.FormatJWKS,
+				Key: "jwks.json", Encoding: config.OutputEncodingBinary},
+		},
+		{
+			name:   "format default key",
+			output: v1alpha1.OutputSpec{Kind: config.OutputKindSecret, Name: "out", Format: jwks.FormatPEMBundle},
+			want: v1alpha1.OutputSpec{Kind: config.OutputKindSecret, Name: "out", Format: jwks.FormatPEMBundle,
+				Key: "keys.pem", Encoding: config.OutputEncodingBinary},
+		},
+		{
+			name:   "per-kid-pem ignores key",
+			output: v1alpha1.OutputSpec{Name: "out", Format: jwks.FormatPerKidPEM, Key: "keys.pem"},
+			want: v1alpha1.OutputSpec{Kind: config.OutputKindConfigMap, Name: "out", Format: jwks.FormatPerKidPEM,
+				Encoding: config.OutputEncodingBinary},
+		},
+		{
+			name:   "explicit key and encoding",
+			output: v1alpha1.OutputSpec{Name: "out", Key: "keys.json", Encoding: config.OutputEncodingString},
+			want: v1alpha1.OutputSpec{Kind: config.OutputKindConfigMap, Name: "out", Format: jwks.FormatJWKS,
+				Key: "keys.json", Encoding: config.OutputEncodingString},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeOutput(tt.output); got != tt.want {
+				t.Errorf("NormalizeOutput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []v1alpha1.OutputSpec
+		wantErr string
+	}{
+		{
+			name: "no outputs",
+		},
+		{
+			name: "outputs into separate resources",
+			outputs: []v1alpha1.OutputSpec{
+				{Name: "gateway-jwks"},
+				{Kind: config.OutputKindSecret, Name: "policy-jwks"},
+				{Name: "pins", Format: jwks.FormatSPKIPins},
+			},
+		},
+		{
+			name: "formats into one resource under different keys",
+			outputs: []v1alpha1.OutputSpec{
+				{Name: "gateway"},
+				{Name: "gateway", Format: jwks.FormatPEMBundle},
+				{Name: "gateway", Format: jwks.FormatPerKidPEM},
+			},
+		},
+		{
+			name: "primary ConfigMap under another key",
+			outputs: []v1alpha1.OutputSpec{
+				{Name: "auth-jwks", Format: jwks.FormatPEMBundle},
+			},
+		},
+		{
+			name:    "empty name",
+			outputs: []v1alpha1.OutputSpec{{Name: ""}},
+			wantErr: "name cannot be empty",
+		},
+		{
+			name:    "unsupported kind",
+			outputs: []v1alpha1.OutputSpec{{Kind: "Pod", Name: "out"}},
+			wantErr: "unsupported kind",
+		},
+		{
+			name:    "unsupported format",
+			outputs: []v1alpha1.OutputSpec{{Name: "out", Format: "der"}},
+			wantErr: "unsupported format",
+		},
+		{
+			name:    "unsupported encoding",
+			outputs: []v1alpha1.OutputSpec{{Name: "out", Encoding: "base32"}},
+			wantErr: "unsupported encoding",
+		},
+		{
+			name:    "primary JWKS key",
+			outputs: []v1alpha1.OutputSpec{{Name: "auth-jwks"}},
+			wantErr: "already written as primary JWKS data",
+		},
+		{
+			name:    "primary discovery key",
+			outputs: []v1alpha1.OutputSpec{{Name: "auth-jwks", Key: config.ConfigMapKeyDiscovery}},
+			wantErr: "already written as primary JWKS data",
+		},
+		{
+			name:    "per-kid-pem into the primary ConfigMap",
+			outputs: []v1alpha1.OutputSpec{{Name: "auth-jwks", Format: jwks.FormatPerKidPEM}},
+			wantErr: "cannot target the primary JWKS ConfigMap",
+		},
+		{
+			name:    "certificate Secret",
+			outputs: []v1alpha1.OutputSpec{{Kind: config.OutputKindSecret, Name: "auth-cert", Key: "keys.json"}},
+			wantErr: "is the certificate Secret",
+		},
+		{
+			name:    "ConfigMap named as the certificate Secret",
+			outputs: []v1alpha1.OutputSpec{{Name: "auth-cert"}},
+		},
+		{
+			name:    "nginx ConfigMap",
+			outputs: []v1alpha1.OutputSpec{{Name: "auth-nginx", Key: "keys.json"}},
+			wantErr: "reserved for nginx configuration",
+		},
+		{
+			name: "same key in different formats",
+			outputs: []v1alpha1.OutputSpec{
+				{Name: "gateway", Key: "keys"},
+				{Name: "gateway", Format: jwks.FormatSPKIPins, Key: "keys"},
+			},
+			wantErr: "duplicate output ConfigMap/gateway/keys",
+		},
+		{
+			name: "two per-kid-pem outputs",
+			outputs: []v1alpha1.OutputSpec{
+				{Kind: config.OutputKindSecret, Name: "pems", Format: jwks.FormatPerKidPEM},
+				{Kind: config.OutputKindSecret, Name: "pems", Format: jwks.FormatPerKidPEM},
+			},
+			wantErr: "duplicate output",
+		},
+		{
+			name: "different encodings in one ConfigMap",
+			outputs: []v1alpha1.OutputSpec{
+				{Name: "gateway"},
+				{Name: "gateway", Format: jwks.FormatPEMBundle, Encoding: config.OutputEncodingString},
+			},
+			wantErr: "is written with encoding",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutputs(newTestJWKS(tt.outputs...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateOutputs() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateOutputs() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsManaged(t *testing.T) {
+	labels := map[string]string{
+		config.LabelJWKSConfig: "auth",
+		config.LabelManagedBy:  config.LabelManagedByValue,
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		object map[string]string
+		want   bool
+	}{
+		{
+			name:   "created by the operator",
+			labels: labels,
+			object: map[string]string{config.LabelJWKSConfig: "auth", config.LabelManagedBy: config.LabelManagedByValue, "team": "a"},
+			want:   true,
+		},
+		{
+			name:   "without labels",
+			labels: labels,
+			want:   false,
+		},
+		{
+			name:   "other JWKS resource",
+			labels: labels,
+			object: map[string]string{config.LabelJWKSConfig: "other", config.LabelManagedBy: config.LabelManagedByValue},
+			want:   false,
+		},
+		{
+			name:   "primary ConfigMap",
+			labels: nil,
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Labels: tt.object}
+			if got := isManaged(obj, tt.labels); got != tt.want {
+				t.Errorf("isManaged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
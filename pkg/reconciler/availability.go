@@ -0,0 +1,126 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// conditionMinimumAvailability is the condition type reporting whether the PodDisruptionBudget minimum is met
+const conditionMinimumAvailability = "MinimumAvailability"
+
+// phase5EnsureAvailability ensures PodDisruptionBudget and HorizontalPodAutoscaler from spec.server
+// and reports the minimum availability of the server pods
+func (l *ReconciliationLoop) phase5EnsureAvailability(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	if !l.serverDeployed(jwks) {
+		l.statusUpdater.RemoveCondition(jwks, conditionMinimumAvailability)
+		return nil // Server not deployed by the operator
+	}
+
+	server := jwks.Spec.Server
+	if server == nil {
+		server = &v1alpha1.ServerSpec{}
+	}
+
+	if err := nginx.ValidateAvailability(server); err != nil {
+		return fmt.Errorf("invalid spec.server: %w", err)
+	}
+
+	if err := l.nginxManager.EnsurePodDisruptionBudget(ctx, jwks.Namespace, jwks.Name, server.PodDisruptionBudget); err != nil {
+		metrics.RecordNginxOperation("pdb", metrics.ResultError)
+		return fmt.Errorf("failed to ensure PodDisruptionBudget: %w", err)
+	}
+	metrics.RecordNginxOperation("pdb", metrics.ResultSuccess)
+
+	if err := l.nginxManager.EnsureHorizontalPodAutoscaler(ctx, jwks.Namespace, jwks.Name, server.Autoscaling); err != nil {
+		metrics.RecordNginxOperation("hpa", metrics.ResultError)
+		return fmt.Errorf("failed to ensure HorizontalPodAutoscaler: %w", err)
+	}
+	metrics.RecordNginxOperation("hpa", metrics.ResultSuccess)
+
+	l.updateAvailabilityCondition(ctx, jwks)
+	return nil
+}
+
+// updateAvailabilityCondition sets the MinimumAvailability condition from the PodDisruptionBudget status
+// The condition is removed if no PodDisruptionBudget is configured
+func (l *ReconciliationLoop) updateAvailabilityCondition(ctx context.Context, jwks *v1alpha1.JWKS) {
+	status, reason, message, ok := l.checkAvailability(ctx, jwks)
+	if !ok {
+		return
+	}
+
+	if status == "" {
+		l.statusUpdater.RemoveCondition(jwks, conditionMinimumAvailability)
+		return
+	}
+
+	if status == metav1.ConditionFalse {
+		l.logger.Warn("JWKS server minimum availability not met",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("message", message),
+		)
+	}
+	l.statusUpdater.SetCondition(jwks, conditionMinimumAvailability, status, reason, message)
+}
+
+// availabilityChanged checks if the MinimumAvailability condition no longer matches the PodDisruptionBudget
+// so that status is refreshed without waiting for the update interval
+func (l *ReconciliationLoop) availabilityChanged(ctx context.Context, jwks *v1alpha1.JWKS) bool {
+	if !l.serverDeployed(jwks) {
+		return false
+	}
+
+	status, reason, _, ok := l.checkAvailability(ctx, jwks)
+	if !ok {
+		return false
+	}
+
+	condition := apimeta.FindStatusCondition(jwks.Status.Conditions, conditionMinimumAvailability)
+	if condition == nil {
+		return status != ""
+	}
+	return condition.Status != status || condition.Reason != reason
+}
+
+// checkAvailability returns the MinimumAvailability condition for the current PodDisruptionBudget status
+// An empty status means the condition must be removed, ok is false if the status cannot be read
+func (l *ReconciliationLoop) checkAvailability(
+	ctx context.Context,
+	jwks *v1alpha1.JWKS,
+) (status metav1.ConditionStatus, reason, message string, ok bool) {
+	if jwks.Spec.Server == nil || jwks.Spec.Server.PodDisruptionBudget == nil {
+		return "", "", "", true
+	}
+
+	availability, err := l.nginxManager.GetAvailability(ctx, jwks.Namespace, jwks.Name)
+	if err != nil {
+		l.logger.Debug("failed to check JWKS server availability, will retry",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+		return "", "", "", false
+	}
+
+	switch {
+	case !availability.Configured || !availability.Observed:
+		return metav1.ConditionUnknown, "Pending", "PodDisruptionBudget status is not yet reported", true
+	case !availability.Met():
+		return metav1.ConditionFalse, "InsufficientReplicas", fmt.Sprintf(
+			"%d of %d expected pods are healthy, %d required",
+			availability.CurrentHealthy, availability.ExpectedPods, availability.DesiredHealthy), true
+	default:
+		return metav1.ConditionTrue, "MinimumAvailable", fmt.Sprintf(
+			"%d of %d expected pods are healthy, %d required",
+			availability.CurrentHealthy, availability.ExpectedPods, availability.DesiredHealthy), true
+	}
+}
@@ -0,0 +1,31 @@
+package reconciler
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+// serverDeployed checks if the backend serving the JWKS runs a server in the cluster
+// Availability, network policy, exposure, propagation and verification only apply to deployed servers
+func (l *ReconciliationLoop) serverDeployed(jwks *v1alpha1.JWKS) bool {
+	return l.backends.For(jwks).Managed()
+}
+
+// phase6RemoveInactiveBackends deletes server objects of backends that no longer serve the JWKS
+// e.g. the nginx Deployment and Service after switching spec.server.type to external
+// Deletion errors are non-critical, deletion is retried on the next reconciliation
+func (l *ReconciliationLoop) phase6RemoveInactiveBackends(ctx context.Context, jwks *v1alpha1.JWKS) {
+	for _, backend := range l.backends.Inactive(jwks) {
+		if err := backend.Delete(ctx, jwks.Namespace, jwks.Name); err != nil {
+			l.logger.Warn("failed to delete server resources of inactive backend",
+				zap.String("namespace", jwks.Namespace),
+				zap.String("name", jwks.Name),
+				zap.String("backend", backend.Name()),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/server"
+)
+
+// newBackendTestLoop returns a reconciliation loop with the nginx and external backends only
+func newBackendTestLoop() *ReconciliationLoop {
+	return &ReconciliationLoop{
+		backends: server.NewBackends(server.NewNginxBackend(nil, nil), server.ExternalBackend{}),
+	}
+}
+
+func TestServerDeployed(t *testing.T) {
+	tests := []struct {
+		name           string
+		nginxConfigMap string
+		server         *v1alpha1.ServerSpec
+		want           bool
+	}{
+		{name: "nginx server", nginxConfigMap: "auth-nginx", want: true},
+		{name: "built-in server", nginxConfigMap: "auth-nginx", server: &v1alpha1.ServerSpec{Type: config.ServerTypeBuiltin}, want: true},
+		{name: "external server", nginxConfigMap: "auth-nginx", server: &v1alpha1.ServerSpec{Type: config.ServerTypeExternal}},
+		{name: "without server config"},
+	}
+
+	loop := newBackendTestLoop()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwks := newOwnershipJWKS(tt.nginxConfigMap)
+			jwks.Spec.Server = tt.server
+
+			if got := loop.serverDeployed(jwks); got != tt.want {
+				t.Errorf("serverDeployed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
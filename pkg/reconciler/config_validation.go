@@ -0,0 +1,69 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// conditionNginxConfigInvalid is the condition type reporting a server config rejected by validation
+const conditionNginxConfigInvalid = "NginxConfigInvalid"
+
+// reportConfigValidation reports the validation result of the server config in the NginxConfigInvalid condition
+// A running nginx -t Job is not an error: the served config is kept and the Job completion triggers reconciliation
+// Returns err unless the config test is still running
+func (l *ReconciliationLoop) reportConfigValidation(jwks *v1alpha1.JWKS, err error) error {
+	switch {
+	case err == nil:
+		l.statusUpdater.RemoveCondition(jwks, conditionNginxConfigInvalid)
+		return nil
+	case errors.Is(err, nginx.ErrConfigTestPending):
+		l.logger.Info("nginx config test is running, keeping the served config",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("job", nginx.ConfigTestJobName(jwks.Name)),
+		)
+		l.statusUpdater.SetCondition(jwks, conditionNginxConfigInvalid, metav1.ConditionUnknown, "Testing",
+			"Changed config is tested with nginx -t in Job "+nginx.ConfigTestJobName(jwks.Name))
+		return nil
+	case errors.Is(err, nginx.ErrConfigTestFailed):
+		l.statusUpdater.SetCondition(jwks, conditionNginxConfigInvalid, metav1.ConditionTrue, "TestFailed", err.Error())
+	case errors.Is(err, nginx.ErrInvalidConfig):
+		l.statusUpdater.SetCondition(jwks, conditionNginxConfigInvalid, metav1.ConditionTrue, "LintFailed", err.Error())
+	}
+	return err
+}
+
+// configTestRunning checks if a changed server config is waiting for the nginx -t Job
+func configTestRunning(jwks *v1alpha1.JWKS) bool {
+	condition := apimeta.FindStatusCondition(jwks.Status.Conditions, conditionNginxConfigInvalid)
+	return condition != nil && condition.Status == metav1.ConditionUnknown
+}
+
+// awaitingFirstConfig checks if the server ConfigMap is not created yet because its first config is tested
+// The workload is not created until then: its pods mount the ConfigMap and would not start without it
+func (l *ReconciliationLoop) awaitingFirstConfig(ctx context.Context, jwks *v1alpha1.JWKS) (bool, error) {
+	if !configTestRunning(jwks) {
+		return false, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: jwks.Namespace, Name: jwks.Spec.NginxConfigMapName}
+	if err := l.client.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get server ConfigMap %s: %w", key.Name, err)
+	}
+	return false, nil
+}
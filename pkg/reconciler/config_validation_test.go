@@ -0,0 +1,76 @@
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+func TestReportConfigValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantErr     bool
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantRunning bool
+	}{
+		{name: "valid config"},
+		{
+			name:        "config test running",
+			err:         fmt.Errorf("job auth-nginx-test: %w", nginx.ErrConfigTestPending),
+			wantStatus:  metav1.ConditionUnknown,
+			wantReason:  "Testing",
+			wantRunning: true,
+		},
+		{
+			name:       "config test failed",
+			err:        fmt.Errorf("job auth-nginx-test: %w", nginx.ErrConfigTestFailed),
+			wantErr:    true,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "TestFailed",
+		},
+		{
+			name:       "lint failed",
+			err:        fmt.Errorf("%w: line 3: unknown directive", nginx.ErrInvalidConfig),
+			wantErr:    true,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "LintFailed",
+		},
+		{
+			name:    "other error",
+			err:     errors.New("configmap update failed"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loop := &ReconciliationLoop{statusUpdater: NewStatusUpdater(nil), logger: zap.NewNop()}
+			jwks := newOwnershipJWKS("auth-nginx")
+			loop.statusUpdater.SetCondition(jwks, conditionNginxConfigInvalid, metav1.ConditionTrue, "LintFailed", "stale")
+
+			err := loop.reportConfigValidation(jwks, tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("reportConfigValidation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			condition := apimeta.FindStatusCondition(jwks.Status.Conditions, conditionNginxConfigInvalid)
+			switch {
+			case tt.err == nil && condition != nil:
+				t.Errorf("condition = %+v, want it removed", condition)
+			case tt.wantReason != "" && (condition == nil || condition.Status != tt.wantStatus || condition.Reason != tt.wantReason):
+				t.Errorf("condition = %+v, want %s/%s", condition, tt.wantStatus, tt.wantReason)
+			}
+			if got := configTestRunning(jwks); got != tt.wantRunning {
+				t.Errorf("configTestRunning() = %v, want %v", got, tt.wantRunning)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package reconciler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/verification"
+)
+
+// verificationOrigin is an origin that is never listed in spec.cors, used to check rejected origins
+const verificationOrigin = "https://jwks-operator-verification.invalid"
+
+// getCORSChecks returns CORS checks matching the headers generated for spec.cors
+func getCORSChecks(jwks *v1alpha1.JWKS) []verification.CORSCheck {
+	policy := nginx.ResolveCORS(jwks.Spec.CORS)
+
+	var checks []verification.CORSCheck
+
+	allowedOrigin := ""
+	switch {
+	case !policy.Enabled:
+	case len(policy.Origins) > 0:
+		allowedOrigin = policy.Origins[0]
+	case policy.AnyOrigin:
+		allowedOrigin = verificationOrigin
+	}
+
+	if allowedOrigin != "" {
+		maxAge := ""
+		if policy.MaxAge != nil {
+			maxAge = strconv.Itoa(int(*policy.MaxAge))
+		}
+
+		for _, preflight := range []bool{false, true} {
+			checks = append(checks, verification.CORSCheck{
+				Preflight:        preflight,
+				Origin:           allowedOrigin,
+				AllowOrigin:      policy.AllowOrigin(allowedOrigin),
+				AllowMethods:     strings.Join(policy.Methods, ", "),
+				MaxAge:           maxAge,
+				AllowCredentials: policy.Credentials,
+			})
+		}
+	}
+
+	// Origins outside of the allow-list must not get CORS headers
+	if policy.AllowOrigin(verificationOrigin) == "" {
+		checks = append(checks, verification.CORSCheck{Origin: verificationOrigin})
+	}
+
+	return checks
+}
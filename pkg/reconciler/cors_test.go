@@ -0,0 +1,70 @@
+package reconciler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/verification"
+)
+
+func TestGetCORSChecks(t *testing.T) {
+	disabled := false
+	maxAge := int32(600)
+
+	tests := []struct {
+		name string
+		cors *v1alpha1.CORSSpec
+		want []verification.CORSCheck
+	}{
+		{
+			name: "any origin",
+			want: []verification.CORSCheck{
+				{Origin: verificationOrigin, AllowOrigin: "*", AllowMethods: "GET, OPTIONS"},
+				{Preflight: true, Origin: verificationOrigin, AllowOrigin: "*", AllowMethods: "GET, OPTIONS"},
+			},
+		},
+		{
+			name: "disabled",
+			cors: &v1alpha1.CORSSpec{Enabled: &disabled},
+			want: []verification.CORSCheck{{Origin: verificationOrigin}},
+		},
+		{
+			name: "allow-list",
+			cors: &v1alpha1.CORSSpec{
+				AllowedOrigins:   []string{"https://app.example.com"},
+				MaxAge:           &maxAge,
+				AllowCredentials: true,
+			},
+			want: []verification.CORSCheck{
+				{
+					Origin:           "https://app.example.com",
+					AllowOrigin:      "https://app.example.com",
+					AllowMethods:     "GET, OPTIONS",
+					MaxAge:           "600",
+					AllowCredentials: true,
+				},
+				{
+					Preflight:        true,
+					Origin:           "https://app.example.com",
+					AllowOrigin:      "https://app.example.com",
+					AllowMethods:     "GET, OPTIONS",
+					MaxAge:           "600",
+					AllowCredentials: true,
+				},
+				{Origin: verificationOrigin},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwks := newOwnershipJWKS("auth-nginx")
+			jwks.Spec.CORS = tt.cors
+
+			if got := getCORSChecks(jwks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getCORSChecks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
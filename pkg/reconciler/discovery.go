@@ -0,0 +1,92 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// phase3PublishDiscovery stores the OpenID Connect discovery document in the JWKS ConfigMap
+// The document is removed if spec.issuer is not set
+// Nothing is written while drift of the ConfigMap is only reported (driftPolicy: report)
+func (l *ReconciliationLoop) phase3PublishDiscovery(ctx context.Context, jwksObj *v1alpha1.JWKS, mergedJWKS *jwks.JWKS) error {
+	if driftReported(jwksObj) {
+		l.logger.Debug("drift of JWKS ConfigMap is reported, discovery document is not updated",
+			zap.String("namespace", jwksObj.Namespace),
+			zap.String("name", jwksObj.Name),
+			zap.String("configMap", jwksObj.Spec.ConfigMapName),
+		)
+		return nil
+	}
+
+	var discoveryData []byte
+	if jwksObj.Spec.Issuer != "" {
+		doc, err := l.buildDiscovery(jwksObj, mergedJWKS)
+		if err != nil {
+			return err
+		}
+		if discoveryData, err = jwks.DiscoveryToJSON(doc); err != nil {
+			return err
+		}
+	}
+
+	if err := l.configMapManager.UpdateDiscovery(ctx, jwksObj.Namespace, jwksObj.Spec.ConfigMapName, discoveryData); err != nil {
+		l.logger.Error("failed to update discovery document",
+			zap.String("namespace", jwksObj.Namespace),
+			zap.String("name", jwksObj.Name),
+			zap.String("configMap", jwksObj.Spec.ConfigMapName),
+			zap.Error(err),
+		)
+		metrics.RecordConfigMapUpdate("discovery", metrics.ResultError)
+		return fmt.Errorf("failed to update discovery document: %w", err)
+	}
+
+	metrics.RecordConfigMapUpdate("discovery", metrics.ResultSuccess)
+	l.logger.Debug("discovery document updated",
+		zap.String("namespace", jwksObj.Namespace),
+		zap.String("name", jwksObj.Name),
+		zap.String("issuer", jwksObj.Spec.Issuer),
+	)
+
+	return nil
+}
+
+// buildDiscovery builds the discovery document from spec.issuer and spec.discovery
+func (l *ReconciliationLoop) buildDiscovery(jwksObj *v1alpha1.JWKS, keySet *jwks.JWKS) (*jwks.DiscoveryDocument, error) {
+	doc := jwks.DiscoveryDocument{
+		Issuer:  jwksObj.Spec.Issuer,
+		JWKSURI: l.getJWKSURI(jwksObj),
+	}
+
+	if d := jwksObj.Spec.Discovery; d != nil {
+		doc.AuthorizationEndpoint = d.AuthorizationEndpoint
+		doc.TokenEndpoint = d.TokenEndpoint
+		doc.UserinfoEndpoint = d.UserinfoEndpoint
+		doc.ResponseTypesSupported = d.ResponseTypesSupported
+		doc.SubjectTypesSupported = d.SubjectTypesSupported
+		doc.IDTokenSigningAlgValuesSupported = d.IDTokenSigningAlgValuesSupported
+		doc.ScopesSupported = d.ScopesSupported
+		doc.ClaimsSupported = d.ClaimsSupported
+	}
+
+	result, err := jwks.BuildDiscovery(doc, keySet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery configuration: %w", err)
+	}
+	return result, nil
+}
+
+// getJWKSURI returns jwks_uri of the discovery document from CRD or derived from the issuer
+// The issuer already carries the public path, so spec.pathPrefix is not added
+func (l *ReconciliationLoop) getJWKSURI(jwksObj *v1alpha1.JWKS) string {
+	if jwksObj.Spec.Discovery != nil && jwksObj.Spec.Discovery.JWKSURI != "" {
+		return jwksObj.Spec.Discovery.JWKSURI
+	}
+	return jwks.DefaultJWKSURI(jwksObj.Spec.Issuer, nginx.NormalizeEndpoint(jwksObj.Spec.Endpoint))
+}
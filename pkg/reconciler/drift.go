@@ -0,0 +1,117 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/configmap"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+)
+
+// conditionDriftDetected is the condition type reporting manual changes of the JWKS ConfigMap
+const conditionDriftDetected = "DriftDetected"
+
+// getDriftPolicy returns the drift policy from CRD or the default
+func (l *ReconciliationLoop) getDriftPolicy(jwks *v1alpha1.JWKS) string {
+	if jwks.Spec.DriftPolicy != "" {
+		return jwks.Spec.DriftPolicy
+	}
+	return config.DriftPolicyRestore
+}
+
+// checkDrift detects manual changes of the JWKS ConfigMap
+// Returns true if the ConfigMap must not be written because drift is only reported
+func (l *ReconciliationLoop) checkDrift(ctx context.Context, jwks *v1alpha1.JWKS) (bool, error) {
+	report, err := l.configMapManager.DetectDrift(ctx, jwks.Namespace, jwks.Spec.ConfigMapName)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect drift: %w", err)
+	}
+
+	if !report.Detected {
+		if apimeta.IsStatusConditionTrue(jwks.Status.Conditions, conditionDriftDetected) {
+			l.statusUpdater.SetCondition(jwks, conditionDriftDetected, metav1.ConditionFalse, "Resolved",
+				"JWKS ConfigMap matches the content written by the operator")
+		}
+		return false, nil
+	}
+
+	message := driftMessage(jwks, report)
+	policy := l.getDriftPolicy(jwks)
+
+	l.logger.Warn("manual change of JWKS ConfigMap detected",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("configMap", jwks.Spec.ConfigMapName),
+		zap.String("driftPolicy", policy),
+		zap.Strings("unknownKids", report.UnknownKids),
+		zap.Strings("modifiedKids", report.ModifiedKids),
+	)
+	metrics.RecordError("configmap_drift_detected")
+
+	if policy == config.DriftPolicyReport {
+		l.recordEvent(jwks, corev1.EventTypeWarning, "DriftDetected", message)
+		l.statusUpdater.SetCondition(jwks, conditionDriftDetected, metav1.ConditionTrue, "ManualChange", message)
+		return true, nil
+	}
+
+	l.recordEvent(jwks, corev1.EventTypeWarning, "DriftRestored", message+", restoring content written by the operator")
+	l.statusUpdater.SetCondition(jwks, conditionDriftDetected, metav1.ConditionFalse, "Restored",
+		message+", content was restored")
+	return false, nil
+}
+
+// driftReported reports whether checkDrift found drift that is only reported
+// The JWKS ConfigMap must then be kept untouched until the drift is resolved
+func driftReported(jwks *v1alpha1.JWKS) bool {
+	return apimeta.IsStatusConditionTrue(jwks.Status.Conditions, conditionDriftDetected)
+}
+
+// shouldRepairDrift reports whether a manual change needs a full reconciliation
+// In report mode drift triggers reconciliation only until it is reported in the condition
+func (l *ReconciliationLoop) shouldRepairDrift(ctx context.Context, jwks *v1alpha1.JWKS) bool {
+	report, err := l.configMapManager.DetectDrift(ctx, jwks.Namespace, jwks.Spec.ConfigMapName)
+	if err != nil {
+		l.logger.Debug("failed to check JWKS ConfigMap drift, will retry",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	if !report.Detected {
+		return apimeta.IsStatusConditionTrue(jwks.Status.Conditions, conditionDriftDetected)
+	}
+	if l.getDriftPolicy(jwks) == config.DriftPolicyReport {
+		return !apimeta.IsStatusConditionTrue(jwks.Status.Conditions, conditionDriftDetected)
+	}
+	return true
+}
+
+// recordEvent emits a Kubernetes event for the JWKS resource if a recorder is configured
+func (l *ReconciliationLoop) recordEvent(jwks *v1alpha1.JWKS, eventType, reason, message string) {
+	if l.recorder == nil {
+		return
+	}
+	l.recorder.Event(jwks, eventType, reason, message)
+}
+
+// driftMessage describes a drift report for conditions and events
+func driftMessage(jwks *v1alpha1.JWKS, report *configmap.DriftReport) string {
+	parts := []string{fmt.Sprintf("ConfigMap %s was changed outside of the operator", jwks.Spec.ConfigMapName)}
+	if len(report.UnknownKids) > 0 {
+		parts = append(parts, "unknown kids: "+strings.Join(report.UnknownKids, ", "))
+	}
+	if len(report.ModifiedKids) > 0 {
+		parts = append(parts, "modified kids: "+strings.Join(report.ModifiedKids, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
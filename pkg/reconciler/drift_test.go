@@ -0,0 +1,43 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPhase3PublishDiscoveryDriftReported(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       metav1.ConditionStatus
+		wantReported bool
+	}{
+		{name: "no drift"},
+		{name: "drift resolved", status: metav1.ConditionFalse},
+		{name: "drift reported", status: metav1.ConditionTrue, wantReported: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loop := &ReconciliationLoop{statusUpdater: NewStatusUpdater(nil), logger: zap.NewNop()}
+			jwks := newOwnershipJWKS("")
+			jwks.Spec.Issuer = "https://issuer.example.com"
+			if tt.status != "" {
+				loop.statusUpdater.SetCondition(jwks, conditionDriftDetected, tt.status, "Test", "test")
+			}
+
+			if got := driftReported(jwks); got != tt.wantReported {
+				t.Fatalf("driftReported() = %v, want %v", got, tt.wantReported)
+			}
+			if !tt.wantReported {
+				return
+			}
+			// The loop has no ConfigMap manager, so any write would panic
+			if err := loop.phase3PublishDiscovery(context.Background(), jwks, nil); err != nil {
+				t.Errorf("phase3PublishDiscovery() unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/expose"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+)
+
+// conditionExposed is the condition type reporting Ingress and HTTPRoute state
+const conditionExposed = "Exposed"
+
+// phase6ExposeServer creates Ingress and HTTPRoute from spec.expose and reports the public URL
+// Expose errors are reported in the Exposed condition and do not fail reconciliation
+func (l *ReconciliationLoop) phase6ExposeServer(ctx context.Context, jwks *v1alpha1.JWKS) {
+	if jwks.Spec.Expose == nil && !hasCondition(jwks, conditionExposed) {
+		return // Expose not configured and nothing to clean up
+	}
+
+	if jwks.Spec.Expose != nil && !l.serverDeployed(jwks) {
+		jwks.Status.URL = ""
+		l.statusUpdater.SetCondition(jwks, conditionExposed, metav1.ConditionFalse, "ServerNotConfigured",
+			"spec.expose requires a server deployed by the operator: spec.nginxConfigMapName and a spec.server.type other than external")
+		return
+	}
+
+	url, err := l.exposeManager.Sync(ctx, jwks, l.getNginxConfigOptions(jwks).JWKSPath())
+	if err != nil {
+		l.logger.Error("failed to expose JWKS server",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+		metrics.RecordNginxOperation("expose", metrics.ResultError)
+
+		reason := "ExposeFailed"
+		switch {
+		case expose.IsGatewayAPINotInstalled(err):
+			reason = "GatewayAPINotInstalled"
+		case errors.Is(err, expose.ErrNotManaged):
+			reason = "ExposeConflict"
+		}
+		l.statusUpdater.SetCondition(jwks, conditionExposed, metav1.ConditionFalse, reason, err.Error())
+		return
+	}
+
+	metrics.RecordNginxOperation("expose", metrics.ResultSuccess)
+	jwks.Status.URL = url
+
+	if jwks.Spec.Expose == nil {
+		l.statusUpdater.RemoveCondition(jwks, conditionExposed)
+		return
+	}
+
+	message := "JWKS server exposed"
+	if url != "" {
+		message = fmt.Sprintf("JWKS server exposed at %s", url)
+	}
+	l.logger.Debug("JWKS server exposed",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("url", url),
+	)
+	l.statusUpdater.SetCondition(jwks, conditionExposed, metav1.ConditionTrue, "Exposed", message)
+}
+
+// hasCondition checks if JWKS has a condition of the given type
+func hasCondition(jwks *v1alpha1.JWKS, conditionType string) bool {
+	for _, condition := range jwks.Status.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
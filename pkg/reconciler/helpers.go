@@ -24,6 +24,14 @@ func (l *ReconciliationLoop) shouldKeepOldKeys(jwks *v1alpha1.JWKS) bool {
 	return l.config.DefaultKeepOldKeys
 }
 
+// shouldCleanupOnDelete determines if data ConfigMaps and Secrets are deleted with JWKS
+func (l *ReconciliationLoop) shouldCleanupOnDelete(jwks *v1alpha1.JWKS) bool {
+	if jwks.Spec.CleanupOnDelete != nil {
+		return *jwks.Spec.CleanupOnDelete
+	}
+	return l.config.CleanupOnDelete
+}
+
 // getEndpoint returns the endpoint from CRD or default
 func (l *ReconciliationLoop) getEndpoint(jwks *v1alpha1.JWKS) string {
 	if jwks.Spec.Endpoint != "" {
@@ -32,6 +40,26 @@ func (l *ReconciliationLoop) getEndpoint(jwks *v1alpha1.JWKS) string {
 	return nginx.DefaultEndpoint
 }
 
+// getNginxConfigOptions returns nginx configuration settings for JWKS
+func (l *ReconciliationLoop) getNginxConfigOptions(jwks *v1alpha1.JWKS) nginx.ConfigOptions {
+	return nginx.ConfigOptions{
+		Endpoint:    l.getEndpoint(jwks),
+		Paths:       jwks.Spec.Paths,
+		PathPrefix:  jwks.Spec.PathPrefix,
+		Discovery:   jwks.Spec.Issuer != "",
+		CORS:        jwks.Spec.CORS,
+		Cache:       jwks.Spec.Cache,
+		Metrics:     nginx.MetricsEnabled(jwks.Spec.Server),
+		Builtin:     nginx.BuiltinServer(jwks.Spec.Server),
+		TLS:         nginx.ServerTLS(jwks.Spec.Server) != nil,
+		RateLimit:   nginx.RateLimit(jwks.Spec.Server),
+		MaxBodySize: nginx.MaxBodySize(jwks.Spec.Server),
+		AccessLog:   nginx.AccessLog(jwks.Spec.Server),
+		Name:        jwks.Name,
+		Namespace:   jwks.Namespace,
+	}
+}
+
 // getJWKSUpdateInterval returns JWKS update interval from CRD or config default
 func (l *ReconciliationLoop) getJWKSUpdateInterval(jwks *v1alpha1.JWKS) time.Duration {
 	if jwks.Spec.JWKSUpdateInterval != "" {
@@ -0,0 +1,90 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/monitoring"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// conditionMonitored is the condition type reporting ServiceMonitor and PodMonitor state
+const conditionMonitored = "Monitored"
+
+// phase6MonitorServer creates the ServiceMonitor or PodMonitor from spec.server.metrics.monitor
+// Monitors of servers without exported metrics are deleted
+// Missing Prometheus Operator CRDs and monitor errors are reported in the Monitored condition
+// and do not fail reconciliation
+func (l *ReconciliationLoop) phase6MonitorServer(ctx context.Context, jwks *v1alpha1.JWKS) {
+	spec := monitoring.Monitor(jwks.Spec.Server)
+	if spec == nil && !hasCondition(jwks, conditionMonitored) {
+		return // Monitor not configured and nothing to clean up
+	}
+
+	desired := spec
+	reason, message := "", ""
+	switch {
+	case spec == nil:
+	case !l.serverDeployed(jwks):
+		desired = nil
+		reason = "ServerNotConfigured"
+		message = "spec.server.metrics.monitor requires a server deployed by the operator: " +
+			"spec.nginxConfigMapName and a spec.server.type other than external"
+	case !nginx.MetricsExported(jwks.Spec.Server):
+		desired = nil
+		reason = "MetricsDisabled"
+		message = "spec.server.metrics.monitor requires spec.server.metrics.enabled or the built-in server"
+	}
+
+	if err := l.monitorManager.Sync(ctx, jwks, desired); err != nil {
+		if monitoring.IsPrometheusOperatorNotInstalled(err) {
+			l.logger.Debug("Prometheus Operator CRDs not installed, skipping monitor",
+				zap.String("namespace", jwks.Namespace),
+				zap.String("name", jwks.Name),
+			)
+			l.statusUpdater.SetCondition(jwks, conditionMonitored, metav1.ConditionFalse, "PrometheusOperatorNotInstalled",
+				fmt.Sprintf("%s CRD of the Prometheus Operator is not installed", monitoring.MonitorKind(spec)))
+			return
+		}
+
+		l.logger.Error("failed to monitor JWKS server",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+		metrics.RecordNginxOperation("monitor", metrics.ResultError)
+
+		failure := "MonitorFailed"
+		if errors.Is(err, monitoring.ErrNotManaged) {
+			failure = "MonitorConflict"
+		}
+		l.statusUpdater.SetCondition(jwks, conditionMonitored, metav1.ConditionFalse, failure, err.Error())
+		return
+	}
+
+	metrics.RecordNginxOperation("monitor", metrics.ResultSuccess)
+
+	if reason != "" {
+		l.statusUpdater.SetCondition(jwks, conditionMonitored, metav1.ConditionFalse, reason, message)
+		return
+	}
+	if spec == nil {
+		l.statusUpdater.RemoveCondition(jwks, conditionMonitored)
+		return
+	}
+
+	kind := monitoring.MonitorKind(spec)
+	l.logger.Debug("JWKS server monitored",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("kind", kind),
+	)
+	l.statusUpdater.SetCondition(jwks, conditionMonitored, metav1.ConditionTrue, "Monitored",
+		fmt.Sprintf("%s %s scrapes the server metrics", kind, jwks.Name))
+}
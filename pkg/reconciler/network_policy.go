@@ -0,0 +1,35 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// phase5EnsureNetworkPolicy ensures the NetworkPolicy of the server pods from spec.networkPolicy
+// The operator namespace is always allowed, otherwise the served JWKS could not be verified
+func (l *ReconciliationLoop) phase5EnsureNetworkPolicy(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	if !l.serverDeployed(jwks) {
+		return nil // Server not deployed by the operator
+	}
+
+	spec := jwks.Spec.NetworkPolicy
+	if err := nginx.ValidateNetworkPolicy(spec); err != nil {
+		return fmt.Errorf("invalid spec.networkPolicy: %w", err)
+	}
+	if spec != nil && l.config.Namespace == "" {
+		return fmt.Errorf("operator namespace is unknown, cannot allow verification traffic")
+	}
+
+	if err := l.nginxManager.EnsureNetworkPolicy(ctx, jwks.Namespace, jwks.Name, l.config.Namespace, spec,
+		jwks.Spec.Server); err != nil {
+		metrics.RecordNginxOperation("network_policy", metrics.ResultError)
+		return fmt.Errorf("failed to ensure NetworkPolicy: %w", err)
+	}
+	metrics.RecordNginxOperation("network_policy", metrics.ResultSuccess)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+)
+
+// phase3PublishOutputs writes the merged JWKS into additional outputs (spec.outputs)
+// It runs without configured outputs as well, to clean up outputs removed from the spec
+func (l *ReconciliationLoop) phase3PublishOutputs(ctx context.Context, jwks *v1alpha1.JWKS, mergedJWKS *jwks.JWKS) error {
+	l.logger.Debug("publishing JWKS outputs",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.Int("outputs", len(jwks.Spec.Outputs)),
+	)
+
+	if err := l.outputPublisher.Publish(ctx, jwks, mergedJWKS); err != nil {
+		l.logger.Error("failed to publish JWKS outputs",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+		metrics.RecordConfigMapUpdate("output", metrics.ResultError)
+		return fmt.Errorf("failed to publish outputs: %w", err)
+	}
+
+	if len(jwks.Spec.Outputs) == 0 {
+		return nil // No additional outputs configured
+	}
+
+	metrics.RecordConfigMapUpdate("output", metrics.ResultSuccess)
+	l.logger.Info("JWKS outputs published successfully",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.Int("outputs", len(jwks.Spec.Outputs)),
+	)
+
+	return nil
+}
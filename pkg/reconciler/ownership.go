@@ -0,0 +1,156 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/output"
+	"github.com/jwks-operator/jwks-operator/pkg/server"
+	"github.com/jwks-operator/jwks-operator/pkg/utils"
+)
+
+// generatedObject is an object generated for JWKS in its namespace
+type generatedObject struct {
+	object client.Object
+	// data marks ConfigMaps and Secrets that hold JWKS data and are subject to cleanupOnDelete
+	data bool
+	// managedOnly restricts ownership to objects created by the operator (carrying its labels)
+	managedOnly bool
+}
+
+// generatedObjects returns empty objects (name and namespace only) generated for JWKS
+func generatedObjects(jwks *v1alpha1.JWKS) []generatedObject {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: jwks.Namespace}
+	}
+
+	objects := []generatedObject{
+		{object: &corev1.ConfigMap{ObjectMeta: meta(jwks.Spec.ConfigMapName)}, data: true},
+	}
+
+	// Server objects are only generated by the nginx backend, an external server may use the same names
+	if server.BackendName(jwks) == server.BackendNginx {
+		objects = append(objects,
+			// Generated from the spec and not kept with cleanupOnDelete disabled
+			generatedObject{object: &corev1.ConfigMap{ObjectMeta: meta(jwks.Spec.NginxConfigMapName)}},
+			generatedObject{object: &appsv1.Deployment{ObjectMeta: meta(nginx.GetDeploymentName(jwks.Name))}},
+			generatedObject{object: &corev1.Service{ObjectMeta: meta(jwks.Name)}},
+			generatedObject{object: &policyv1.PodDisruptionBudget{ObjectMeta: meta(jwks.Name)}, managedOnly: true},
+			generatedObject{object: &autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: meta(jwks.Name)}, managedOnly: true},
+			generatedObject{object: &networkingv1.NetworkPolicy{ObjectMeta: meta(jwks.Name)}, managedOnly: true},
+		)
+	}
+
+	seen := make(map[string]bool)
+	for _, raw := range jwks.Spec.Outputs {
+		out := output.NormalizeOutput(raw)
+		id := out.Kind + "/" + out.Name
+		if seen[id] || (out.Kind == config.OutputKindConfigMap && out.Name == jwks.Spec.ConfigMapName) {
+			continue
+		}
+		seen[id] = true
+
+		var obj client.Object
+		switch out.Kind {
+		case config.OutputKindSecret:
+			obj = &corev1.Secret{ObjectMeta: meta(out.Name)}
+		default:
+			obj = &corev1.ConfigMap{ObjectMeta: meta(out.Name)}
+		}
+		objects = append(objects, generatedObject{object: obj, data: true, managedOnly: true})
+	}
+
+	return objects
+}
+
+// isManagedBy checks if the object carries the operator labels for this JWKS
+func isManagedBy(obj client.Object, jwks *v1alpha1.JWKS) bool {
+	labels := obj.GetLabels()
+	return labels[config.LabelManagedBy] == config.LabelManagedByValue &&
+		labels[config.LabelJWKSConfig] == jwks.Name
+}
+
+// phase6EnsureOwnerReferences sets JWKS as controller owner of generated resources
+// so that changes to them trigger reconciliation and garbage collection removes them with JWKS
+func (l *ReconciliationLoop) phase6EnsureOwnerReferences(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	for _, generated := range generatedObjects(jwks) {
+		obj := generated.object
+		if err := l.client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %T %s: %w", obj, obj.GetName(), err)
+		}
+
+		if generated.managedOnly && !isManagedBy(obj, jwks) {
+			continue
+		}
+
+		changed, err := utils.SetControllerReference(jwks, obj, l.client.Scheme())
+		if err != nil {
+			return fmt.Errorf("failed to set owner reference on %T %s: %w", obj, obj.GetName(), err)
+		}
+		if !changed {
+			continue
+		}
+
+		if err := l.client.Update(ctx, obj); err != nil {
+			return fmt.Errorf("failed to update owner reference on %T %s: %w", obj, obj.GetName(), err)
+		}
+
+		l.logger.Debug("owner reference set",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("object", obj.GetName()),
+		)
+	}
+
+	return nil
+}
+
+// orphanDataObjects removes JWKS owner references from ConfigMaps and Secrets holding data
+// so that garbage collection keeps them after JWKS is deleted
+func (l *ReconciliationLoop) orphanDataObjects(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	for _, generated := range generatedObjects(jwks) {
+		if !generated.data {
+			continue
+		}
+
+		obj := generated.object
+		if err := l.client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %T %s: %w", obj, obj.GetName(), err)
+		}
+
+		if !utils.RemoveOwnerReference(jwks, obj) {
+			continue
+		}
+
+		if err := l.client.Update(ctx, obj); err != nil {
+			return fmt.Errorf("failed to orphan %T %s: %w", obj, obj.GetName(), err)
+		}
+
+		l.logger.Info("resource orphaned, cleanupOnDelete is disabled",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("object", obj.GetName()),
+		)
+	}
+
+	return nil
+}
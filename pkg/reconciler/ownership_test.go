@@ -0,0 +1,96 @@
+package reconciler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// newOwnershipJWKS returns a JWKS resource with the given nginx ConfigMap and outputs
+func newOwnershipJWKS(nginxConfigMap string, outputs ...v1alpha1.OutputSpec) *v1alpha1.JWKS {
+	return &v1alpha1.JWKS{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+		Spec: v1alpha1.JWKSSpec{
+			ConfigMapName:      "auth-jwks",
+			NginxConfigMapName: nginxConfigMap,
+			Outputs:            outputs,
+		},
+	}
+}
+
+func TestGeneratedObjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		jwks   *v1alpha1.JWKS
+		server *v1alpha1.ServerSpec
+		want   []string
+	}{
+		{
+			name: "JWKS ConfigMap only",
+			jwks: newOwnershipJWKS(""),
+			want: []string{"*v1.ConfigMap/auth-jwks data"},
+		},
+		{
+			name: "nginx resources",
+			jwks: newOwnershipJWKS("auth-nginx"),
+			want: []string{
+				"*v1.ConfigMap/auth-jwks data",
+				"*v1.ConfigMap/auth-nginx",
+				"*v1.Deployment/auth",
+				"*v1.Service/auth",
+				"*v1.PodDisruptionBudget/auth managed",
+				"*v2.HorizontalPodAutoscaler/auth managed",
+				"*v1.NetworkPolicy/auth managed",
+			},
+		},
+		{
+			name:   "external server",
+			jwks:   newOwnershipJWKS("auth-nginx"),
+			server: &v1alpha1.ServerSpec{Type: config.ServerTypeExternal},
+			want:   []string{"*v1.ConfigMap/auth-jwks data"},
+		},
+		{
+			name: "outputs are owned only when managed",
+			jwks: newOwnershipJWKS("",
+				v1alpha1.OutputSpec{Name: "gateway"},
+				v1alpha1.OutputSpec{Name: "gateway", Key: "keys.json"},
+				v1alpha1.OutputSpec{Kind: config.OutputKindSecret, Name: "gateway"},
+				v1alpha1.OutputSpec{Name: "auth-jwks", Key: "keys.json"},
+			),
+			want: []string{
+				"*v1.ConfigMap/auth-jwks data",
+				"*v1.ConfigMap/gateway data managed",
+				"*v1.Secret/gateway data managed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.jwks.Spec.Server = tt.server
+
+			got := make([]string, 0)
+			for _, generated := range generatedObjects(tt.jwks) {
+				desc := fmt.Sprintf("%T/%s", generated.object, generated.object.GetName())
+				if generated.object.GetNamespace() != tt.jwks.Namespace {
+					t.Errorf("%s is in namespace %q, want %q", desc, generated.object.GetNamespace(), tt.jwks.Namespace)
+				}
+				if generated.data {
+					desc += " data"
+				}
+				if generated.managedOnly {
+					desc += " managed"
+				}
+				got = append(got, desc)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generatedObjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -74,7 +74,8 @@ func (l *ReconciliationLoop) phase2GenerateJWKS(secret *corev1.Secret) (*jwks.JW
 }
 
 // phase3UpdateConfigMap ensures JWKS ConfigMap exists and updates it
-func (l *ReconciliationLoop) phase3UpdateConfigMap(ctx context.Context, jwks *v1alpha1.JWKS, newJWKS *jwks.JWKS) error {
+// Returns the merged JWKS that was written to the ConfigMap
+func (l *ReconciliationLoop) phase3UpdateConfigMap(ctx context.Context, jwks *v1alpha1.JWKS, newJWKS *jwks.JWKS) (*jwks.JWKS, error) {
 	l.logger.Debug("updating JWKS ConfigMap",
 		zap.String("namespace", jwks.Namespace),
 		zap.String("name", jwks.Name),
@@ -89,7 +90,13 @@ func (l *ReconciliationLoop) phase3UpdateConfigMap(ctx context.Context, jwks *v1
 			zap.String("configMap", jwks.Spec.ConfigMapName),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to ensure ConfigMap: %w", err)
+		return nil, fmt.Errorf("failed to ensure ConfigMap: %w", err)
+	}
+
+	// Detect manual changes before merging, so tampered keys are never adopted as old keys
+	reportOnly, err := l.checkDrift(ctx, jwks)
+	if err != nil {
+		return nil, err
 	}
 
 	updateStrategy := l.getUpdateStrategy(jwks)
@@ -103,7 +110,13 @@ func (l *ReconciliationLoop) phase3UpdateConfigMap(ctx context.Context, jwks *v1
 	)
 
 	strategy := configmap.NewUpdateStrategy(l.configMapManager)
-	if err := strategy.Apply(ctx, jwks.Namespace, jwks.Spec.ConfigMapName, newJWKS, updateStrategy, keepOldKeys); err != nil {
+	if reportOnly {
+		// Drift is only reported, keep the ConfigMap untouched
+		return strategy.Plan(ctx, jwks.Namespace, jwks.Spec.ConfigMapName, newJWKS, updateStrategy, keepOldKeys)
+	}
+
+	mergedJWKS, err := strategy.Apply(ctx, jwks.Namespace, jwks.Spec.ConfigMapName, newJWKS, updateStrategy, keepOldKeys)
+	if err != nil {
 		l.logger.Error("failed to update ConfigMap",
 			zap.String("namespace", jwks.Namespace),
 			zap.String("name", jwks.Name),
@@ -111,7 +124,7 @@ func (l *ReconciliationLoop) phase3UpdateConfigMap(ctx context.Context, jwks *v1
 			zap.Error(err),
 		)
 		metrics.RecordConfigMapUpdate("jwks", metrics.ResultError)
-		return fmt.Errorf("failed to update ConfigMap: %w", err)
+		return nil, fmt.Errorf("failed to update ConfigMap: %w", err)
 	}
 
 	metrics.RecordConfigMapUpdate("jwks", metrics.ResultSuccess)
@@ -121,129 +134,13 @@ func (l *ReconciliationLoop) phase3UpdateConfigMap(ctx context.Context, jwks *v1
 		zap.String("configMap", jwks.Spec.ConfigMapName),
 	)
 
-	return nil
-}
-
-// phase4UpdateNginxConfig ensures nginx ConfigMap exists and updates it
-func (l *ReconciliationLoop) phase4UpdateNginxConfig(ctx context.Context, jwks *v1alpha1.JWKS) error {
-	if jwks.Spec.NginxConfigMapName == "" {
-		return nil // Nginx not configured
-	}
-
-	l.logger.Debug("updating nginx ConfigMap",
-		zap.String("namespace", jwks.Namespace),
-		zap.String("name", jwks.Name),
-		zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
-	)
-
-	endpoint := l.getEndpoint(jwks)
-	if err := l.ensureNginxConfigMap(ctx, jwks, endpoint); err != nil {
-		l.logger.Error("failed to ensure nginx ConfigMap",
-			zap.String("namespace", jwks.Namespace),
-			zap.String("name", jwks.Name),
-			zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
-			zap.Error(err),
-		)
-		return fmt.Errorf("failed to ensure nginx ConfigMap: %w", err)
-	}
-
-	if err := l.nginxManager.UpdateConfig(ctx, jwks.Namespace, jwks.Spec.NginxConfigMapName, jwks.Spec.ConfigMapName, endpoint); err != nil {
-		l.logger.Error("failed to update nginx config",
-			zap.String("namespace", jwks.Namespace),
-			zap.String("name", jwks.Name),
-			zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
-			zap.Error(err),
-		)
-		metrics.RecordConfigMapUpdate("nginx", metrics.ResultError)
-		metrics.RecordNginxOperation("config", metrics.ResultError)
-		return fmt.Errorf("failed to update nginx config: %w", err)
-	}
-
-	metrics.RecordConfigMapUpdate("nginx", metrics.ResultSuccess)
-	metrics.RecordNginxOperation("config", metrics.ResultSuccess)
-	l.logger.Info("nginx ConfigMap updated successfully",
-		zap.String("namespace", jwks.Namespace),
-		zap.String("name", jwks.Name),
-		zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
-	)
-
-	l.statusUpdater.UpdateNginxConfigUpdated(jwks)
-	return nil
-}
-
-// phase5EnsureNginxDeployment ensures nginx Deployment exists
-func (l *ReconciliationLoop) phase5EnsureNginxDeployment(ctx context.Context, jwks *v1alpha1.JWKS) error {
-	if jwks.Spec.NginxConfigMapName == "" {
-		return nil // Nginx not configured
-	}
-
-	l.logger.Debug("ensuring nginx Deployment",
-		zap.String("namespace", jwks.Namespace),
-		zap.String("name", jwks.Name),
-	)
-
-	endpoint := l.getEndpoint(jwks)
-	if err := l.nginxManager.EnsureDeployment(
-		ctx,
-		jwks.Namespace,
-		jwks.Name,
-		jwks.Spec.NginxConfigMapName,
-		jwks.Spec.ConfigMapName,
-		endpoint,
-		&l.config.Nginx.Resources,
-	); err != nil {
-		l.logger.Error("failed to ensure nginx deployment",
-			zap.String("namespace", jwks.Namespace),
-			zap.String("name", jwks.Name),
-			zap.Error(err),
-		)
-		metrics.RecordNginxOperation("deployment", metrics.ResultError)
-		return fmt.Errorf("failed to ensure nginx deployment: %w", err)
-	}
-
-	metrics.RecordNginxOperation("deployment", metrics.ResultSuccess)
-	l.logger.Info("nginx Deployment ensured successfully",
-		zap.String("namespace", jwks.Namespace),
-		zap.String("name", jwks.Name),
-	)
-
-	return nil
-}
-
-// phase6EnsureNginxService ensures nginx Service exists
-func (l *ReconciliationLoop) phase6EnsureNginxService(ctx context.Context, jwks *v1alpha1.JWKS) error {
-	if jwks.Spec.NginxConfigMapName == "" {
-		return nil // Nginx not configured
-	}
-
-	l.logger.Debug("ensuring nginx Service",
-		zap.String("namespace", jwks.Namespace),
-		zap.String("name", jwks.Name),
-	)
-
-	if err := l.nginxManager.EnsureService(ctx, jwks.Namespace, jwks.Name); err != nil {
-		l.logger.Error("failed to ensure nginx service",
-			zap.String("namespace", jwks.Namespace),
-			zap.String("name", jwks.Name),
-			zap.Error(err),
-		)
-		metrics.RecordNginxOperation("service", metrics.ResultError)
-		return fmt.Errorf("failed to ensure nginx service: %w", err)
-	}
-
-	metrics.RecordNginxOperation("service", metrics.ResultSuccess)
-	l.logger.Info("nginx Service ensured successfully",
-		zap.String("namespace", jwks.Namespace),
-		zap.String("name", jwks.Name),
-	)
-
-	return nil
+	return mergedJWKS, nil
 }
 
 // phase7VerifyJWKS verifies JWKS from nginx (periodic verification)
 func (l *ReconciliationLoop) phase7VerifyJWKS(ctx context.Context, jwks *v1alpha1.JWKS, secret *corev1.Secret) error {
-	if jwks.Spec.NginxConfigMapName == "" {
-		return nil // Nginx not configured
+	if !l.serverDeployed(jwks) {
+		return nil // Server not deployed by the operator
 	}
 
 	// Check if this is first fast reconciliation after restart (force verification)
@@ -264,6 +161,15 @@ func (l *ReconciliationLoop) phase7VerifyJWKS(ctx context.Context, jwks *v1alpha
 		return nil // Not time to verify yet
 	}
 
+	// Pods serve old and new content while kubelet refreshes ConfigMap volumes
+	if l.propagationPending(jwks) && !propagationTimedOut(jwks) {
+		l.logger.Debug("JWKS is still propagating to server pods, postponing verification",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+		)
+		return nil
+	}
+
 	// Check if Service has ready endpoints before verification
 	if err := l.waitForServiceEndpoints(ctx, jwks.Namespace, jwks.Name); err != nil {
 		l.logger.Warn("Service endpoints not ready, skipping verification",
@@ -280,6 +186,7 @@ func (l *ReconciliationLoop) phase7VerifyJWKS(ctx context.Context, jwks *v1alpha
 		zap.String("namespace", jwks.Namespace),
 		zap.String("name", jwks.Name),
 		zap.String("service", jwks.Name),
+		zap.String("url", l.backends.For(jwks).EndpointURL(jwks, l.getNginxConfigOptions(jwks))),
 	)
 
 	// Use a context with timeout for verification
@@ -318,7 +225,7 @@ func (l *ReconciliationLoop) phase7VerifyJWKS(ctx context.Context, jwks *v1alpha
 
 	verifyErr = utils.RetryWithDelay(verifyCtx, retryConfig, func() error {
 		attemptCount++
-		err := l.verifier.VerifyJWKSFromNginx(verifyCtx, jwks.Namespace, jwks.Name, secret)
+		err := l.verifyServer(verifyCtx, jwks, secret)
 		if err != nil {
 			l.logger.Debug("JWKS verification attempt failed",
 				zap.String("namespace", jwks.Namespace),
@@ -385,22 +292,6 @@ func (l *ReconciliationLoop) ensureJWKSConfigMap(ctx context.Context, jwks *v1al
 	return l.configMapManager.CreateConfigMap(ctx, jwks.Namespace, jwks.Spec.ConfigMapName, jwksData)
 }
 
-// ensureNginxConfigMap checks if nginx ConfigMap exists, recreates if deleted
-func (l *ReconciliationLoop) ensureNginxConfigMap(ctx context.Context, jwks *v1alpha1.JWKS, endpoint string) error {
-	exists, err := utils.EnsureConfigMapExists(ctx, l.client, jwks.Namespace, jwks.Spec.NginxConfigMapName)
-	if err != nil {
-		return fmt.Errorf("failed to check nginx ConfigMap: %w", err)
-	}
-
-	if exists {
-		// ConfigMap exists, nothing to do
-		return nil
-	}
-
-	// ConfigMap was deleted, recreate it by calling UpdateConfig which will create it
-	return l.nginxManager.UpdateConfig(ctx, jwks.Namespace, jwks.Spec.NginxConfigMapName, jwks.Spec.ConfigMapName, endpoint)
-}
-
 // waitForServiceEndpoints waits for Service to have ready endpoints
 // Uses Pods directly instead of Endpoints to avoid deprecation warnings
 func (l *ReconciliationLoop) waitForServiceEndpoints(ctx context.Context, namespace, serviceName string) error {
@@ -0,0 +1,149 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// conditionContentPropagated is the condition type reporting whether every server pod serves the current JWKS
+const conditionContentPropagated = "ContentPropagated"
+
+// trackContentDigest records when the JWKS ConfigMap content changed
+// Propagation of the new content is then checked until every server pod serves it
+func (l *ReconciliationLoop) trackContentDigest(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	digest, err := l.configMapManager.GetContentDigest(ctx, jwks.Namespace, jwks.Spec.ConfigMapName)
+	if err != nil {
+		return fmt.Errorf("failed to get JWKS content digest: %w", err)
+	}
+
+	if digest == jwks.Status.ContentDigest {
+		return nil
+	}
+
+	now := metav1.Now()
+	jwks.Status.ContentDigest = digest
+	jwks.Status.ContentUpdateTime = &now
+	jwks.Status.PropagationDuration = nil
+
+	if l.serverDeployed(jwks) && digest != "" {
+		l.statusUpdater.SetCondition(jwks, conditionContentPropagated, metav1.ConditionFalse, "Propagating",
+			"Waiting for server pods to serve the updated JWKS")
+	}
+	return nil
+}
+
+// propagationPending checks if the current JWKS content has not reached every server pod yet
+func (l *ReconciliationLoop) propagationPending(jwks *v1alpha1.JWKS) bool {
+	return l.serverDeployed(jwks) &&
+		jwks.Status.ContentDigest != "" &&
+		jwks.Status.PropagationDuration == nil
+}
+
+// propagationTimedOut checks if the current JWKS content has been propagating for longer than kubelet needs
+func propagationTimedOut(jwks *v1alpha1.JWKS) bool {
+	return jwks.Status.ContentUpdateTime == nil ||
+		time.Since(jwks.Status.ContentUpdateTime.Time) > config.DefaultPropagationTimeout
+}
+
+// checkPropagation checks if every ready server pod serves the current JWKS content
+// and reports the time it took in status and metrics
+func (l *ReconciliationLoop) checkPropagation(ctx context.Context, jwks *v1alpha1.JWKS) {
+	if !l.serverDeployed(jwks) {
+		l.statusUpdater.RemoveCondition(jwks, conditionContentPropagated)
+		return
+	}
+	if !l.propagationPending(jwks) {
+		return
+	}
+
+	pods := &corev1.PodList{}
+	if err := l.client.List(ctx, pods,
+		client.InNamespace(jwks.Namespace),
+		client.MatchingLabels(nginx.SelectorLabels(jwks.Name)),
+	); err != nil {
+		l.logger.Debug("failed to list server pods, will retry",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	jwksPath := l.getNginxConfigOptions(jwks).JWKSPath()
+	ready, served := 0, 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		port, ok := podHTTPPort(pod)
+		if !ok || !isPodReady(pod) {
+			continue
+		}
+		ready++
+
+		if err := l.verifier.VerifyContentFromPod(ctx, pod.Status.PodIP, port, jwksPath,
+			jwks.Status.ContentDigest); err != nil {
+			l.logger.Debug("server pod does not serve the current JWKS yet",
+				zap.String("namespace", jwks.Namespace),
+				zap.String("name", jwks.Name),
+				zap.String("pod", pod.Name),
+				zap.Error(err),
+			)
+			continue
+		}
+		served++
+	}
+
+	if ready == 0 || served < ready {
+		l.statusUpdater.SetCondition(jwks, conditionContentPropagated, metav1.ConditionFalse, "Propagating",
+			fmt.Sprintf("%d of %d ready server pods serve the updated JWKS", served, ready))
+		return
+	}
+
+	duration := time.Since(jwks.Status.ContentUpdateTime.Time).Round(time.Second)
+	jwks.Status.PropagationDuration = &metav1.Duration{Duration: duration}
+	metrics.RecordJWKSPropagation(duration.Seconds())
+
+	l.logger.Info("JWKS propagated to server pods",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.Int("pods", ready),
+		zap.Duration("propagationDuration", duration),
+	)
+	l.statusUpdater.SetCondition(jwks, conditionContentPropagated, metav1.ConditionTrue, "Propagated",
+		fmt.Sprintf("%d ready server pods serve the current JWKS, propagation took %s", ready, duration))
+}
+
+// podHTTPPort returns the nginx HTTP port of a server pod
+func podHTTPPort(pod *corev1.Pod) (int32, bool) {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == "http" {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isPodReady checks if the pod has an IP and passes its readiness probe
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
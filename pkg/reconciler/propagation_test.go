@@ -0,0 +1,129 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+func TestPropagationPending(t *testing.T) {
+	tests := []struct {
+		name           string
+		nginxConfigMap string
+		serverType     string
+		digest         string
+		duration       *metav1.Duration
+		want           bool
+	}{
+		{name: "without server", digest: "abc"},
+		{name: "external server", nginxConfigMap: "auth-nginx", serverType: config.ServerTypeExternal, digest: "abc"},
+		{name: "without content", nginxConfigMap: "auth-nginx"},
+		{name: "propagating", nginxConfigMap: "auth-nginx", digest: "abc", want: true},
+		{name: "propagated", nginxConfigMap: "auth-nginx", digest: "abc", duration: &metav1.Duration{Duration: time.Second}},
+	}
+
+	loop := newBackendTestLoop()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwks := newOwnershipJWKS(tt.nginxConfigMap)
+			if tt.serverType != "" {
+				jwks.Spec.Server = &v1alpha1.ServerSpec{Type: tt.serverType}
+			}
+			jwks.Status.ContentDigest = tt.digest
+			jwks.Status.PropagationDuration = tt.duration
+
+			if got := loop.propagationPending(jwks); got != tt.want {
+				t.Errorf("propagationPending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropagationTimedOut(t *testing.T) {
+	recent := metav1.NewTime(time.Now())
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name       string
+		updateTime *metav1.Time
+		want       bool
+	}{
+		{name: "unknown update time", want: true},
+		{name: "recent update", updateTime: &recent},
+		{name: "old update", updateTime: &old, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwks := newOwnershipJWKS("auth-nginx")
+			jwks.Status.ContentUpdateTime = tt.updateTime
+
+			if got := propagationTimedOut(jwks); got != tt.want {
+				t.Errorf("propagationTimedOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerPodState(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name      string
+		pod       corev1.Pod
+		wantReady bool
+		wantPort  int32
+	}{
+		{
+			name: "ready pod",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "nginx", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+				}},
+				Status: corev1.PodStatus{
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			wantReady: true,
+			wantPort:  8080,
+		},
+		{
+			name: "not ready pod",
+			pod: corev1.Pod{
+				Status: corev1.PodStatus{
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+				},
+			},
+		},
+		{
+			name: "terminating pod",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+				Status: corev1.PodStatus{
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPodReady(&tt.pod); got != tt.wantReady {
+				t.Errorf("isPodReady() = %v, want %v", got, tt.wantReady)
+			}
+			port, ok := podHTTPPort(&tt.pod)
+			if port != tt.wantPort || ok != (tt.wantPort != 0) {
+				t.Errorf("podHTTPPort() = %d, %v, want %d", port, ok, tt.wantPort)
+			}
+		})
+	}
+}
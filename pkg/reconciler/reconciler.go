@@ -6,6 +6,7 @@ import (
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
@@ -13,6 +14,7 @@ import (
 	"github.com/jwks-operator/jwks-operator/pkg/configmap"
 	"github.com/jwks-operator/jwks-operator/pkg/jwks"
 	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/server"
 )
 
 // Reconciler reconciles JWKS resources
@@ -20,7 +22,7 @@ type Reconciler struct {
 	client             client.Client
 	jwksGenerator      *jwks.Generator
 	configMapManager   *configmap.Manager
-	nginxManager       *nginx.Manager
+	backends           *server.Backends
 	statusUpdater      *StatusUpdater
 	reconciliationLoop *ReconciliationLoop
 	config             *config.Config
@@ -30,12 +32,17 @@ type Reconciler struct {
 // NewReconciler creates a new reconciler
 func NewReconciler(
 	client client.Client,
+	recorder record.EventRecorder,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *Reconciler {
 	jwksGenerator := jwks.NewGenerator()
 	configMapManager := configmap.NewManager(client)
 	nginxManager := nginx.NewManager(client, &cfg.Nginx)
+	backends := server.NewBackends(
+		server.NewNginxBackend(nginxManager, &cfg.Nginx.Resources),
+		server.ExternalBackend{},
+	)
 	statusUpdater := NewStatusUpdater(client)
 
 	reconciliationLoop := NewReconciliationLoop(
@@ -43,7 +50,9 @@ func NewReconciler(
 		jwksGenerator,
 		configMapManager,
 		nginxManager,
+		backends,
 		statusUpdater,
+		recorder,
 		cfg,
 		logger,
 	)
@@ -52,7 +61,7 @@ func NewReconciler(
 		client:             client,
 		jwksGenerator:      jwksGenerator,
 		configMapManager:   configMapManager,
-		nginxManager:       nginxManager,
+		backends:           backends,
 		statusUpdater:      statusUpdater,
 		reconciliationLoop: reconciliationLoop,
 		config:             cfg,
@@ -86,6 +95,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, jwks *v1alpha1.JWKS) error {
 	// Check if verification is needed (independent of reconciliation)
 	needsVerification := r.reconciliationLoop.shouldVerifyJWKS(jwks, forceVerification)
 
+	// New JWKS content is checked on server pods until it has propagated
+	if r.reconciliationLoop.propagationPending(jwks) {
+		needsVerification = true
+	}
+
 	// If neither reconciliation nor verification is needed, skip
 	if !needsReconciliation && !needsVerification {
 		r.logger.Debug("skipping reconciliation and verification - too soon since last update/verification",
@@ -166,24 +180,17 @@ func (r *Reconciler) Cleanup(ctx context.Context, namespace, jwksName string) er
 		zap.String("name", jwksName),
 	)
 
-	// Delete nginx Service
-	if err := r.nginxManager.DeleteService(ctx, namespace, jwksName); err != nil {
-		r.logger.Warn("failed to delete nginx service",
-			zap.String("namespace", namespace),
-			zap.String("name", jwksName),
-			zap.Error(err),
-		)
-		// Continue cleanup even if service deletion fails
-	}
-
-	// Delete nginx Deployment
-	if err := r.nginxManager.DeleteDeployment(ctx, namespace, jwksName); err != nil {
-		r.logger.Warn("failed to delete nginx deployment",
-			zap.String("namespace", namespace),
-			zap.String("name", jwksName),
-			zap.Error(err),
-		)
-		// Continue cleanup even if deployment deletion fails
+	// Delete server objects of every backend, the JWKS may have switched backends
+	for _, backend := range r.backends.All() {
+		if err := backend.Delete(ctx, namespace, jwksName); err != nil {
+			r.logger.Warn("failed to delete server resources",
+				zap.String("namespace", namespace),
+				zap.String("name", jwksName),
+				zap.String("backend", backend.Name()),
+				zap.Error(err),
+			)
+			// Continue cleanup even if deletion fails
+		}
 	}
 
 	r.logger.Info("cleanup completed",
@@ -194,6 +201,33 @@ func (r *Reconciler) Cleanup(ctx context.Context, namespace, jwksName string) er
 	return nil
 }
 
+// Finalize runs cleanup for a JWKS resource that is being deleted
+// Server resources and the generated nginx configuration are always removed; ConfigMaps and Secrets
+// holding data and replicas are removed if cleanupOnDelete is enabled and kept otherwise
+func (r *Reconciler) Finalize(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	if err := r.Cleanup(ctx, jwks.Namespace, jwks.Name); err != nil {
+		return err
+	}
+
+	if r.reconciliationLoop.shouldCleanupOnDelete(jwks) {
+		if err := r.reconciliationLoop.replicator.DeleteReplicas(ctx, jwks); err != nil {
+			return fmt.Errorf("failed to delete JWKS ConfigMap replicas: %w", err)
+		}
+
+		r.logger.Info("cleanupOnDelete enabled, data resources will be garbage collected",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+		)
+		return nil
+	}
+
+	if err := r.reconciliationLoop.orphanDataObjects(ctx, jwks); err != nil {
+		return fmt.Errorf("failed to orphan data resources: %w", err)
+	}
+
+	return nil
+}
+
 // getSecretForVerification gets Secret for verification purposes
 func (r *Reconciler) getSecretForVerification(ctx context.Context, jwks *v1alpha1.JWKS) (*corev1.Secret, error) {
 	return r.reconciliationLoop.phase1GetSecret(ctx, jwks)
@@ -201,14 +235,14 @@ func (r *Reconciler) getSecretForVerification(ctx context.Context, jwks *v1alpha
 
 // performVerificationOnly performs only JWKS verification without full reconciliation
 func (r *Reconciler) performVerificationOnly(ctx context.Context, jwks *v1alpha1.JWKS, secret *corev1.Secret) error {
-	// Check if Nginx is configured
-	if jwks.Spec.NginxConfigMapName == "" {
-		return nil // Nginx not configured, skip verification
+	// Check if the server is deployed by the operator
+	if !r.reconciliationLoop.serverDeployed(jwks) {
+		return nil // No server, skip verification
 	}
 
 	// Before verification, ensure Service exists
 	// This is important because performVerificationOnly can be called before full reconciliation
-	if err := r.nginxManager.EnsureService(ctx, jwks.Namespace, jwks.Name); err != nil {
+	if err := r.backends.For(jwks).EnsureService(ctx, jwks); err != nil {
 		r.logger.Warn("failed to ensure service before verification, skipping",
 			zap.String("namespace", jwks.Namespace),
 			zap.String("name", jwks.Name),
@@ -218,5 +252,12 @@ func (r *Reconciler) performVerificationOnly(ctx context.Context, jwks *v1alpha1
 		return nil
 	}
 
+	r.reconciliationLoop.checkPropagation(ctx, jwks)
 	return r.reconciliationLoop.phase7VerifyJWKS(ctx, jwks, secret)
 }
+
+// PropagationPending checks if new JWKS content has not reached every server pod yet
+// The controller requeues such resources sooner to report the propagation time
+func (r *Reconciler) PropagationPending(jwks *v1alpha1.JWKS) bool {
+	return r.reconciliationLoop.propagationPending(jwks)
+}
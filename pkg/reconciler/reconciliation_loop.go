@@ -2,6 +2,7 @@ package reconciler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,14 +11,19 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
 	"github.com/jwks-operator/jwks-operator/pkg/config"
 	"github.com/jwks-operator/jwks-operator/pkg/configmap"
+	"github.com/jwks-operator/jwks-operator/pkg/expose"
 	"github.com/jwks-operator/jwks-operator/pkg/jwks"
 	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/monitoring"
 	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/output"
+	"github.com/jwks-operator/jwks-operator/pkg/server"
 	"github.com/jwks-operator/jwks-operator/pkg/verification"
 )
 
@@ -27,7 +33,13 @@ type ReconciliationLoop struct {
 	jwksGenerator    *jwks.Generator
 	configMapManager *configmap.Manager
 	nginxManager     *nginx.Manager
+	backends         *server.Backends
+	outputPublisher  *output.Publisher
+	replicator       *configmap.Replicator
+	exposeManager    *expose.Manager
+	monitorManager   *monitoring.Manager
 	statusUpdater    *StatusUpdater
+	recorder         record.EventRecorder
 	verifier         *verification.Verifier
 	config           *config.Config
 	logger           *zap.Logger
@@ -39,7 +51,9 @@ func NewReconciliationLoop(
 	jwksGenerator *jwks.Generator,
 	configMapManager *configmap.Manager,
 	nginxManager *nginx.Manager,
+	backends *server.Backends,
 	statusUpdater *StatusUpdater,
+	recorder record.EventRecorder,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *ReconciliationLoop {
@@ -48,7 +62,13 @@ func NewReconciliationLoop(
 		jwksGenerator:    jwksGenerator,
 		configMapManager: configMapManager,
 		nginxManager:     nginxManager,
+		backends:         backends,
+		outputPublisher:  output.NewPublisher(client),
+		replicator:       configmap.NewReplicator(client),
+		exposeManager:    expose.NewManager(client),
+		monitorManager:   monitoring.NewManager(client),
 		statusUpdater:    statusUpdater,
+		recorder:         recorder,
 		verifier:         verification.NewVerifier(&cfg.Verification),
 		config:           cfg,
 		logger:           logger,
@@ -90,16 +110,52 @@ func (l *ReconciliationLoop) Execute(ctx context.Context, jwks *v1alpha1.JWKS) e
 	}
 
 	// Phase 3: Ensure JWKS ConfigMap exists and update with JWKS
-	if err := l.phase3UpdateConfigMap(ctx, jwks, newJWKS); err != nil {
+	mergedJWKS, err := l.phase3UpdateConfigMap(ctx, jwks, newJWKS)
+	if err != nil {
 		result = metrics.ResultError
 		metrics.RecordError("configmap_update_failed")
 		l.statusUpdater.SetNotReady(jwks, "ConfigMapUpdateFailed", fmt.Sprintf("Failed to update ConfigMap: %v", err))
 		return err
 	}
 
+	// Phase 3.1: Store OpenID Connect discovery document next to the JWKS
+	if err := l.phase3PublishDiscovery(ctx, jwks, mergedJWKS); err != nil {
+		result = metrics.ResultError
+		metrics.RecordError("discovery_update_failed")
+		l.statusUpdater.SetNotReady(jwks, "DiscoveryUpdateFailed", fmt.Sprintf("Failed to update discovery document: %v", err))
+		return err
+	}
+
+	// Phase 3.2: Publish merged JWKS into additional outputs
+	if err := l.phase3PublishOutputs(ctx, jwks, mergedJWKS); err != nil {
+		result = metrics.ResultError
+		metrics.RecordError("output_publish_failed")
+		l.statusUpdater.SetNotReady(jwks, "OutputPublishFailed", fmt.Sprintf("Failed to publish outputs: %v", err))
+		return err
+	}
+
+	// Phase 3.3: Replicate JWKS ConfigMap into other namespaces
+	// Replication errors are reported in the Replicated condition
+	l.phase3ReplicateConfigMap(ctx, jwks)
+
 	// Phase 4: Ensure nginx ConfigMap exists and update if configured
 	if err := l.phase4UpdateNginxConfig(ctx, jwks); err != nil {
 		result = metrics.ResultError
+		if errors.Is(err, nginx.ErrInvalidTemplate) {
+			metrics.RecordError("nginx_template_invalid")
+			l.statusUpdater.SetNotReady(jwks, "NginxTemplateInvalid", fmt.Sprintf("Failed to render nginx config template: %v", err))
+			return err
+		}
+		if errors.Is(err, nginx.ErrBuiltinImageNotConfigured) {
+			metrics.RecordError("builtin_image_not_configured")
+			l.statusUpdater.SetNotReady(jwks, "BuiltinImageNotConfigured", err.Error())
+			return err
+		}
+		if errors.Is(err, nginx.ErrInvalidConfig) {
+			metrics.RecordError("nginx_config_invalid")
+			l.statusUpdater.SetNotReady(jwks, "NginxConfigInvalid", fmt.Sprintf("Generated nginx config failed validation: %v", err))
+			return err
+		}
 		metrics.RecordError("nginx_config_update_failed")
 		l.statusUpdater.SetNotReady(jwks, "NginxConfigUpdateFailed", fmt.Sprintf("Failed to update nginx config: %v", err))
 		return err
@@ -113,6 +169,22 @@ func (l *ReconciliationLoop) Execute(ctx context.Context, jwks *v1alpha1.JWKS) e
 		return err
 	}
 
+	// Phase 5.1: Ensure PodDisruptionBudget and HorizontalPodAutoscaler from spec.server
+	if err := l.phase5EnsureAvailability(ctx, jwks); err != nil {
+		result = metrics.ResultError
+		metrics.RecordError("nginx_availability_failed")
+		l.statusUpdater.SetNotReady(jwks, "NginxAvailabilityFailed", fmt.Sprintf("Failed to ensure server availability: %v", err))
+		return err
+	}
+
+	// Phase 5.2: Ensure NetworkPolicy from spec.networkPolicy
+	if err := l.phase5EnsureNetworkPolicy(ctx, jwks); err != nil {
+		result = metrics.ResultError
+		metrics.RecordError("nginx_network_policy_failed")
+		l.statusUpdater.SetNotReady(jwks, "NginxNetworkPolicyFailed", fmt.Sprintf("Failed to ensure network policy: %v", err))
+		return err
+	}
+
 	// Phase 6: Ensure nginx Service exists
 	if err := l.phase6EnsureNginxService(ctx, jwks); err != nil {
 		result = metrics.ResultError
@@ -121,6 +193,38 @@ func (l *ReconciliationLoop) Execute(ctx context.Context, jwks *v1alpha1.JWKS) e
 		return err
 	}
 
+	// Phase 6.1: Delete server objects of backends that no longer serve the JWKS
+	l.phase6RemoveInactiveBackends(ctx, jwks)
+
+	// Phase 6.2: Expose nginx Service via Ingress or HTTPRoute
+	// Expose errors are reported in the Exposed condition
+	l.phase6ExposeServer(ctx, jwks)
+
+	// Phase 6.3: Create ServiceMonitor or PodMonitor from spec.server.metrics.monitor
+	// Monitor errors are reported in the Monitored condition
+	l.phase6MonitorServer(ctx, jwks)
+
+	// Phase 6.4: Set owner references on generated resources
+	// Ownership errors are non-critical, resources keep working without owner references
+	if err := l.phase6EnsureOwnerReferences(ctx, jwks); err != nil {
+		l.logger.Warn("failed to ensure owner references",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+	}
+
+	// Phase 6.5: Track propagation of the JWKS content to server pods
+	// Pods pick up ConfigMap changes without restarts, status reports how long it took
+	if err := l.trackContentDigest(ctx, jwks); err != nil {
+		l.logger.Warn("failed to track JWKS content",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Error(err),
+		)
+	}
+	l.checkPropagation(ctx, jwks)
+
 	// Phase 7: Verify JWKS from nginx (periodic verification)
 	// Verification errors are non-critical, continue even if verification fails
 	_ = l.phase7VerifyJWKS(ctx, jwks, secret)
@@ -158,7 +262,7 @@ func (l *ReconciliationLoop) shouldReconcile(ctx context.Context, jwks *v1alpha1
 	// Check if nginx resources need to be created/updated
 	// This handles cases when operator restarts or is updated
 	// and resources might have been deleted or don't exist
-	if jwks.Spec.NginxConfigMapName != "" {
+	if l.serverDeployed(jwks) {
 		// Check if Deployment exists - if not, we need full reconciliation
 		deploymentName := jwks.Name
 		deployment := &appsv1.Deployment{}
@@ -211,6 +315,45 @@ func (l *ReconciliationLoop) shouldReconcile(ctx context.Context, jwks *v1alpha1
 		}
 	}
 
+	// Check if replicas are missing or stale (e.g. a matching namespace was created)
+	if jwks.Spec.ReplicateTo != nil {
+		inSync, err := l.replicator.InSync(ctx, jwks)
+		if err != nil {
+			l.logger.Debug("failed to check JWKS ConfigMap replicas, will retry",
+				zap.String("namespace", jwks.Namespace),
+				zap.String("name", jwks.Name),
+				zap.Error(err),
+			)
+		} else if !inSync {
+			return true
+		}
+	}
+
+	// Check if the JWKS ConfigMap was changed outside of the operator
+	if l.shouldRepairDrift(ctx, jwks) {
+		return true
+	}
+
+	// Check if the certificate in spec.server.tls was renewed
+	if l.tlsSecretChanged(ctx, jwks) {
+		return true
+	}
+
+	// Check if the nginx template or snippets in spec.server.template changed
+	if l.templateChanged(ctx, jwks) {
+		return true
+	}
+
+	// Check if a changed server config is waiting for the nginx -t Job
+	if configTestRunning(jwks) {
+		return true
+	}
+
+	// Check if the minimum availability reported by the PodDisruptionBudget changed
+	if l.availabilityChanged(ctx, jwks) {
+		return true
+	}
+
 	// Check if enough time has passed since last update
 	elapsed := time.Since(jwks.Status.LastUpdateTime.Time)
 	updateInterval := l.getJWKSUpdateInterval(jwks)
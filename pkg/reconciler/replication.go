@@ -0,0 +1,73 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+)
+
+// phase3ReplicateConfigMap copies the JWKS ConfigMap into namespaces selected by spec.replicateTo
+// Replication errors are reported in the Replicated condition and do not fail reconciliation
+func (l *ReconciliationLoop) phase3ReplicateConfigMap(ctx context.Context, jwks *v1alpha1.JWKS) {
+	hadReplicas := len(jwks.Status.ReplicatedNamespaces) > 0 || len(jwks.Status.ReplicaConflicts) > 0
+	if jwks.Spec.ReplicateTo == nil && !hadReplicas {
+		return // Replication not configured
+	}
+
+	result, err := l.replicator.Sync(ctx, jwks)
+	if err != nil {
+		l.logger.Error("failed to replicate JWKS ConfigMap",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("configMap", jwks.Spec.ConfigMapName),
+			zap.Error(err),
+		)
+		metrics.RecordConfigMapUpdate("replica", metrics.ResultError)
+		l.statusUpdater.SetCondition(jwks, "Replicated", metav1.ConditionFalse, "ReplicationFailed", err.Error())
+		return
+	}
+
+	metrics.RecordConfigMapUpdate("replica", metrics.ResultSuccess)
+	jwks.Status.ReplicatedNamespaces = result.Synced
+	jwks.Status.ReplicaConflicts = result.Conflicts
+
+	if len(result.Removed) > 0 {
+		l.logger.Info("removed JWKS ConfigMap replicas from namespaces out of scope",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.Strings("namespaces", result.Removed),
+		)
+	}
+
+	if jwks.Spec.ReplicateTo == nil {
+		l.statusUpdater.RemoveCondition(jwks, "Replicated")
+		return
+	}
+
+	if len(result.Conflicts) > 0 {
+		l.logger.Warn("JWKS ConfigMap not replicated, unmanaged ConfigMap with the same name exists",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("configMap", jwks.Spec.ConfigMapName),
+			zap.Strings("namespaces", result.Conflicts),
+		)
+		l.statusUpdater.SetCondition(jwks, "Replicated", metav1.ConditionFalse, "ReplicaConflict",
+			fmt.Sprintf("ConfigMap %s exists and is not managed by the operator in namespaces: %s",
+				jwks.Spec.ConfigMapName, strings.Join(result.Conflicts, ", ")))
+		return
+	}
+
+	l.logger.Debug("JWKS ConfigMap replicated",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.Strings("namespaces", result.Synced),
+	)
+	l.statusUpdater.SetCondition(jwks, "Replicated", metav1.ConditionTrue, "Replicated",
+		fmt.Sprintf("JWKS ConfigMap replicated to %d namespaces", len(result.Synced)))
+}
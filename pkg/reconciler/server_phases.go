@@ -0,0 +1,148 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/metrics"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// phase4UpdateNginxConfig renders the server config of the backend serving the JWKS
+func (l *ReconciliationLoop) phase4UpdateNginxConfig(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	if !l.serverDeployed(jwks) {
+		l.statusUpdater.RemoveCondition(jwks, conditionNginxConfigInvalid)
+		return nil // Server not deployed by the operator
+	}
+
+	backend := l.backends.For(jwks)
+	l.logger.Debug("updating server config",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("backend", backend.Name()),
+		zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
+	)
+
+	configOptions := l.getNginxConfigOptions(jwks)
+	content, digest, err := l.configMapManager.GetContent(ctx, jwks.Namespace, jwks.Spec.ConfigMapName)
+	if err != nil {
+		return fmt.Errorf("failed to get JWKS content: %w", err)
+	}
+	configOptions.Content, configOptions.ContentDigest = content, digest
+
+	// A changed config is only written after it passed validation, see reportConfigValidation
+	err = backend.RenderConfig(ctx, jwks, configOptions)
+	testPending := errors.Is(err, nginx.ErrConfigTestPending)
+	if err := l.reportConfigValidation(jwks, err); err != nil {
+		l.logger.Error("failed to update server config",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("backend", backend.Name()),
+			zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
+			zap.Error(err),
+		)
+		metrics.RecordConfigMapUpdate("nginx", metrics.ResultError)
+		metrics.RecordNginxOperation("config", metrics.ResultError)
+		return fmt.Errorf("failed to update %s config: %w", backend.Name(), err)
+	}
+	if testPending {
+		return nil
+	}
+
+	metrics.RecordConfigMapUpdate("nginx", metrics.ResultSuccess)
+	metrics.RecordNginxOperation("config", metrics.ResultSuccess)
+	l.logger.Info("server config updated successfully",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("backend", backend.Name()),
+		zap.String("nginxConfigMap", jwks.Spec.NginxConfigMapName),
+	)
+
+	l.statusUpdater.UpdateNginxConfigUpdated(jwks)
+	return nil
+}
+
+// phase5EnsureNginxDeployment ensures the workload of the backend serving the JWKS
+func (l *ReconciliationLoop) phase5EnsureNginxDeployment(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	if !l.serverDeployed(jwks) {
+		return nil // Server not deployed by the operator
+	}
+
+	awaiting, err := l.awaitingFirstConfig(ctx, jwks)
+	if err != nil {
+		return err
+	}
+	if awaiting {
+		l.logger.Info("server config is tested with nginx -t, workload is created after the test passed",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("job", nginx.ConfigTestJobName(jwks.Name)),
+		)
+		return nil
+	}
+
+	backend := l.backends.For(jwks)
+	l.logger.Debug("ensuring server workload",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("backend", backend.Name()),
+	)
+
+	if err := backend.EnsureWorkload(ctx, jwks, l.getNginxConfigOptions(jwks)); err != nil {
+		l.logger.Error("failed to ensure server workload",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("backend", backend.Name()),
+			zap.Error(err),
+		)
+		metrics.RecordNginxOperation("deployment", metrics.ResultError)
+		return fmt.Errorf("failed to ensure %s workload: %w", backend.Name(), err)
+	}
+
+	metrics.RecordNginxOperation("deployment", metrics.ResultSuccess)
+	l.logger.Info("server workload ensured successfully",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("backend", backend.Name()),
+	)
+
+	return nil
+}
+
+// phase6EnsureNginxService ensures the Service of the backend serving the JWKS
+func (l *ReconciliationLoop) phase6EnsureNginxService(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	if !l.serverDeployed(jwks) {
+		return nil // Server not deployed by the operator
+	}
+
+	backend := l.backends.For(jwks)
+	l.logger.Debug("ensuring server Service",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("backend", backend.Name()),
+	)
+
+	if err := backend.EnsureService(ctx, jwks); err != nil {
+		l.logger.Error("failed to ensure server service",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("backend", backend.Name()),
+			zap.Error(err),
+		)
+		metrics.RecordNginxOperation("service", metrics.ResultError)
+		return fmt.Errorf("failed to ensure %s service: %w", backend.Name(), err)
+	}
+
+	metrics.RecordNginxOperation("service", metrics.ResultSuccess)
+	l.logger.Info("server Service ensured successfully",
+		zap.String("namespace", jwks.Namespace),
+		zap.String("name", jwks.Name),
+		zap.String("backend", backend.Name()),
+	)
+
+	return nil
+}
@@ -63,6 +63,21 @@ func (u *StatusUpdater) SetCondition(jwks *v1alpha1.JWKS, conditionType string,
 	}
 }
 
+// RemoveCondition removes a condition from JWKS
+func (u *StatusUpdater) RemoveCondition(jwks *v1alpha1.JWKS, conditionType string) {
+	if jwks == nil {
+		return
+	}
+
+	conditions := jwks.Status.Conditions[:0]
+	for _, c := range jwks.Status.Conditions {
+		if c.Type != conditionType {
+			conditions = append(conditions, c)
+		}
+	}
+	jwks.Status.Conditions = conditions
+}
+
 // SetReady sets the Ready condition to true
 func (u *StatusUpdater) SetReady(jwks *v1alpha1.JWKS, message string) {
 	u.SetCondition(jwks, "Ready", metav1.ConditionTrue, "Reconciled", message)
@@ -0,0 +1,46 @@
+package reconciler
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// templateChanged checks if the spec.server.template ConfigMap changed since the nginx config was rendered
+// A deleted template ConfigMap is reported by the full reconciliation as well
+func (l *ReconciliationLoop) templateChanged(ctx context.Context, jwks *v1alpha1.JWKS) bool {
+	server := jwks.Spec.Server
+	if server == nil || server.Template == nil || nginx.BuiltinServer(server) || !l.serverDeployed(jwks) {
+		return false // The built-in server ignores templates
+	}
+
+	nginxConfigMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: jwks.Namespace, Name: jwks.Spec.NginxConfigMapName}
+	if err := l.client.Get(ctx, key, nginxConfigMap); err != nil {
+		return false // A missing nginx ConfigMap is handled by shouldReconcile
+	}
+
+	templateConfigMap := &corev1.ConfigMap{}
+	key = types.NamespacedName{Namespace: jwks.Namespace, Name: server.Template.ConfigMapName}
+	if err := l.client.Get(ctx, key, templateConfigMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true
+		}
+		l.logger.Debug("failed to check nginx template ConfigMap, will retry",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("templateConfigMap", server.Template.ConfigMapName),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	return nginxConfigMap.Annotations[config.AnnotationTemplateHash] != nginx.TemplateHash(templateConfigMap)
+}
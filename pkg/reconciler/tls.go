@@ -0,0 +1,69 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+	"github.com/jwks-operator/jwks-operator/pkg/verification"
+)
+
+// serverVerifier returns the verifier and Service port verification uses for the server
+// Servers with spec.server.tls are verified over https, ca.crt of the TLS Secret is trusted
+// Requests are paced below spec.server.rateLimit, so verification is not rejected by the server
+func (l *ReconciliationLoop) serverVerifier(ctx context.Context, jwks *v1alpha1.JWKS) (*verification.Verifier, int32, error) {
+	verifier, port := l.verifier, nginx.ServicePort(jwks.Spec.Service)
+
+	if tls := nginx.ServerTLS(jwks.Spec.Server); tls != nil {
+		secret := &corev1.Secret{}
+		if err := l.client.Get(ctx, types.NamespacedName{Namespace: jwks.Namespace, Name: tls.SecretName}, secret); err != nil {
+			return nil, 0, fmt.Errorf("failed to get TLS Secret %s: %w", tls.SecretName, err)
+		}
+
+		var err error
+		if verifier, err = verifier.WithTLS(secret.Data[config.SecretKeyCACert]); err != nil {
+			return nil, 0, err
+		}
+		port = nginx.TLSServicePort(tls)
+	}
+
+	if limits := nginx.ResolveRequestLimits(nginx.RateLimit(jwks.Spec.Server), 0); limits.Enabled() {
+		verifier = verifier.WithRateLimit(limits.RequestsPerSecond, limits.Status)
+	}
+	return verifier, port, nil
+}
+
+// tlsSecretChanged checks if the TLS Secret no longer matches the hash on the server pod template
+// so that a renewed certificate rolls the pods without waiting for the update interval
+func (l *ReconciliationLoop) tlsSecretChanged(ctx context.Context, jwks *v1alpha1.JWKS) bool {
+	tls := nginx.ServerTLS(jwks.Spec.Server)
+	if tls == nil || !l.serverDeployed(jwks) {
+		return false
+	}
+
+	secret := &corev1.Secret{}
+	if err := l.client.Get(ctx, types.NamespacedName{Namespace: jwks.Namespace, Name: tls.SecretName}, secret); err != nil {
+		l.logger.Debug("failed to check TLS Secret, will retry",
+			zap.String("namespace", jwks.Namespace),
+			zap.String("name", jwks.Name),
+			zap.String("secret", tls.SecretName),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Namespace: jwks.Namespace, Name: nginx.GetDeploymentName(jwks.Name)}
+	if err := l.client.Get(ctx, key, deployment); err != nil {
+		return false // A missing Deployment is handled by shouldReconcile
+	}
+
+	return deployment.Spec.Template.Annotations[config.AnnotationTLSSecretHash] != nginx.TLSSecretHash(secret)
+}
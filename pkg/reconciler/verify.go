@@ -0,0 +1,42 @@
+package reconciler
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+// verifyServer runs all checks against the JWKS server Service once
+func (l *ReconciliationLoop) verifyServer(ctx context.Context, jwks *v1alpha1.JWKS, secret *corev1.Secret) error {
+	verifier, servicePort, err := l.serverVerifier(ctx, jwks)
+	if err != nil {
+		return err
+	}
+	configOptions := l.getNginxConfigOptions(jwks)
+
+	if err := verifier.VerifyJWKSFromNginx(ctx, jwks.Namespace, jwks.Name, servicePort,
+		configOptions.JWKSPath(), secret); err != nil {
+		return err
+	}
+
+	if err := verifier.VerifyCORSFromNginx(ctx, jwks.Namespace, jwks.Name, servicePort,
+		configOptions.JWKSPath(), getCORSChecks(jwks)); err != nil {
+		return err
+	}
+
+	if err := verifier.VerifyCachingFromNginx(ctx, jwks.Namespace, jwks.Name, servicePort,
+		configOptions.JWKSPath(), l.nginxManager.CacheControl(jwks.Spec.Cache)); err != nil {
+		return err
+	}
+
+	if jwks.Spec.Issuer != "" {
+		if err := verifier.VerifyDiscoveryFromNginx(ctx, jwks.Namespace, jwks.Name, servicePort,
+			configOptions.JWKSPath(), configOptions.DiscoveryPath(), jwks.Spec.Issuer, l.getJWKSURI(jwks)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// Backend names
+const (
+	// BackendNginx runs the nginx or built-in server Deployment and Service
+	BackendNginx = "nginx"
+	// BackendExternal deploys nothing, the JWKS ConfigMap is served outside the operator
+	BackendExternal = "external"
+)
+
+// Options are the serving settings rendered into the server config
+type Options = nginx.ConfigOptions
+
+// Backend serves the JWKS ConfigMap of a JWKS resource over HTTP
+// The reconciliation phases only call the backend, so serving is not tied to nginx
+type Backend interface {
+	// Name returns the backend name used in logs
+	Name() string
+	// Managed reports whether the backend deploys a server for the JWKS in the cluster
+	// Availability, network policy, exposure, propagation and verification only apply to managed backends
+	Managed() bool
+	// RenderConfig creates or updates the server configuration of the JWKS
+	RenderConfig(ctx context.Context, jwks *v1alpha1.JWKS, opts Options) error
+	// EnsureWorkload creates or updates the pods serving the JWKS
+	EnsureWorkload(ctx context.Context, jwks *v1alpha1.JWKS, opts Options) error
+	// EnsureService creates or updates the Service in front of the workload
+	EnsureService(ctx context.Context, jwks *v1alpha1.JWKS) error
+	// Delete removes the objects the backend created for the JWKS, objects not created by the operator are kept
+	Delete(ctx context.Context, namespace, name string) error
+	// EndpointURL returns the in-cluster URL of the primary JWKS path
+	// Backends that are not managed return an empty URL
+	EndpointURL(jwks *v1alpha1.JWKS, opts Options) string
+}
+
+// BackendName returns the name of the backend serving the JWKS
+// Resources without spec.nginxConfigMapName have no server config and are served externally
+func BackendName(jwks *v1alpha1.JWKS) string {
+	if jwks.Spec.NginxConfigMapName == "" ||
+		(jwks.Spec.Server != nil && jwks.Spec.Server.Type == config.ServerTypeExternal) {
+		return BackendExternal
+	}
+	return BackendNginx
+}
+
+// Backends selects the backend of each JWKS resource
+type Backends struct {
+	backends []Backend
+}
+
+// NewBackends creates the backend set
+func NewBackends(backends ...Backend) *Backends {
+	return &Backends{backends: backends}
+}
+
+// For returns the backend serving the JWKS
+// Unknown backend names fall back to the external backend, which deploys nothing
+func (b *Backends) For(jwks *v1alpha1.JWKS) Backend {
+	name := BackendName(jwks)
+	for _, backend := range b.backends {
+		if backend.Name() == name {
+			return backend
+		}
+	}
+	return ExternalBackend{}
+}
+
+// Inactive returns the backends not serving the JWKS, whose objects must be removed
+func (b *Backends) Inactive(jwks *v1alpha1.JWKS) []Backend {
+	name := BackendName(jwks)
+	inactive := make([]Backend, 0, len(b.backends))
+	for _, backend := range b.backends {
+		if backend.Name() != name {
+			inactive = append(inactive, backend)
+		}
+	}
+	return inactive
+}
+
+// All returns every backend, used to clean up deleted JWKS resources
+func (b *Backends) All() []Backend {
+	return b.backends
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// newBackendJWKS returns a JWKS resource with the given nginx ConfigMap and server type
+func newBackendJWKS(nginxConfigMap, serverType string) *v1alpha1.JWKS {
+	jwks := &v1alpha1.JWKS{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+		Spec: v1alpha1.JWKSSpec{
+			ConfigMapName:      "auth-jwks",
+			NginxConfigMapName: nginxConfigMap,
+		},
+	}
+	if serverType != "" {
+		jwks.Spec.Server = &v1alpha1.ServerSpec{Type: serverType}
+	}
+	return jwks
+}
+
+func TestBackends(t *testing.T) {
+	backends := NewBackends(NewNginxBackend(nil, nil), ExternalBackend{})
+
+	tests := []struct {
+		name         string
+		jwks         *v1alpha1.JWKS
+		wantBackend  string
+		wantInactive string
+	}{
+		{
+			name:         "nginx server",
+			jwks:         newBackendJWKS("auth-nginx", ""),
+			wantBackend:  BackendNginx,
+			wantInactive: BackendExternal,
+		},
+		{
+			name:         "built-in server",
+			jwks:         newBackendJWKS("auth-nginx", config.ServerTypeBuiltin),
+			wantBackend:  BackendNginx,
+			wantInactive: BackendExternal,
+		},
+		{
+			name:         "external server",
+			jwks:         newBackendJWKS("auth-nginx", config.ServerTypeExternal),
+			wantBackend:  BackendExternal,
+			wantInactive: BackendNginx,
+		},
+		{
+			name:         "without server config",
+			jwks:         newBackendJWKS("", ""),
+			wantBackend:  BackendExternal,
+			wantInactive: BackendNginx,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BackendName(tt.jwks); got != tt.wantBackend {
+				t.Errorf("BackendName() = %q, want %q", got, tt.wantBackend)
+			}
+			backend := backends.For(tt.jwks)
+			if got := backend.Name(); got != tt.wantBackend {
+				t.Errorf("For() = %q, want %q", got, tt.wantBackend)
+			}
+			if got := backend.Managed(); got != (tt.wantBackend == BackendNginx) {
+				t.Errorf("Managed() = %v, want %v", got, tt.wantBackend == BackendNginx)
+			}
+			inactive := backends.Inactive(tt.jwks)
+			if len(inactive) != 1 || inactive[0].Name() != tt.wantInactive {
+				t.Errorf("Inactive() = %v, want [%s]", inactive, tt.wantInactive)
+			}
+		})
+	}
+}
+
+func TestBackendsForUnknownBackend(t *testing.T) {
+	backends := NewBackends(ExternalBackend{})
+
+	if got := backends.For(newBackendJWKS("auth-nginx", "")).Name(); got != BackendExternal {
+		t.Errorf("For() = %q, want the external fallback", got)
+	}
+}
+
+func TestBackendEndpointURL(t *testing.T) {
+	opts := Options{Endpoint: "/.well-known/jwks.json"}
+
+	tests := []struct {
+		name    string
+		backend Backend
+		service *v1alpha1.ServiceSpec
+		want    string
+	}{
+		{
+			name:    "nginx on the default port",
+			backend: NewNginxBackend(nil, nil),
+			want:    "http://auth.default.svc.cluster.local/.well-known/jwks.json",
+		},
+		{
+			name:    "nginx on a custom port",
+			backend: NewNginxBackend(nil, nil),
+			service: &v1alpha1.ServiceSpec{Port: 8080},
+			want:    "http://auth.default.svc.cluster.local:8080/.well-known/jwks.json",
+		},
+		{
+			name:    "external",
+			backend: ExternalBackend{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwks := newBackendJWKS("auth-nginx", "")
+			jwks.Spec.Service = tt.service
+
+			if got := tt.backend.EndpointURL(jwks, opts); got != tt.want {
+				t.Errorf("EndpointURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+// ExternalBackend deploys no server, the operator only publishes the JWKS ConfigMaps
+// The JWKS is served by something outside the operator, e.g. an existing gateway mounting the ConfigMap
+type ExternalBackend struct{}
+
+// Name returns the backend name
+func (ExternalBackend) Name() string {
+	return BackendExternal
+}
+
+// Managed returns false, the JWKS is not served in the cluster by the operator
+func (ExternalBackend) Managed() bool {
+	return false
+}
+
+// RenderConfig does nothing, there is no server config
+func (ExternalBackend) RenderConfig(context.Context, *v1alpha1.JWKS, Options) error {
+	return nil
+}
+
+// EnsureWorkload does nothing, there are no server pods
+func (ExternalBackend) EnsureWorkload(context.Context, *v1alpha1.JWKS, Options) error {
+	return nil
+}
+
+// EnsureService does nothing, there is no Service
+func (ExternalBackend) EnsureService(context.Context, *v1alpha1.JWKS) error {
+	return nil
+}
+
+// Delete does nothing, the backend creates no objects
+func (ExternalBackend) Delete(context.Context, string, string) error {
+	return nil
+}
+
+// EndpointURL returns an empty URL, the JWKS is not served in the cluster by the operator
+func (ExternalBackend) EndpointURL(*v1alpha1.JWKS, Options) string {
+	return ""
+}
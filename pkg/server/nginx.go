@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/nginx"
+)
+
+// NginxBackend serves the JWKS from a Deployment running nginx or the built-in server (spec.server.type)
+// The server config is written to spec.nginxConfigMapName and mounted next to the JWKS ConfigMap
+type NginxBackend struct {
+	manager   *nginx.Manager
+	resources *config.NginxResources
+}
+
+// NewNginxBackend creates the nginx backend, resources are the default container resources
+func NewNginxBackend(manager *nginx.Manager, resources *config.NginxResources) *NginxBackend {
+	return &NginxBackend{
+		manager:   manager,
+		resources: resources,
+	}
+}
+
+// Name returns the backend name
+func (b *NginxBackend) Name() string {
+	return BackendNginx
+}
+
+// Managed returns true, the backend deploys the nginx or built-in server
+func (b *NginxBackend) Managed() bool {
+	return true
+}
+
+// RenderConfig creates or updates the server ConfigMap
+// The nginx config is rendered from spec.server.template if set, a template failing to render is rejected
+// The built-in server without a configured image is rejected before its config is written
+func (b *NginxBackend) RenderConfig(ctx context.Context, jwks *v1alpha1.JWKS, opts Options) error {
+	if err := b.manager.ValidateImage(jwks.Spec.Server); err != nil {
+		return err
+	}
+	if !opts.Builtin && jwks.Spec.Server != nil {
+		configTemplate, err := b.manager.LoadTemplate(ctx, jwks.Namespace, jwks.Spec.Server.Template)
+		if err != nil {
+			return err
+		}
+		opts.Template = configTemplate
+	}
+	return b.manager.UpdateConfig(ctx, jwks.Namespace, jwks.Name, jwks.Spec.NginxConfigMapName, jwks.Spec.ConfigMapName,
+		opts, jwks.Spec.Server)
+}
+
+// EnsureWorkload creates or updates the server Deployment
+func (b *NginxBackend) EnsureWorkload(ctx context.Context, jwks *v1alpha1.JWKS, opts Options) error {
+	return b.manager.EnsureDeployment(
+		ctx,
+		jwks.Namespace,
+		jwks.Name,
+		jwks.Spec.NginxConfigMapName,
+		jwks.Spec.ConfigMapName,
+		opts.JWKSPath(),
+		b.resources,
+		jwks.Spec.Server,
+	)
+}
+
+// EnsureService creates or updates the server Service
+func (b *NginxBackend) EnsureService(ctx context.Context, jwks *v1alpha1.JWKS) error {
+	return b.manager.EnsureService(ctx, jwks.Namespace, jwks.Name, jwks.Spec.Service, nginx.ServerTLS(jwks.Spec.Server))
+}
+
+// Delete removes the Service, Deployment, PodDisruptionBudget, HorizontalPodAutoscaler, NetworkPolicy
+// and the nginx config test Job
+// The server ConfigMap is kept, it is handled like the JWKS ConfigMap on deletion (spec.cleanupOnDelete)
+func (b *NginxBackend) Delete(ctx context.Context, namespace, name string) error {
+	if err := b.manager.DeleteService(ctx, namespace, name); err != nil {
+		return fmt.Errorf("failed to delete Service: %w", err)
+	}
+	if err := b.manager.DeleteDeployment(ctx, namespace, name); err != nil {
+		return fmt.Errorf("failed to delete Deployment: %w", err)
+	}
+	if err := b.manager.EnsurePodDisruptionBudget(ctx, namespace, name, nil); err != nil {
+		return fmt.Errorf("failed to delete PodDisruptionBudget: %w", err)
+	}
+	if err := b.manager.EnsureHorizontalPodAutoscaler(ctx, namespace, name, nil); err != nil {
+		return fmt.Errorf("failed to delete HorizontalPodAutoscaler: %w", err)
+	}
+	if err := b.manager.EnsureNetworkPolicy(ctx, namespace, name, "", nil, nil); err != nil {
+		return fmt.Errorf("failed to delete NetworkPolicy: %w", err)
+	}
+	if err := b.manager.DeleteConfigTest(ctx, namespace, name); err != nil {
+		return fmt.Errorf("failed to delete nginx config test: %w", err)
+	}
+	return nil
+}
+
+// EndpointURL returns the URL of the primary JWKS path behind the server Service
+// The https port is used if spec.server.tls is set
+func (b *NginxBackend) EndpointURL(jwks *v1alpha1.JWKS, opts Options) string {
+	scheme, port, defaultPort := "http", nginx.ServicePort(jwks.Spec.Service), int32(80)
+	if tls := nginx.ServerTLS(jwks.Spec.Server); tls != nil {
+		scheme, port, defaultPort = "https", nginx.TLSServicePort(tls), 443
+	}
+	if port == defaultPort {
+		return fmt.Sprintf("%s://%s.%s.svc.cluster.local%s", scheme, jwks.Name, jwks.Namespace, opts.JWKSPath())
+	}
+	return fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d%s", scheme, jwks.Name, jwks.Namespace, port, opts.JWKSPath())
+}
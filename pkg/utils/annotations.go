@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jwks-operator/jwks-operator/pkg/config"
+)
+
+// ApplyManagedAnnotations sets desired annotations on obj and removes annotations
+// that were set from the spec before but are no longer desired
+// The list of managed keys is stored in the AnnotationManagedAnnotations annotation
+// Returns true if obj was changed
+func ApplyManagedAnnotations(obj metav1.Object, desired map[string]string) bool {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	changed := false
+	if previous := annotations[config.AnnotationManagedAnnotations]; previous != "" {
+		for _, key := range strings.Split(previous, ",") {
+			if _, ok := desired[key]; ok {
+				continue
+			}
+			if _, exists := annotations[key]; exists {
+				delete(annotations, key)
+				changed = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key, value := range desired {
+		keys = append(keys, key)
+		if current, ok := annotations[key]; !ok || current != value {
+			annotations[key] = value
+			changed = true
+		}
+	}
+	sort.Strings(keys)
+
+	managed := strings.Join(keys, ",")
+	if managed == "" {
+		if _, ok := annotations[config.AnnotationManagedAnnotations]; ok {
+			delete(annotations, config.AnnotationManagedAnnotations)
+			changed = true
+		}
+	} else if annotations[config.AnnotationManagedAnnotations] != managed {
+		annotations[config.AnnotationManagedAnnotations] = managed
+		changed = true
+	}
+
+	obj.SetAnnotations(annotations)
+	return changed
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SetControllerReference sets owner as the controller of obj
+// Objects already controlled by another owner are left untouched
+// Returns true if obj was changed
+func SetControllerReference(owner, obj metav1.Object, scheme *runtime.Scheme) (bool, error) {
+	if metav1.IsControlledBy(obj, owner) {
+		return false, nil
+	}
+
+	err := controllerutil.SetControllerReference(owner, obj, scheme)
+	if err != nil {
+		var alreadyOwned *controllerutil.AlreadyOwnedError
+		if errors.As(err, &alreadyOwned) {
+			return false, nil // Controlled by someone else, not ours to take over
+		}
+		return false, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoveOwnerReference removes all owner references pointing to owner from obj
+// Returns true if obj was changed
+func RemoveOwnerReference(owner, obj metav1.Object) bool {
+	refs := obj.GetOwnerReferences()
+	kept := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.UID != owner.GetUID() {
+			kept = append(kept, ref)
+		}
+	}
+
+	if len(kept) == len(refs) {
+		return false
+	}
+
+	obj.SetOwnerReferences(kept)
+	return true
+}
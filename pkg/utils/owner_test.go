@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jwks-operator/jwks-operator/api/v1alpha1"
+)
+
+// newTestOwner returns a JWKS resource used as owner in tests
+func newTestOwner(name string, uid types.UID) *v1alpha1.JWKS {
+	return &v1alpha1.JWKS{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: uid}}
+}
+
+// controllerRef returns a controller owner reference to the JWKS resource
+func controllerRef(owner *v1alpha1.JWKS) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "JWKS",
+		Name:       owner.Name,
+		UID:        owner.UID,
+		Controller: &controller,
+	}
+}
+
+func TestSetControllerReference(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	owner := newTestOwner("auth", "uid-auth")
+	other := newTestOwner("other", "uid-other")
+
+	tests := []struct {
+		name        string
+		refs        []metav1.OwnerReference
+		wantChanged bool
+		wantRefs    int
+		wantUID     types.UID
+	}{
+		{
+			name:        "without owner",
+			wantChanged: true,
+			wantRefs:    1,
+			wantUID:     owner.UID,
+		},
+		{
+			name:     "already controlled by owner",
+			refs:     []metav1.OwnerReference{controllerRef(owner)},
+			wantRefs: 1,
+			wantUID:  owner.UID,
+		},
+		{
+			name:     "controlled by another owner",
+			refs:     []metav1.OwnerReference{controllerRef(other)},
+			wantRefs: 1,
+			wantUID:  other.UID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", OwnerReferences: tt.refs}}
+
+			changed, err := SetControllerReference(owner, obj, scheme)
+			if err != nil {
+				t.Fatalf("SetControllerReference() unexpected error: %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("SetControllerReference() = %v, want %v", changed, tt.wantChanged)
+			}
+			if len(obj.OwnerReferences) != tt.wantRefs {
+				t.Fatalf("got %d owner references, want %d", len(obj.OwnerReferences), tt.wantRefs)
+			}
+			if controller := metav1.GetControllerOf(obj); controller == nil || controller.UID != tt.wantUID {
+				t.Errorf("controller = %v, want UID %s", controller, tt.wantUID)
+			}
+		})
+	}
+}
+
+func TestRemoveOwnerReference(t *testing.T) {
+	owner := newTestOwner("auth", "uid-auth")
+	other := newTestOwner("other", "uid-other")
+
+	tests := []struct {
+		name        string
+		refs        []metav1.OwnerReference
+		wantChanged bool
+		wantRefs    int
+	}{
+		{
+			name: "without owner references",
+		},
+		{
+			name:     "other owner is kept",
+			refs:     []metav1.OwnerReference{controllerRef(other)},
+			wantRefs: 1,
+		},
+		{
+			name:        "owner is removed",
+			refs:        []metav1.OwnerReference{controllerRef(owner), controllerRef(other)},
+			wantChanged: true,
+			wantRefs:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", OwnerReferences: tt.refs}}
+
+			if changed := RemoveOwnerReference(owner, obj); changed != tt.wantChanged {
+				t.Errorf("RemoveOwnerReference() = %v, want %v", changed, tt.wantChanged)
+			}
+			if len(obj.OwnerReferences) != tt.wantRefs {
+				t.Errorf("got %d owner references, want %d", len(obj.OwnerReferences), tt.wantRefs)
+			}
+			for _, ref := range obj.OwnerReferences {
+				if ref.UID == owner.UID {
+					t.Errorf("owner reference to %s was not removed", owner.Name)
+				}
+			}
+		})
+	}
+}
@@ -25,6 +25,17 @@ func (v *Validator) ValidateNginxConfig(nginxConfig *config.NginxConfig) error {
 		return fmt.Errorf("nginx port must be between 1 and 65535, got %d", nginxConfig.Port)
 	}
 
+	// Validate status port
+	if nginxConfig.StatusPort < 0 || nginxConfig.StatusPort > 65535 {
+		return fmt.Errorf("nginx status port must be between 1 and 65535, got %d", nginxConfig.StatusPort)
+	}
+	if nginxConfig.StatusPort > 0 && nginxConfig.StatusPort == nginxConfig.Port {
+		return fmt.Errorf("nginx status port must differ from nginx port %d", nginxConfig.Port)
+	}
+	if nginxConfig.StatusPort == config.ExporterPort || nginxConfig.Port == config.ExporterPort {
+		return fmt.Errorf("nginx ports must differ from the metrics exporter port %d", config.ExporterPort)
+	}
+
 	// Validate replicas
 	if nginxConfig.Replicas < 0 {
 		return fmt.Errorf("nginx replicas must be non-negative, got %d", nginxConfig.Replicas)
@@ -35,6 +46,32 @@ func (v *Validator) ValidateNginxConfig(nginxConfig *config.NginxConfig) error {
 		return fmt.Errorf("nginx cache max age must be non-negative, got %d", nginxConfig.CacheMaxAge)
 	}
 
+	// Validate security profile
+	switch nginxConfig.SecurityProfile {
+	case "", config.SecurityProfileRestricted, config.SecurityProfileNone:
+	default:
+		return fmt.Errorf("nginx security profile must be %q or %q, got %q",
+			config.SecurityProfileRestricted, config.SecurityProfileNone, nginxConfig.SecurityProfile)
+	}
+
+	// Validate privileged ports, the restricted profile runs nginx as a non-root user
+	if nginxConfig.SecurityProfile != config.SecurityProfileNone {
+		ports := []struct {
+			name string
+			port int
+		}{
+			{"nginx port", nginxConfig.Port},
+			{"nginx status port", nginxConfig.StatusPort},
+			{"nginx TLS port", nginxConfig.TLSPort},
+		}
+		for _, p := range ports {
+			if p.port > 0 && p.port < config.MinUnprivilegedPort {
+				return fmt.Errorf("%s must be at least %d with the %q security profile, got %d",
+					p.name, config.MinUnprivilegedPort, config.SecurityProfileRestricted, p.port)
+			}
+		}
+	}
+
 	// Validate resources if provided
 	if err := v.ValidateNginxResources(&nginxConfig.Resources); err != nil {
 		return fmt.Errorf("invalid nginx resources: %w", err)
@@ -0,0 +1,90 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// minGzipLength matches gzip_min_length of the generated nginx config
+const minGzipLength = 256
+
+// VerifyCachingFromNginx checks the ETag, conditional GET, Cache-Control and compression of the JWKS response
+// The ETag must be the digest of the served content, so it is the same on every replica
+func (v *Verifier) VerifyCachingFromNginx(
+	ctx context.Context,
+	namespace string,
+	serviceName string,
+	port int32,
+	jwksPath string,
+	cacheControl string,
+) error {
+	url := v.serviceURL(namespace, serviceName, port) + jwksPath
+
+	resp, body, err := v.get(ctx, url, map[string]string{"Accept-Encoding": "identity"})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	etag := resp.Header.Get("ETag")
+	if expected := `"` + jwks.Digest(body) + `"`; etag != expected {
+		return fmt.Errorf("unexpected ETag %q, expected %q", etag, expected)
+	}
+
+	if got := resp.Header.Get("Cache-Control"); got != cacheControl {
+		return fmt.Errorf("unexpected Cache-Control %q, expected %q", got, cacheControl)
+	}
+
+	resp, _, err = v.get(ctx, url, map[string]string{"If-None-Match": etag})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		return fmt.Errorf("unexpected status code for If-None-Match: %d, expected %d", resp.StatusCode, http.StatusNotModified)
+	}
+
+	if len(body) < minGzipLength {
+		return nil
+	}
+
+	// Setting Accept-Encoding explicitly disables transparent decompression in net/http
+	resp, _, err = v.get(ctx, url, map[string]string{"Accept-Encoding": "gzip"})
+	if err != nil {
+		return err
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		return fmt.Errorf("unexpected Content-Encoding %q, expected gzip", got)
+	}
+
+	return nil
+}
+
+// get sends a GET request with the given headers and returns the response with its body
+func (v *Verifier) get(ctx context.Context, url string, headers map[string]string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return resp, body, nil
+}
@@ -0,0 +1,101 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CORSCheck describes a request with an Origin header and the CORS headers expected in the response
+type CORSCheck struct {
+	// Preflight sends an OPTIONS request instead of GET
+	Preflight bool
+	// Origin is sent in the Origin header
+	Origin string
+	// AllowOrigin is the expected Access-Control-Allow-Origin, empty means the header must be absent
+	AllowOrigin string
+	// AllowMethods is the expected Access-Control-Allow-Methods of preflight responses
+	AllowMethods string
+	// MaxAge is the expected Access-Control-Max-Age of preflight responses, empty means the header must be absent
+	MaxAge string
+	// AllowCredentials expects Access-Control-Allow-Credentials: true
+	AllowCredentials bool
+}
+
+// VerifyCORSFromNginx sends requests with an Origin header to the JWKS path and checks the CORS headers
+func (v *Verifier) VerifyCORSFromNginx(
+	ctx context.Context,
+	namespace string,
+	serviceName string,
+	port int32,
+	jwksPath string,
+	checks []CORSCheck,
+) error {
+	url := v.serviceURL(namespace, serviceName, port) + jwksPath
+
+	for _, check := range checks {
+		if err := v.verifyCORS(ctx, url, check); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyCORS performs a single CORS check
+func (v *Verifier) verifyCORS(ctx context.Context, url string, check CORSCheck) error {
+	method := http.MethodGet
+	expectedStatus := http.StatusOK
+	if check.Preflight {
+		method = http.MethodOptions
+		expectedStatus = http.StatusNoContent
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Origin", check.Origin)
+	if check.Preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	// Preflight status is only meaningful if CORS is enabled, nginx answers 405 otherwise
+	if resp.StatusCode != expectedStatus && (!check.Preflight || check.AllowOrigin != "") {
+		return fmt.Errorf("unexpected status code for %s with origin %s: %d", method, check.Origin, resp.StatusCode)
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != check.AllowOrigin {
+		return fmt.Errorf("unexpected Access-Control-Allow-Origin for %s with origin %s: %q, expected %q",
+			method, check.Origin, got, check.AllowOrigin)
+	}
+
+	if check.AllowOrigin == "" {
+		return nil
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Credentials"); (got == "true") != check.AllowCredentials {
+		return fmt.Errorf("unexpected Access-Control-Allow-Credentials for origin %s: %q", check.Origin, got)
+	}
+
+	if !check.Preflight {
+		return nil
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Methods"); got != check.AllowMethods {
+		return fmt.Errorf("unexpected Access-Control-Allow-Methods: %q, expected %q", got, check.AllowMethods)
+	}
+	if got := resp.Header.Get("Access-Control-Max-Age"); got != check.MaxAge {
+		return fmt.Errorf("unexpected Access-Control-Max-Age: %q, expected %q", got, check.MaxAge)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
+)
+
+// VerifyContentFromPod checks that a single server pod serves JWKS content with the given digest
+// Pods are queried directly, because the Service balances requests between old and new content
+func (v *Verifier) VerifyContentFromPod(
+	ctx context.Context,
+	podIP string,
+	port int32,
+	jwksPath string,
+	digest string,
+) error {
+	url := "http://" + net.JoinHostPort(podIP, strconv.Itoa(int(port))) + jwksPath
+
+	resp, body, err := v.get(ctx, url, map[string]string{"Accept-Encoding": "identity"})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if served := jwks.Digest(body); served != digest {
+		return fmt.Errorf("pod %s serves JWKS with digest %s, expected %s", podIP, served, digest)
+	}
+
+	return nil
+}
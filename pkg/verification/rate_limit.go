@@ -0,0 +1,90 @@
+package verification
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// pacedTransport spaces requests by a fixed interval
+// A request rejected with the rate limit status is sent once more in the next slot, the server may have
+// counted requests of a verification that ran shortly before
+type pacedTransport struct {
+	next     http.RoundTripper
+	interval time.Duration
+	status   int
+
+	mu     sync.Mutex
+	nextAt time.Time
+}
+
+// RoundTrip waits for the next free slot and sends the request
+func (t *pacedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != t.status || req.Body != nil {
+		return resp, err
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if err := t.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// wait blocks until the next free slot
+func (t *pacedTransport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	wait := t.nextAt.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	t.nextAt = now.Add(wait + t.interval)
+	t.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithRateLimit returns a verifier sending at most requestsPerSecond requests
+// Verification sends several requests in a row, which spec.server.rateLimit would otherwise reject with status
+// Requests are spaced 10% wider than the rate, so they are accepted without burst
+func (v *Verifier) WithRateLimit(requestsPerSecond, status int) *Verifier {
+	if requestsPerSecond <= 0 {
+		return v
+	}
+
+	transport := v.httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Verifier{
+		httpClient: &http.Client{
+			Timeout: v.httpClient.Timeout,
+			Transport: &pacedTransport{
+				next:     transport,
+				interval: 11 * time.Second / (10 * time.Duration(requestsPerSecond)),
+				status:   status,
+			},
+		},
+		config: v.config,
+		https:  v.https,
+	}
+}
@@ -0,0 +1,51 @@
+package verification
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// WithTLS returns a verifier checking the server over https
+// The server certificate must be issued by a system root, a CA from verification.caBundleFile or caBundle
+// caBundle are PEM CA certificates, usually ca.crt of the TLS Secret
+func (v *Verifier) WithTLS(caBundle []byte) (*Verifier, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+
+	if v.config != nil && v.config.CABundleFile != "" {
+		data, err := os.ReadFile(v.config.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %w", v.config.CABundleFile, err)
+		}
+		if !roots.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", v.config.CABundleFile)
+		}
+	}
+	if len(caBundle) > 0 && !roots.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("CA certificate of the TLS Secret contains no PEM certificates")
+	}
+
+	// The transport lives for one verification, connections are not kept
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    roots,
+		},
+		DisableKeepAlives: true,
+	}
+
+	return &Verifier{
+		httpClient: &http.Client{
+			Timeout:   v.httpClient.Timeout,
+			Transport: transport,
+		},
+		config: v.config,
+		https:  true,
+	}, nil
+}
@@ -17,12 +17,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/jwks-operator/jwks-operator/pkg/config"
+	"github.com/jwks-operator/jwks-operator/pkg/jwks"
 )
 
 // Verifier verifies JWKS served by nginx
 type Verifier struct {
 	httpClient *http.Client
 	config     *config.VerificationConfig
+	// https is set for servers with spec.server.tls, see WithTLS
+	https bool
 }
 
 // NewVerifier creates a new JWKS verifier
@@ -45,6 +48,8 @@ func (v *Verifier) VerifyJWKSFromNginx(
 	ctx context.Context,
 	namespace string,
 	serviceName string,
+	port int32,
+	jwksPath string,
 	secret *corev1.Secret,
 ) error {
 	if secret == nil {
@@ -52,7 +57,7 @@ func (v *Verifier) VerifyJWKSFromNginx(
 	}
 
 	// Step 1: Get JWKS from nginx Service
-	jwksURL := fmt.Sprintf("http://%s.%s.svc.cluster.local/jwks.json", serviceName, namespace)
+	jwksURL := v.serviceURL(namespace, serviceName, port) + jwksPath
 	jwksData, err := v.fetchJWKS(ctx, jwksURL)
 	if err != nil {
 		return fmt.Errorf("failed to fetch JWKS from nginx: %w", err)
@@ -84,6 +89,59 @@ func (v *Verifier) VerifyJWKSFromNginx(
 	return nil
 }
 
+// VerifyDiscoveryFromNginx verifies that the OpenID Connect discovery document served by nginx
+// matches the expected issuer and jwks_uri and lists algorithms of the served keys
+func (v *Verifier) VerifyDiscoveryFromNginx(
+	ctx context.Context,
+	namespace string,
+	serviceName string,
+	port int32,
+	jwksPath string,
+	discoveryPath string,
+	issuer string,
+	jwksURI string,
+) error {
+	baseURL := v.serviceURL(namespace, serviceName, port)
+
+	discoveryData, err := v.fetchJWKS(ctx, baseURL+discoveryPath)
+	if err != nil {
+		return fmt.Errorf("failed to fetch discovery document from nginx: %w", err)
+	}
+
+	var doc jwks.DiscoveryDocument
+	if err := json.Unmarshal(discoveryData, &doc); err != nil {
+		return fmt.Errorf("failed to unmarshal discovery document: %w", err)
+	}
+
+	jwksData, err := v.fetchJWKS(ctx, baseURL+jwksPath)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS from nginx: %w", err)
+	}
+
+	var keySet jwks.JWKS
+	if err := json.Unmarshal(jwksData, &keySet); err != nil {
+		return fmt.Errorf("failed to unmarshal JWKS: %w", err)
+	}
+
+	if err := jwks.ValidateDiscovery(&doc, issuer, jwksURI, &keySet); err != nil {
+		return fmt.Errorf("discovery document is inconsistent: %w", err)
+	}
+
+	return nil
+}
+
+// serviceURL returns the in-cluster base URL of the nginx Service
+func (v *Verifier) serviceURL(namespace, serviceName string, port int32) string {
+	scheme, defaultPort := "http", int32(80)
+	if v.https {
+		scheme, defaultPort = "https", 443
+	}
+	if port == 0 || port == defaultPort {
+		return fmt.Sprintf("%s://%s.%s.svc.cluster.local", scheme, serviceName, namespace)
+	}
+	return fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", scheme, serviceName, namespace, port)
+}
+
 // fetchJWKS fetches JWKS from nginx Service
 func (v *Verifier) fetchJWKS(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)